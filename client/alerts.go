@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AlertRule is a user-defined condition that the registry periodically evaluates to raise alerts.
+type AlertRule struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Type        string  `json:"type"`
+	Severity    string  `json:"severity,omitempty"`
+	Threshold   float64 `json:"threshold"`
+
+	WindowMinutes         int `json:"window_minutes,omitempty"`
+	BaselineWindowMinutes int `json:"baseline_window_minutes,omitempty"`
+	MinSamples            int `json:"min_samples,omitempty"`
+
+	ResourceType string  `json:"resource_type,omitempty"`
+	ResourceID   *string `json:"resource_id,omitempty"`
+
+	Enabled bool `json:"enabled"`
+}
+
+// CreateAlertRule creates a new alert rule in the registry.
+func (c *Client) CreateAlertRule(rule *AlertRule) (*AlertRule, error) {
+	u, _ := c.constructAPIEndpoint("/alert-rules")
+	body, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize alert rule into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var created AlertRule
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &created, nil
+}
+
+// ListAlertRules fetches all alert rules.
+func (c *Client) ListAlertRules() ([]*AlertRule, error) {
+	u, _ := c.constructAPIEndpoint("/alert-rules")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var rules []*AlertRule
+	if err := json.NewDecoder(resp.Body).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule deletes an alert rule by name.
+func (c *Client) DeleteAlertRule(name string) error {
+	u, _ := c.constructAPIEndpoint("/alert-rules/" + name)
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// Alert is raised when an alert rule's condition holds.
+type Alert struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Title        string     `json:"title"`
+	Message      string     `json:"message"`
+	Severity     string     `json:"severity"`
+	Threshold    *float64   `json:"threshold,omitempty"`
+	CurrentValue *float64   `json:"current_value,omitempty"`
+	ResourceType string     `json:"resource_type"`
+	ResourceID   *string    `json:"resource_id,omitempty"`
+	Acknowledged bool       `json:"acknowledged"`
+	Resolved     bool       `json:"resolved"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ListAlerts fetches the open alerts, or all alerts if includeResolved is true.
+func (c *Client) ListAlerts(includeResolved bool) ([]*Alert, error) {
+	u, _ := c.constructAPIEndpoint("/alerts")
+	if includeResolved {
+		u += "?status=all"
+	}
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var alerts []*Alert
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks an alert as acknowledged.
+func (c *Client) AcknowledgeAlert(id string) error {
+	return c.postAlertAction(id, "acknowledge")
+}
+
+// ResolveAlert marks an alert as resolved.
+func (c *Client) ResolveAlert(id string) error {
+	return c.postAlertAction(id, "resolve")
+}
+
+func (c *Client) postAlertAction(id, action string) error {
+	u, _ := c.constructAPIEndpoint("/alerts/" + id + "/" + action)
+	resp, err := c.HTTPClient.Post(u, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Usage is the LLM token usage of a single model call, as reported by an agent.
+type Usage struct {
+	Model        string `json:"model"`
+	ClientType   string `json:"client_type"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	// CacheReadTokens and CacheWriteTokens are the prompt caching token counts, if any.
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+	// Cost in USD. If nil, the registry computes it from its pricing catalog.
+	Cost *float64 `json:"cost,omitempty"`
+	// SessionID is the MCP session (Mcp-Session-Id) in which the agent calls tools through MCPJungle.
+	// Tool calls made in the session are linked to the reported usage.
+	SessionID string  `json:"session_id,omitempty"`
+	UserID    *string `json:"user_id,omitempty"`
+	// Timestamp defaults to the time the registry receives the report.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReportUsage sends one or more usage records to the registry.
+// Multiple records are sent as a single batch, which the registry stores atomically.
+func (c *Client) ReportUsage(usages ...*Usage) error {
+	u, _ := c.constructAPIEndpoint("/analytics/usage")
+
+	var payload any = usages
+	if len(usages) == 1 {
+		payload = usages[0]
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to serialize usage into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ExportUsageReport downloads a usage report for the given window and copies it into w.
+// from and to are either YYYY-MM-DD dates or RFC3339 timestamps.
+// format must be one of csv, json or ndjson.
+func (c *Client) ExportUsageReport(from, to, format string, w io.Writer) error {
+	u, _ := c.constructAPIEndpoint("/analytics/export")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	q := req.URL.Query()
+	q.Add("from", from)
+	q.Add("to", to)
+	q.Add("format", format)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CallLimit caps the concurrent calls and the rate of calls to an upstream MCP server or tool.
+type CallLimit struct {
+	// ScopeType is either server or tool
+	ScopeType string `json:"scope_type"`
+	// ScopeValue is the server name or the canonical tool name
+	ScopeValue    string  `json:"scope_value"`
+	MaxConcurrent int     `json:"max_concurrent,omitempty"`
+	RatePerSecond float64 `json:"rate_per_second,omitempty"`
+	Burst         int     `json:"burst,omitempty"`
+	// Mode is either reject (default) or queue
+	Mode           string `json:"mode,omitempty"`
+	QueueTimeoutMs int    `json:"queue_timeout_ms,omitempty"`
+}
+
+// CallLimitStats counts how the calls within a limit's scope were treated since the registry started.
+type CallLimitStats struct {
+	Allowed             int64 `json:"allowed"`
+	Queued              int64 `json:"queued"`
+	RejectedRate        int64 `json:"rejected_rate"`
+	RejectedConcurrency int64 `json:"rejected_concurrency"`
+	InFlight            int64 `json:"in_flight"`
+}
+
+// CallLimitStatus is a call limit along with its statistics.
+type CallLimitStatus struct {
+	CallLimit
+	Stats CallLimitStats `json:"stats"`
+}
+
+// SetCallLimit creates or replaces the limit of a server or tool.
+func (c *Client) SetCallLimit(limit *CallLimit) error {
+	u, _ := c.constructAPIEndpoint("/limits")
+	body, err := json.Marshal(limit)
+	if err != nil {
+		return fmt.Errorf("failed to serialize limit into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ListCallLimits fetches all call limits along with their statistics.
+func (c *Client) ListCallLimits() ([]*CallLimitStatus, error) {
+	u, _ := c.constructAPIEndpoint("/limits")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var limits []*CallLimitStatus
+	if err := json.NewDecoder(resp.Body).Decode(&limits); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return limits, nil
+}
+
+// DeleteCallLimit removes the limit of a server or tool.
+func (c *Client) DeleteCallLimit(scopeType, scopeValue string) error {
+	u, _ := c.constructAPIEndpoint("/limits/" + scopeType + "/" + scopeValue)
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
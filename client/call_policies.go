@@ -0,0 +1,102 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// CallPolicy configures how the registry calls an upstream MCP server or tool.
+// Settings that are nil are inherited from the server's policy (for tools) or the registry defaults.
+type CallPolicy struct {
+	// ScopeType is either server or tool
+	ScopeType string `json:"scope_type"`
+	// ScopeValue is the server name or the canonical tool name
+	ScopeValue       string `json:"scope_value"`
+	ConnectTimeoutMs *int   `json:"connect_timeout_ms,omitempty"`
+	CallTimeoutMs    *int   `json:"call_timeout_ms,omitempty"`
+
+	// BreakerFailureThreshold and BreakerCooldownMs configure the circuit breaker of a server
+	BreakerFailureThreshold *int `json:"breaker_failure_threshold,omitempty"`
+	BreakerCooldownMs       *int `json:"breaker_cooldown_ms,omitempty"`
+
+	// Retry* configure how failed calls are retried
+	RetryMaxAttempts   *int    `json:"retry_max_attempts,omitempty"`
+	RetryBackoffMs     *int    `json:"retry_backoff_ms,omitempty"`
+	RetryMaxBackoffMs  *int    `json:"retry_max_backoff_ms,omitempty"`
+	RetryOn            *string `json:"retry_on,omitempty"`
+	RetryNonIdempotent *bool   `json:"retry_non_idempotent,omitempty"`
+
+	// ValidateArguments is whether the arguments of calls are validated against the tool's input schema (enabled by default)
+	ValidateArguments *bool `json:"validate_arguments,omitempty"`
+}
+
+// SetCallPolicy creates the policy of a server or tool, or updates the settings of its
+// existing policy that are set in policy.
+func (c *Client) SetCallPolicy(policy *CallPolicy) (*CallPolicy, error) {
+	u, _ := c.constructAPIEndpoint("/policies")
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize policy into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var updated CallPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListCallPolicies fetches all call policies.
+func (c *Client) ListCallPolicies() ([]*CallPolicy, error) {
+	u, _ := c.constructAPIEndpoint("/policies")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var policies []*CallPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&policies); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return policies, nil
+}
+
+// DeleteCallPolicy removes the policy of a server or tool.
+func (c *Client) DeleteCallPolicy(scopeType, scopeValue string) error {
+	u, _ := c.constructAPIEndpoint("/policies/" + scopeType + "/" + scopeValue)
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
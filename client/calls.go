@@ -0,0 +1,237 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CallCaptureConfig opts an MCP server into capturing the full payloads of the tool calls made to it.
+type CallCaptureConfig struct {
+	ServerName string `json:"server_name"`
+	// MaxPayloadBytes caps the size of the captured arguments and result, 0 means the registry default
+	MaxPayloadBytes int `json:"max_payload_bytes,omitempty"`
+	// RedactFields is a comma-separated list of fields to redact, in addition to the ones always redacted
+	RedactFields string `json:"redact_fields,omitempty"`
+}
+
+// CapturedCall is a tool call captured by the registry, along with its full arguments and result.
+type CapturedCall struct {
+	ID         string `json:"id"`
+	ServerName string `json:"server_name"`
+	ToolName   string `json:"tool_name"`
+	SessionID  string `json:"session_id,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	ClientType string `json:"client_type"`
+
+	Arguments          string `json:"arguments"`
+	ArgumentsTruncated bool   `json:"arguments_truncated,omitempty"`
+	Result             string `json:"result,omitempty"`
+	ResultTruncated    bool   `json:"result_truncated,omitempty"`
+
+	IsError   bool   `json:"is_error"`
+	Error     string `json:"error,omitempty"`
+	ErrorType string `json:"error_type,omitempty"`
+
+	DurationMs int       `json:"duration_ms"`
+	Attempts   int       `json:"attempts"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// CapturedCallFilter selects the captured calls to list, zero fields don't filter.
+type CapturedCallFilter struct {
+	Server     string
+	Tool       string
+	SessionID  string
+	OnlyErrors bool
+	// Since and Until are RFC3339 timestamps or YYYY-MM-DD dates, Since is exclusive
+	Since string
+	Until string
+	Limit int
+}
+
+// SetCallCapture enables capturing the tool calls made to a server, or updates its capture config.
+func (c *Client) SetCallCapture(cfg *CallCaptureConfig) (*CallCaptureConfig, error) {
+	u, _ := c.constructAPIEndpoint("/capture")
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize capture config into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var updated CallCaptureConfig
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListCallCaptures fetches the capture configs of all servers that have capture enabled.
+func (c *Client) ListCallCaptures() ([]*CallCaptureConfig, error) {
+	u, _ := c.constructAPIEndpoint("/capture")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var configs []*CallCaptureConfig
+	if err := json.NewDecoder(resp.Body).Decode(&configs); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return configs, nil
+}
+
+// DeleteCallCapture disables capturing the tool calls made to a server.
+func (c *Client) DeleteCallCapture(serverName string) error {
+	u, _ := c.constructAPIEndpoint("/capture/" + url.PathEscape(serverName))
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// ListCapturedCalls fetches the captured tool calls selected by the filter, the most recent first.
+func (c *Client) ListCapturedCalls(filter CapturedCallFilter) ([]*CapturedCall, error) {
+	u, _ := c.constructAPIEndpoint("/calls")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	q := req.URL.Query()
+	if filter.Server != "" {
+		q.Add("server", filter.Server)
+	}
+	if filter.Tool != "" {
+		q.Add("tool", filter.Tool)
+	}
+	if filter.SessionID != "" {
+		q.Add("session_id", filter.SessionID)
+	}
+	if filter.OnlyErrors {
+		q.Add("errors", "true")
+	}
+	if filter.Since != "" {
+		q.Add("since", filter.Since)
+	}
+	if filter.Until != "" {
+		q.Add("until", filter.Until)
+	}
+	if filter.Limit > 0 {
+		q.Add("limit", strconv.Itoa(filter.Limit))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var calls []*CapturedCall
+	if err := json.NewDecoder(resp.Body).Decode(&calls); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return calls, nil
+}
+
+// GetCapturedCall fetches a captured tool call by ID.
+func (c *Client) GetCapturedCall(id string) (*CapturedCall, error) {
+	u, _ := c.constructAPIEndpoint("/calls/" + url.PathEscape(id))
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var call CapturedCall
+	if err := json.NewDecoder(resp.Body).Decode(&call); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &call, nil
+}
+
+// CallReplay is the outcome of replaying a captured tool call, compared to the recorded outcome.
+type CallReplay struct {
+	CallID     string `json:"call_id"`
+	ServerName string `json:"server_name"`
+	ToolName   string `json:"tool_name"`
+
+	Result          string `json:"result,omitempty"`
+	ResultTruncated bool   `json:"result_truncated,omitempty"`
+	IsError         bool   `json:"is_error"`
+	Error           string `json:"error,omitempty"`
+	ErrorType       string `json:"error_type,omitempty"`
+	DurationMs      int    `json:"duration_ms"`
+
+	Recorded *CapturedCall `json:"recorded"`
+	// Changed is set if the outcome of the replay differs from the recorded one
+	Changed bool `json:"changed"`
+	// Diff is a line diff from the recorded result to the new one
+	Diff string `json:"diff,omitempty"`
+}
+
+// ReplayCapturedCall calls the tool of a captured call again with the same arguments.
+// The call is replayed against server if set, otherwise against the server it was recorded from.
+func (c *Client) ReplayCapturedCall(id, server string) (*CallReplay, error) {
+	u, _ := c.constructAPIEndpoint("/calls/" + url.PathEscape(id) + "/replay")
+	body, err := json.Marshal(map[string]string{"server": server})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize replay request into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var replay CallReplay
+	if err := json.NewDecoder(resp.Body).Decode(&replay); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &replay, nil
+}
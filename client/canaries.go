@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Canary is a synthetic tool call that the registry makes on a schedule to check that a tool works.
+type Canary struct {
+	Name string `json:"name"`
+	// ToolName is the canonical name of the tool to call, ie- <server>/<tool>
+	ToolName   string `json:"tool_name"`
+	ServerName string `json:"server_name,omitempty"`
+
+	Input map[string]any `json:"input,omitempty"`
+	// Expect holds the assertions that the result must meet, like the expect of a test case
+	Expect map[string]any `json:"expect,omitempty"`
+
+	IntervalSeconds  int  `json:"interval_seconds,omitempty"`
+	FailureThreshold int  `json:"failure_threshold,omitempty"`
+	Enabled          bool `json:"enabled"`
+
+	// The outcome of the latest run, only reported by the registry
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastPassed          *bool      `json:"last_passed,omitempty"`
+	LastLatencyMs       int64      `json:"last_latency_ms,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+}
+
+// CanaryRun is the outcome of one run of a canary.
+type CanaryRun struct {
+	CanaryName string    `json:"canary_name"`
+	ServerName string    `json:"server_name"`
+	RanAt      time.Time `json:"ran_at"`
+	Passed     bool      `json:"passed"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// SetCanary creates a canary or replaces the existing one with the same name.
+func (c *Client) SetCanary(canary *Canary) (*Canary, error) {
+	u, _ := c.constructAPIEndpoint("/canaries")
+	body, err := json.Marshal(canary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize canary into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var updated Canary
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListCanaries fetches all canaries along with the outcome of their latest run.
+func (c *Client) ListCanaries() ([]*Canary, error) {
+	u, _ := c.constructAPIEndpoint("/canaries")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var canaries []*Canary
+	if err := json.NewDecoder(resp.Body).Decode(&canaries); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return canaries, nil
+}
+
+// DeleteCanary deletes a canary and its runs.
+func (c *Client) DeleteCanary(name string) error {
+	u, _ := c.constructAPIEndpoint("/canaries/" + url.PathEscape(name))
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// ListCanaryRuns fetches the most recent runs of a canary, newest first.
+// A limit of 0 uses the registry's default.
+func (c *Client) ListCanaryRuns(name string, limit int) ([]*CanaryRun, error) {
+	u, _ := c.constructAPIEndpoint("/canaries/" + url.PathEscape(name) + "/runs")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	if limit > 0 {
+		q := req.URL.Query()
+		q.Add("limit", strconv.Itoa(limit))
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var runs []*CanaryRun
+	if err := json.NewDecoder(resp.Body).Decode(&runs); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return runs, nil
+}
+
+// RunCanary runs a canary right away and returns the outcome.
+func (c *Client) RunCanary(name string) (*CanaryRun, error) {
+	u, _ := c.constructAPIEndpoint("/canaries/" + url.PathEscape(name) + "/run")
+	resp, err := c.HTTPClient.Post(u, "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var run CanaryRun
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Cassette puts an MCP server in record or replay mode.
+type Cassette struct {
+	ServerName string `json:"server_name"`
+	// Mode is either record or replay
+	Mode string `json:"mode"`
+	// Path is the path of the cassette file, relative to the registry's cassette directory
+	Path string `json:"path"`
+	// Match is either strict (the default) or lenient
+	Match string `json:"match,omitempty"`
+}
+
+// UnmatchedCall is a tool call that the cassette of a server in replay mode had no recording of.
+type UnmatchedCall struct {
+	Tool      string    `json:"tool"`
+	Arguments string    `json:"arguments"`
+	Time      time.Time `json:"time"`
+	Fallback  bool      `json:"fallback,omitempty"`
+}
+
+// CassetteStatus is the cassette of a server, along with how it was used since it was loaded.
+type CassetteStatus struct {
+	Cassette
+	RecordedCalls  int             `json:"recorded_calls"`
+	ReplayedCalls  int             `json:"replayed_calls"`
+	UnusedCalls    int             `json:"unused_calls"`
+	UnmatchedCount int             `json:"unmatched_count"`
+	Unmatched      []UnmatchedCall `json:"unmatched,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// SetCassette puts a server in record or replay mode.
+func (c *Client) SetCassette(cassette *Cassette) (*Cassette, error) {
+	u, _ := c.constructAPIEndpoint("/cassettes")
+	body, err := json.Marshal(cassette)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize cassette into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var updated Cassette
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListCassettes fetches the report of the cassettes of all servers in record or replay mode.
+func (c *Client) ListCassettes() ([]*CassetteStatus, error) {
+	u, _ := c.constructAPIEndpoint("/cassettes")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var cassettes []*CassetteStatus
+	if err := json.NewDecoder(resp.Body).Decode(&cassettes); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return cassettes, nil
+}
+
+// DeleteCassette takes a server out of record or replay mode.
+func (c *Client) DeleteCassette(serverName string) error {
+	u, _ := c.constructAPIEndpoint("/cassettes/" + url.PathEscape(serverName))
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+// GetLogLevel returns the current log level of the server.
+func (c *Client) GetLogLevel() (string, error) {
+	u, _ := c.constructAPIEndpoint("/log-level")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var l logLevel
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return l.Level, nil
+}
+
+// SetLogLevel changes the log level of the server without restarting it, and returns the new level.
+func (c *Client) SetLogLevel(level string) (string, error) {
+	u, _ := c.constructAPIEndpoint("/log-level")
+	body, err := json.Marshal(logLevel{Level: level})
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize log level into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var l logLevel
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return l.Level, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // Server represents an MCP server registered in the MCPJungle registry.
@@ -13,6 +14,44 @@ type Server struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
+
+	Endpoints     []ServerEndpoint `json:"endpoints,omitempty"`
+	LoadBalancing string           `json:"load_balancing,omitempty"`
+
+	// CircuitBreaker is only reported when listing servers.
+	CircuitBreaker *CircuitBreakerStatus `json:"circuit_breaker,omitempty"`
+
+	// EndpointStatuses is only reported when listing servers that have endpoints.
+	EndpointStatuses []EndpointStatus `json:"endpoint_statuses,omitempty"`
+
+	// Health is only reported when listing servers.
+	Health *ServerHealth `json:"health,omitempty"`
+}
+
+// ServerEndpoint is one of several URLs serving the same MCP server, eg- a replica of it.
+type ServerEndpoint struct {
+	URL string `json:"url"`
+
+	// Weight is the relative share of calls sent to the endpoint, it defaults to 1.
+	Weight int `json:"weight,omitempty"`
+}
+
+// EndpointStatus is the runtime state of one of the endpoints of an MCP server.
+type EndpointStatus struct {
+	URL       string     `json:"url"`
+	Weight    int        `json:"weight"`
+	Healthy   bool       `json:"healthy"`
+	InFlight  int        `json:"in_flight"`
+	LastError string     `json:"last_error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// CircuitBreakerStatus is the state of the circuit breaker that makes calls to an unhealthy server fail fast.
+type CircuitBreakerStatus struct {
+	// State is one of closed, open, half_open
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
 }
 
 // RegisterServerInput is the input structure for registering a new MCP server.
@@ -20,13 +59,24 @@ type RegisterServerInput struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 
-	// URL is mandatory and must be a valid http/https URL (eg- https://example.com/mcp).
+	// URL is mandatory, unless Endpoints or MockConfig are given, and must be a valid http/https URL (eg- https://example.com/mcp).
 	// MCPJungle only supports streamable HTTP transport as of now.
 	URL string `json:"url"`
 
 	// BearerToken is an optional token used for authenticating requests to the MCP server.
 	// It is useful when the upstream MCP server requires static tokens (e.g., API tokens) for authentication.
 	BearerToken string `json:"bearer_token,omitempty"`
+
+	// Endpoints optionally lists several URLs serving the server, eg- its replicas.
+	// Tool calls are load balanced across them and fail over to another endpoint if one is unreachable.
+	Endpoints []ServerEndpoint `json:"endpoints,omitempty"`
+
+	// LoadBalancing is the strategy used to choose among the endpoints: round_robin (default) or least_in_flight.
+	LoadBalancing string `json:"load_balancing,omitempty"`
+
+	// MockConfig optionally declares a mock server in YAML, which the registry serves in-process.
+	// A mock server has no URL or endpoints.
+	MockConfig string `json:"mock_config,omitempty"`
 }
 
 // RegisterServer registers a new MCP server with the registry.
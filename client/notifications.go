@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NotificationChannel is a destination that alerts are delivered to.
+type NotificationChannel struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	MinSeverity string `json:"min_severity,omitempty"`
+
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+
+	SMTPHost     string `json:"smtp_host,omitempty"`
+	SMTPPort     int    `json:"smtp_port,omitempty"`
+	SMTPUsername string `json:"smtp_username,omitempty"`
+	SMTPPassword string `json:"smtp_password,omitempty"`
+	EmailFrom    string `json:"email_from,omitempty"`
+	EmailTo      string `json:"email_to,omitempty"`
+}
+
+// CreateNotificationChannel creates a new notification channel in the registry.
+func (c *Client) CreateNotificationChannel(channel *NotificationChannel) error {
+	u, _ := c.constructAPIEndpoint("/notification-channels")
+	body, err := json.Marshal(channel)
+	if err != nil {
+		return fmt.Errorf("failed to serialize notification channel into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ListNotificationChannels fetches all notification channels.
+// Secrets and passwords are never returned by the server.
+func (c *Client) ListNotificationChannels() ([]*NotificationChannel, error) {
+	u, _ := c.constructAPIEndpoint("/notification-channels")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var channels []*NotificationChannel
+	if err := json.NewDecoder(resp.Body).Decode(&channels); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return channels, nil
+}
+
+// DeleteNotificationChannel deletes a notification channel by name.
+func (c *Client) DeleteNotificationChannel(name string) error {
+	u, _ := c.constructAPIEndpoint("/notification-channels/" + name)
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// TestNotificationChannel asks the server to deliver a test alert to the channel.
+func (c *Client) TestNotificationChannel(name string) error {
+	u, _ := c.constructAPIEndpoint("/notification-channels/" + name + "/test")
+	resp, err := c.HTTPClient.Post(u, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ModelPrice is an entry of the registry's pricing catalog. Prices are in USD per million tokens.
+type ModelPrice struct {
+	Model             string  `json:"model"`
+	InputPerMTok      float64 `json:"input_per_mtok"`
+	OutputPerMTok     float64 `json:"output_per_mtok"`
+	CacheReadPerMTok  float64 `json:"cache_read_per_mtok"`
+	CacheWritePerMTok float64 `json:"cache_write_per_mtok"`
+	// EffectiveFrom is a YYYY-MM-DD date or an RFC3339 timestamp when setting a price.
+	// An empty value means the price applies to all usage until the model's next price.
+	EffectiveFrom string `json:"effective_from,omitempty"`
+}
+
+// CostRecomputation is the outcome of recomputing historical costs.
+type CostRecomputation struct {
+	Updated  int `json:"updated"`
+	Unpriced int `json:"unpriced"`
+}
+
+// SetModelPrice adds a price to the pricing catalog, replacing the model's price with the same effective date.
+func (c *Client) SetModelPrice(price *ModelPrice) error {
+	u, _ := c.constructAPIEndpoint("/pricing")
+	body, err := json.Marshal(price)
+	if err != nil {
+		return fmt.Errorf("failed to serialize price into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ListModelPrices fetches the pricing catalog, optionally only the prices of a single model.
+func (c *Client) ListModelPrices(model string) ([]*ModelPrice, error) {
+	u, _ := c.constructAPIEndpoint("/pricing")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	if model != "" {
+		q := req.URL.Query()
+		q.Add("model", model)
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var prices []struct {
+		ModelPrice
+		EffectiveFrom time.Time `json:"effective_from"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	result := make([]*ModelPrice, len(prices))
+	for i, p := range prices {
+		result[i] = &p.ModelPrice
+		if !p.EffectiveFrom.IsZero() {
+			result[i].EffectiveFrom = p.EffectiveFrom.Format(time.RFC3339)
+		}
+	}
+	return result, nil
+}
+
+// DeleteModelPrice removes the model's price with the given effective date,
+// or all of the model's prices if effectiveFrom is empty.
+func (c *Client) DeleteModelPrice(model, effectiveFrom string) error {
+	// the slashes of model names like openai/gpt-4o are kept, the registry matches the rest of the path
+	segments := strings.Split(model, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	u, _ := c.constructAPIEndpoint("/pricing/" + strings.Join(segments, "/"))
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+	if effectiveFrom != "" {
+		q := req.URL.Query()
+		q.Add("effective_from", effectiveFrom)
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// RecomputeCosts recomputes the cost of all usage in the window from the pricing catalog,
+// optionally only the usage of a single model.
+// from and to are either YYYY-MM-DD dates or RFC3339 timestamps.
+func (c *Client) RecomputeCosts(from, to, model string) (*CostRecomputation, error) {
+	u, _ := c.constructAPIEndpoint("/pricing/recompute")
+	body, err := json.Marshal(map[string]string{"from": from, "to": to, "model": model})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize request into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var result CostRecomputation
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
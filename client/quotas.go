@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Quota caps the number of tool calls or the cost incurred in a period.
+type Quota struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Metric      string  `json:"metric"`
+	Period      string  `json:"period"`
+	Limit       float64 `json:"limit"`
+	ScopeType   string  `json:"scope_type,omitempty"`
+	// ScopeValue is the API key, client type, server name or canonical tool name the quota is scoped to.
+	// When listing quotas, the hash of the API key is returned instead of the key.
+	ScopeValue string `json:"scope_value,omitempty"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// QuotaStatus is a quota along with its consumption in the current period.
+type QuotaStatus struct {
+	Quota
+	Current  float64   `json:"current"`
+	ResetsAt time.Time `json:"resets_at"`
+	Exceeded bool      `json:"exceeded"`
+}
+
+// CreateQuota creates a new quota in the registry.
+func (c *Client) CreateQuota(quota *Quota) error {
+	u, _ := c.constructAPIEndpoint("/quotas")
+	body, err := json.Marshal(quota)
+	if err != nil {
+		return fmt.Errorf("failed to serialize quota into JSON: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Post(u, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// ListQuotas fetches all quotas along with their current consumption.
+func (c *Client) ListQuotas() ([]*QuotaStatus, error) {
+	u, _ := c.constructAPIEndpoint("/quotas")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var quotas []*QuotaStatus
+	if err := json.NewDecoder(resp.Body).Decode(&quotas); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return quotas, nil
+}
+
+// DeleteQuota deletes a quota by name.
+func (c *Client) DeleteQuota(name string) error {
+	u, _ := c.constructAPIEndpoint("/quotas/" + name)
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
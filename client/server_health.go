@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ServerHealth summarizes the periodic health checks of an MCP server.
+type ServerHealth struct {
+	ServerName string `json:"server_name"`
+	// Status is one of healthy, degraded (some canaries are failing), unhealthy, unknown (not checked yet)
+	Status    string     `json:"status"`
+	LatencyMs int64      `json:"latency_ms"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+
+	// Uptime is the fraction of the checks of the last 24 hours that succeeded
+	Uptime *float64 `json:"uptime_24h,omitempty"`
+
+	// FailingCanaries lists the canaries of the server that reached their failure threshold
+	FailingCanaries []string `json:"failing_canaries,omitempty"`
+}
+
+// ServerHealthCheck is the outcome of one health check of an MCP server.
+type ServerHealthCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Healthy   bool      `json:"healthy"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ServerHealthHistory is the health of an MCP server along with its most recent health checks, newest first.
+type ServerHealthHistory struct {
+	ServerHealth
+	History []ServerHealthCheck `json:"history"`
+}
+
+// ListServerHealth fetches the health of all registered servers.
+func (c *Client) ListServerHealth() ([]*ServerHealth, error) {
+	u, _ := c.constructAPIEndpoint("/servers/health")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var health []*ServerHealth
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return health, nil
+}
+
+// GetServerHealthHistory fetches the health of a server along with up to limit of its most recent health checks.
+// A limit of 0 uses the registry's default.
+func (c *Client) GetServerHealthHistory(name string, limit int) (*ServerHealthHistory, error) {
+	u, _ := c.constructAPIEndpoint("/servers/" + url.PathEscape(name) + "/health")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	if limit > 0 {
+		q := req.URL.Query()
+		q.Add("limit", strconv.Itoa(limit))
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var history ServerHealthHistory
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &history, nil
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ServerSLO is the availability objective of an MCP server.
+type ServerSLO struct {
+	ServerName string `json:"server_name"`
+	// Target is the fraction of health checks and tool calls that must succeed, eg- 0.999
+	Target float64 `json:"target"`
+}
+
+// ServerSLOReport is the availability of an MCP server over a window, measured against its SLO.
+type ServerSLOReport struct {
+	ServerName string   `json:"server_name"`
+	Target     *float64 `json:"target,omitempty"`
+
+	HealthChecks    int64 `json:"health_checks"`
+	HealthyChecks   int64 `json:"healthy_checks"`
+	ToolCalls       int64 `json:"tool_calls"`
+	FailedToolCalls int64 `json:"failed_tool_calls"`
+
+	Availability        *float64 `json:"availability,omitempty"`
+	ErrorBudgetConsumed *float64 `json:"error_budget_consumed,omitempty"`
+	BurnRate            *float64 `json:"burn_rate,omitempty"`
+	Met                 *bool    `json:"met,omitempty"`
+}
+
+// SLOReport is the availability of every registered MCP server over a window.
+type SLOReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Elapsed is the fraction of the window that has elapsed
+	Elapsed float64            `json:"elapsed"`
+	Servers []*ServerSLOReport `json:"servers"`
+}
+
+// SetServerSLO sets the SLO of a server, replacing its existing SLO if any.
+func (c *Client) SetServerSLO(slo *ServerSLO) (*ServerSLO, error) {
+	u, _ := c.constructAPIEndpoint("/slos")
+	body, err := json.Marshal(slo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize SLO into JSON: %w", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var updated ServerSLO
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListServerSLOs fetches the SLOs of all servers.
+func (c *Client) ListServerSLOs() ([]*ServerSLO, error) {
+	u, _ := c.constructAPIEndpoint("/slos")
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var slos []*ServerSLO
+	if err := json.NewDecoder(resp.Body).Decode(&slos); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return slos, nil
+}
+
+// DeleteServerSLO deletes the SLO of a server.
+func (c *Client) DeleteServerSLO(serverName string) error {
+	u, _ := c.constructAPIEndpoint("/slos/" + url.PathEscape(serverName))
+	req, _ := http.NewRequest(http.MethodDelete, u, nil)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status from server: %s, body: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// GetSLOReport fetches the availability of every server in the window [from, to) against its SLO.
+// from and to are RFC3339 timestamps or YYYY-MM-DD dates, the report covers the current month if both are empty.
+func (c *Client) GetSLOReport(from, to string) (*SLOReport, error) {
+	u, _ := c.constructAPIEndpoint("/slos/report")
+	req, _ := http.NewRequest(http.MethodGet, u, nil)
+	q := req.URL.Query()
+	if from != "" {
+		q.Add("from", from)
+	}
+	if to != "" {
+		q.Add("to", to)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, body)
+	}
+
+	var report SLOReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &report, nil
+}
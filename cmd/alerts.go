@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Manage alerts and alert rules",
+}
+
+var listAlertsCmdAll bool
+
+var listAlertsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List open alerts",
+	RunE:  runListAlerts,
+}
+
+var ackAlertCmd = &cobra.Command{
+	Use:   "ack <id>",
+	Short: "Acknowledge an alert",
+	Long:  "Acknowledge an alert to signal that someone is looking into it. The alert stays open until it is resolved.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAckAlert,
+}
+
+var resolveAlertCmd = &cobra.Command{
+	Use:   "resolve <id>",
+	Short: "Resolve an alert",
+	Long: "Resolve an alert manually.\n" +
+		"If the alert was raised by a rule whose condition still holds, the rule raises a new alert on its next evaluation.",
+	Args: cobra.ExactArgs(1),
+	RunE: runResolveAlert,
+}
+
+var alertRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Manage the rules that raise alerts",
+	Long: "Alert rules are evaluated periodically by the registry.\n" +
+		"While a rule's condition holds, the rule has a single open alert, which is resolved automatically once the condition clears.",
+}
+
+var (
+	createAlertRuleCmdType           string
+	createAlertRuleCmdDesc           string
+	createAlertRuleCmdSeverity       string
+	createAlertRuleCmdThreshold      float64
+	createAlertRuleCmdWindow         int
+	createAlertRuleCmdBaselineWindow int
+	createAlertRuleCmdMinSamples     int
+	createAlertRuleCmdResource       string
+	createAlertRuleCmdDisabled       bool
+)
+
+var createAlertRuleCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an alert rule",
+	Long: "Create an alert rule. Supported types:\n" +
+		"  daily_cost         cost since the start of the day (UTC) reaches --threshold USD\n" +
+		"  monthly_cost       cost since the start of the month (UTC) reaches --threshold USD\n" +
+		"  error_rate         ratio of failed tool calls in the last --window minutes reaches --threshold (0 to 1)\n" +
+		"  usage_spike        token usage in the last --window minutes is --threshold times the baseline,\n" +
+		"                     averaged over the preceding --baseline-window minutes\n" +
+		"  server_unreachable the MCP server given by --resource server:<name> cannot be connected to",
+	Args: cobra.ExactArgs(1),
+	RunE: runCreateAlertRule,
+}
+
+var listAlertRulesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List alert rules",
+	RunE:  runListAlertRules,
+}
+
+var deleteAlertRuleCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an alert rule",
+	Long:  "Delete an alert rule. Any open alert raised by the rule is resolved.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteAlertRule,
+}
+
+func init() {
+	createAlertRuleCmd.Flags().StringVar(&createAlertRuleCmdType, "type", "", "Rule type")
+	createAlertRuleCmd.Flags().StringVar(&createAlertRuleCmdDesc, "description", "", "Rule description")
+	createAlertRuleCmd.Flags().StringVar(
+		&createAlertRuleCmdSeverity,
+		"severity",
+		"medium",
+		"Severity of the alerts raised by this rule: low, medium, high or critical",
+	)
+	createAlertRuleCmd.Flags().Float64Var(&createAlertRuleCmdThreshold, "threshold", 0, "Threshold of the rule")
+	createAlertRuleCmd.Flags().IntVar(
+		&createAlertRuleCmdWindow,
+		"window",
+		0,
+		"Evaluation window in minutes (error_rate, usage_spike)",
+	)
+	createAlertRuleCmd.Flags().IntVar(
+		&createAlertRuleCmdBaselineWindow,
+		"baseline-window",
+		0,
+		"Baseline period in minutes preceding the evaluation window (usage_spike)",
+	)
+	createAlertRuleCmd.Flags().IntVar(
+		&createAlertRuleCmdMinSamples,
+		"min-samples",
+		0,
+		"Minimum number of samples in the window for the rule to trigger (error_rate, usage_spike)",
+	)
+	createAlertRuleCmd.Flags().StringVar(
+		&createAlertRuleCmdResource,
+		"resource",
+		"",
+		"Scope the rule to a single resource, as <type>:<id> (eg- server:github, model:claude-sonnet-4, client:cursor)",
+	)
+	createAlertRuleCmd.Flags().BoolVar(&createAlertRuleCmdDisabled, "disabled", false, "Create the rule disabled")
+	_ = createAlertRuleCmd.MarkFlagRequired("type")
+
+	listAlertsCmd.Flags().BoolVar(&listAlertsCmdAll, "all", false, "Include resolved alerts")
+
+	alertsCmd.AddCommand(listAlertsCmd)
+	alertsCmd.AddCommand(ackAlertCmd)
+	alertsCmd.AddCommand(resolveAlertCmd)
+
+	alertRulesCmd.AddCommand(createAlertRuleCmd)
+	alertRulesCmd.AddCommand(listAlertRulesCmd)
+	alertRulesCmd.AddCommand(deleteAlertRuleCmd)
+	alertsCmd.AddCommand(alertRulesCmd)
+	rootCmd.AddCommand(alertsCmd)
+}
+
+func runCreateAlertRule(cmd *cobra.Command, args []string) error {
+	rule := &client.AlertRule{
+		Name:                  args[0],
+		Description:           createAlertRuleCmdDesc,
+		Type:                  createAlertRuleCmdType,
+		Severity:              createAlertRuleCmdSeverity,
+		Threshold:             createAlertRuleCmdThreshold,
+		WindowMinutes:         createAlertRuleCmdWindow,
+		BaselineWindowMinutes: createAlertRuleCmdBaselineWindow,
+		MinSamples:            createAlertRuleCmdMinSamples,
+		Enabled:               !createAlertRuleCmdDisabled,
+	}
+	if createAlertRuleCmdResource != "" {
+		resourceType, resourceID, ok := strings.Cut(createAlertRuleCmdResource, ":")
+		if !ok || resourceType == "" || resourceID == "" {
+			return fmt.Errorf("invalid resource '%s', expected <type>:<id>", createAlertRuleCmdResource)
+		}
+		rule.ResourceType = resourceType
+		rule.ResourceID = &resourceID
+	}
+
+	created, err := apiClient.CreateAlertRule(rule)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	fmt.Printf("Alert rule %s created successfully!\n", created.Name)
+	return nil
+}
+
+func runListAlertRules(cmd *cobra.Command, args []string) error {
+	rules, err := apiClient.ListAlertRules()
+	if err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("There are no alert rules in the registry")
+		return nil
+	}
+	for i, r := range rules {
+		status := "enabled"
+		if !r.Enabled {
+			status = "disabled"
+		}
+		scope := "global"
+		if r.ResourceID != nil {
+			scope = r.ResourceType + ":" + *r.ResourceID
+		}
+		fmt.Printf("%d. %s [%s, %s]\n", i+1, r.Name, r.Severity, status)
+		fmt.Printf("type: %s, threshold: %g, scope: %s\n", r.Type, r.Threshold, scope)
+		if r.Description != "" {
+			fmt.Println(r.Description)
+		}
+		if i < len(rules)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeleteAlertRule(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteAlertRule(args[0]); err != nil {
+		return fmt.Errorf("failed to delete alert rule %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted alert rule %s\n", args[0])
+	return nil
+}
+
+func runListAlerts(cmd *cobra.Command, args []string) error {
+	alerts, err := apiClient.ListAlerts(listAlertsCmdAll)
+	if err != nil {
+		return fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	if len(alerts) == 0 {
+		fmt.Println("There are no alerts")
+		return nil
+	}
+	for i, a := range alerts {
+		status := "open"
+		switch {
+		case a.Resolved:
+			status = "resolved"
+		case a.Acknowledged:
+			status = "acknowledged"
+		}
+		fmt.Printf("%d. %s [%s, %s]\n", i+1, a.Title, a.Severity, status)
+		fmt.Printf("id: %s, raised at: %s\n", a.ID, a.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Println(a.Message)
+		if i < len(alerts)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runAckAlert(cmd *cobra.Command, args []string) error {
+	if err := apiClient.AcknowledgeAlert(args[0]); err != nil {
+		return fmt.Errorf("failed to acknowledge alert %s: %w", args[0], err)
+	}
+	fmt.Printf("Alert %s acknowledged\n", args[0])
+	return nil
+}
+
+func runResolveAlert(cmd *cobra.Command, args []string) error {
+	if err := apiClient.ResolveAlert(args[0]); err != nil {
+		return fmt.Errorf("failed to resolve alert %s: %w", args[0], err)
+	}
+	fmt.Printf("Alert %s resolved\n", args[0])
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Usage analytics and reports",
+}
+
+var (
+	analyticsExportCmdFrom   string
+	analyticsExportCmdTo     string
+	analyticsExportCmdMonth  string
+	analyticsExportCmdFormat string
+	analyticsExportCmdOutput string
+)
+
+var analyticsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a usage report",
+	Long: "Export token usage and costs for a time window, including per-model, per-client and per-server breakdowns.\n" +
+		"Specify the window either with --month or with --from and --to.",
+	RunE: runAnalyticsExport,
+}
+
+func init() {
+	analyticsExportCmd.Flags().StringVar(
+		&analyticsExportCmdFrom,
+		"from",
+		"",
+		"Start of the report window (YYYY-MM-DD or RFC3339 timestamp), inclusive",
+	)
+	analyticsExportCmd.Flags().StringVar(
+		&analyticsExportCmdTo,
+		"to",
+		"",
+		"End of the report window (YYYY-MM-DD or RFC3339 timestamp). A date includes the whole day.",
+	)
+	analyticsExportCmd.Flags().StringVar(
+		&analyticsExportCmdMonth,
+		"month",
+		"",
+		"Export the report for a whole calendar month (YYYY-MM), eg- 2025-06",
+	)
+	analyticsExportCmd.Flags().StringVar(
+		&analyticsExportCmdFormat,
+		"format",
+		"csv",
+		"Report format: csv, json or ndjson",
+	)
+	analyticsExportCmd.Flags().StringVarP(
+		&analyticsExportCmdOutput,
+		"output",
+		"o",
+		"",
+		"File to write the report to (defaults to stdout)",
+	)
+	analyticsExportCmd.MarkFlagsMutuallyExclusive("month", "from")
+	analyticsExportCmd.MarkFlagsMutuallyExclusive("month", "to")
+	analyticsExportCmd.MarkFlagsRequiredTogether("from", "to")
+	analyticsExportCmd.MarkFlagsOneRequired("month", "from")
+
+	analyticsCmd.AddCommand(analyticsExportCmd)
+	rootCmd.AddCommand(analyticsCmd)
+}
+
+func runAnalyticsExport(cmd *cobra.Command, args []string) error {
+	from, to := analyticsExportCmdFrom, analyticsExportCmdTo
+	if analyticsExportCmdMonth != "" {
+		var err error
+		if from, to, err = monthWindow(analyticsExportCmdMonth); err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if analyticsExportCmdOutput != "" {
+		f, err := os.Create(analyticsExportCmdOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := apiClient.ExportUsageReport(from, to, analyticsExportCmdFormat, w); err != nil {
+		return fmt.Errorf("failed to export usage report: %w", err)
+	}
+
+	if analyticsExportCmdOutput != "" {
+		fmt.Printf("Usage report written to %s\n", analyticsExportCmdOutput)
+	}
+	return nil
+}
+
+// monthWindow returns the boundaries of a calendar month given as YYYY-MM, as RFC3339 timestamps.
+// The end of the window is exclusive, so the window covers the whole month.
+func monthWindow(month string) (string, string, error) {
+	m, err := time.Parse("2006-01", month)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid month '%s', expected format YYYY-MM", month)
+	}
+	return m.Format(time.RFC3339), m.AddDate(0, 1, 0).Format(time.RFC3339), nil
+}
@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+// callsTailInterval is how often `calls tail` polls the registry for new calls
+const callsTailInterval = time.Second
+
+var callsCmd = &cobra.Command{
+	Use:   "calls",
+	Short: "Inspect the tool calls captured by the registry",
+	Long: "Inspect the full arguments and results of the tool calls made to MCP servers that have capture enabled.\n" +
+		"Capture is opt-in per server, see `mcpjungle calls capture enable`.",
+}
+
+var (
+	listCallsCmdServer  string
+	listCallsCmdTool    string
+	listCallsCmdSession string
+	listCallsCmdErrors  bool
+	listCallsCmdSince   string
+	listCallsCmdLimit   int
+)
+
+var listCallsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List captured tool calls, the most recent first",
+	RunE:  runListCalls,
+}
+
+var showCallCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the arguments and result of a captured tool call",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShowCall,
+}
+
+var tailCallsCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Follow the tool calls as they are captured",
+	Long:  "Print the tool calls captured from now on, until interrupted. Accepts the same filters as `calls list`.",
+	RunE:  runTailCalls,
+}
+
+var replayCallsCmdServer string
+
+var replayCallsCmd = &cobra.Command{
+	Use:   "replay <id...>",
+	Short: "Call the tools of captured calls again and compare the results to the recorded ones",
+	Long: "Call the tool of every captured call again with the same arguments, eg- to check that a fix works,\n" +
+		"and show how the new result differs from the recorded one.\n" +
+		"Calls whose arguments were truncated or redacted when captured can't be replayed.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReplayCalls,
+}
+
+var captureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Manage which MCP servers have their tool calls captured",
+	Long: "Captured payloads may contain sensitive data. Fields named like credentials (eg- password, token, api_key)\n" +
+		"are always redacted, use --redact to redact more fields.",
+}
+
+var (
+	enableCaptureCmdMaxBytes int
+	enableCaptureCmdRedact   []string
+)
+
+var enableCaptureCmd = &cobra.Command{
+	Use:   "enable <server>",
+	Short: "Capture the tool calls made to a server",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnableCapture,
+}
+
+var disableCaptureCmd = &cobra.Command{
+	Use:   "disable <server>",
+	Short: "Stop capturing the tool calls made to a server",
+	Long:  "Stop capturing the tool calls made to a server. The calls captured so far are kept.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDisableCapture,
+}
+
+var listCapturesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the servers that have capture enabled",
+	RunE:  runListCaptures,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{listCallsCmd, tailCallsCmd} {
+		c.Flags().StringVar(&listCallsCmdServer, "server", "", "Only show the calls made to this server")
+		c.Flags().StringVar(&listCallsCmdTool, "tool", "", "Only show the calls of this tool (its name within the server)")
+		c.Flags().StringVar(&listCallsCmdSession, "session", "", "Only show the calls made in this MCP session")
+		c.Flags().BoolVar(&listCallsCmdErrors, "errors", false, "Only show the calls that failed")
+	}
+	listCallsCmd.Flags().StringVar(
+		&listCallsCmdSince,
+		"since",
+		"",
+		"Only show the calls made since this time: a duration ago (eg- 15m), a YYYY-MM-DD date or an RFC3339 timestamp",
+	)
+	listCallsCmd.Flags().IntVar(&listCallsCmdLimit, "limit", 0, "Maximum number of calls to show (default 50)")
+
+	replayCallsCmd.Flags().StringVar(
+		&replayCallsCmdServer,
+		"server",
+		"",
+		"Replay the calls against this server instead of the one they were made to",
+	)
+
+	enableCaptureCmd.Flags().IntVar(
+		&enableCaptureCmdMaxBytes,
+		"max-bytes",
+		0,
+		"Size cap of the captured arguments and result, larger payloads are truncated (default 64KiB)",
+	)
+	enableCaptureCmd.Flags().StringSliceVar(
+		&enableCaptureCmdRedact,
+		"redact",
+		nil,
+		"Names of additional fields to redact from the payloads, eg- ssn,email",
+	)
+
+	captureCmd.AddCommand(enableCaptureCmd)
+	captureCmd.AddCommand(disableCaptureCmd)
+	captureCmd.AddCommand(listCapturesCmd)
+
+	callsCmd.AddCommand(listCallsCmd)
+	callsCmd.AddCommand(showCallCmd)
+	callsCmd.AddCommand(tailCallsCmd)
+	callsCmd.AddCommand(replayCallsCmd)
+	callsCmd.AddCommand(captureCmd)
+	rootCmd.AddCommand(callsCmd)
+}
+
+// parseSince converts a duration ago (eg- 15m) into a timestamp, other values are passed to the registry as they are.
+func parseSince(v string) string {
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d).UTC().Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func callsFilterFromFlags() client.CapturedCallFilter {
+	return client.CapturedCallFilter{
+		Server:     listCallsCmdServer,
+		Tool:       listCallsCmdTool,
+		SessionID:  listCallsCmdSession,
+		OnlyErrors: listCallsCmdErrors,
+	}
+}
+
+func printCallSummary(call *client.CapturedCall) {
+	outcome := "ok"
+	if call.IsError {
+		outcome = "error (" + call.ErrorType + ")"
+	}
+	fmt.Printf("%s  %s/%s  %dms  %s  %s\n",
+		call.Timestamp.Local().Format(time.DateTime),
+		call.ServerName,
+		call.ToolName,
+		call.DurationMs,
+		outcome,
+		call.ID,
+	)
+}
+
+func runListCalls(cmd *cobra.Command, args []string) error {
+	filter := callsFilterFromFlags()
+	filter.Limit = listCallsCmdLimit
+	if listCallsCmdSince != "" {
+		filter.Since = parseSince(listCallsCmdSince)
+	}
+	calls, err := apiClient.ListCapturedCalls(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list captured calls: %w", err)
+	}
+	if len(calls) == 0 {
+		fmt.Println("There are no captured calls matching the filters")
+		return nil
+	}
+	for _, call := range calls {
+		printCallSummary(call)
+	}
+	return nil
+}
+
+// formatPayload indents a captured JSON payload, truncated payloads are no longer valid JSON and are printed as they are.
+func formatPayload(payload string, truncated bool) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(payload), "", "  "); err != nil {
+		out.Reset()
+		out.WriteString(payload)
+	}
+	if truncated {
+		out.WriteString("\n... (truncated)")
+	}
+	return out.String()
+}
+
+func runShowCall(cmd *cobra.Command, args []string) error {
+	call, err := apiClient.GetCapturedCall(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get captured call %s: %w", args[0], err)
+	}
+
+	fmt.Printf("ID: %s\n", call.ID)
+	fmt.Printf("Tool: %s/%s\n", call.ServerName, call.ToolName)
+	fmt.Printf("Time: %s\n", call.Timestamp.Local().Format(time.RFC3339))
+	fmt.Printf("Duration: %dms (%d attempt(s))\n", call.DurationMs, call.Attempts)
+	fmt.Printf("Client: %s\n", call.ClientType)
+	if call.SessionID != "" {
+		fmt.Printf("Session: %s\n", call.SessionID)
+	}
+	if call.RequestID != "" {
+		fmt.Printf("Request: %s\n", call.RequestID)
+	}
+	if call.IsError {
+		fmt.Printf("Outcome: error (%s)\n", call.ErrorType)
+	} else {
+		fmt.Println("Outcome: ok")
+	}
+	if call.Error != "" {
+		fmt.Printf("Error: %s\n", call.Error)
+	}
+
+	fmt.Println("\nArguments:")
+	fmt.Println(formatPayload(call.Arguments, call.ArgumentsTruncated))
+	if call.Result != "" {
+		fmt.Println("\nResult:")
+		fmt.Println(formatPayload(call.Result, call.ResultTruncated))
+	}
+	return nil
+}
+
+func runTailCalls(cmd *cobra.Command, args []string) error {
+	filter := callsFilterFromFlags()
+	since := time.Now().UTC()
+	// calls are captured once they complete, so a call can be stored with a timestamp slightly older than
+	// the newest one already seen. Poll with some overlap and skip the calls already printed.
+	const overlap = 5 * time.Second
+	seen := make(map[string]time.Time)
+	filter.Since = since.Add(-overlap).Format(time.RFC3339Nano)
+	recent, err := apiClient.ListCapturedCalls(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list captured calls: %w", err)
+	}
+	// only print the calls made from now on
+	for _, call := range recent {
+		seen[call.ID] = call.Timestamp
+	}
+
+	ticker := time.NewTicker(callsTailInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cmd.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		filter.Since = since.Add(-overlap).Format(time.RFC3339Nano)
+		calls, err := apiClient.ListCapturedCalls(filter)
+		if err != nil {
+			return fmt.Errorf("failed to list captured calls: %w", err)
+		}
+		// calls are listed the most recent first, print them in the order they were made
+		for i := len(calls) - 1; i >= 0; i-- {
+			call := calls[i]
+			if _, ok := seen[call.ID]; ok || call.Timestamp.Before(since.Add(-overlap)) {
+				continue
+			}
+			seen[call.ID] = call.Timestamp
+			printCallSummary(call)
+			if call.Timestamp.After(since) {
+				since = call.Timestamp
+			}
+		}
+		for id, ts := range seen {
+			if ts.Before(since.Add(-overlap)) {
+				delete(seen, id)
+			}
+		}
+	}
+}
+
+func runReplayCalls(cmd *cobra.Command, args []string) error {
+	failed := 0
+	for i, id := range args {
+		if i > 0 {
+			fmt.Println()
+		}
+		replay, err := apiClient.ReplayCapturedCall(id, replayCallsCmdServer)
+		if err != nil {
+			fmt.Printf("Failed to replay call %s: %v\n", id, err)
+			failed++
+			continue
+		}
+
+		outcome := "ok"
+		if replay.IsError {
+			outcome = "error (" + replay.ErrorType + ")"
+		}
+		verdict := "unchanged"
+		if replay.Changed {
+			verdict = "changed"
+		}
+		fmt.Printf("Replayed call %s to %s/%s: %s in %dms, %s\n",
+			id, replay.ServerName, replay.ToolName, outcome, replay.DurationMs, verdict)
+		if replay.Error != "" {
+			fmt.Printf("Error: %s\n", replay.Error)
+		}
+		if replay.Diff != "" {
+			fmt.Print(replay.Diff)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to replay %d of %d call(s)", failed, len(args))
+	}
+	return nil
+}
+
+func runEnableCapture(cmd *cobra.Command, args []string) error {
+	cfg, err := apiClient.SetCallCapture(&client.CallCaptureConfig{
+		ServerName:      args[0],
+		MaxPayloadBytes: enableCaptureCmdMaxBytes,
+		RedactFields:    strings.Join(enableCaptureCmdRedact, ","),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable capture for %s: %w", args[0], err)
+	}
+	fmt.Printf("Tool calls made to %s are now captured\n", cfg.ServerName)
+	return nil
+}
+
+func runDisableCapture(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteCallCapture(args[0]); err != nil {
+		return fmt.Errorf("failed to disable capture for %s: %w", args[0], err)
+	}
+	fmt.Printf("Tool calls made to %s are no longer captured\n", args[0])
+	return nil
+}
+
+func runListCaptures(cmd *cobra.Command, args []string) error {
+	configs, err := apiClient.ListCallCaptures()
+	if err != nil {
+		return fmt.Errorf("failed to list capture configs: %w", err)
+	}
+	if len(configs) == 0 {
+		fmt.Println("No server has capture enabled")
+		return nil
+	}
+	for i, cfg := range configs {
+		maxBytes := "default size cap"
+		if cfg.MaxPayloadBytes > 0 {
+			maxBytes = fmt.Sprintf("%d bytes max", cfg.MaxPayloadBytes)
+		}
+		fmt.Printf("%d. %s (%s)", i+1, cfg.ServerName, maxBytes)
+		if cfg.RedactFields != "" {
+			fmt.Printf(", also redacting: %s", cfg.RedactFields)
+		}
+		fmt.Println()
+	}
+	return nil
+}
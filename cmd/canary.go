@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var canaryCmd = &cobra.Command{
+	Use:   "canary",
+	Short: "Manage canary checks, synthetic tool calls that the registry makes on a schedule",
+	Long: "A successful health check only tells that a server can be connected to, not that its tools work.\n" +
+		"A canary calls a tool with an input on a schedule and checks the result, like a case of a test suite.\n" +
+		"The registry keeps the outcome and latency of every run. A canary that fails repeatedly raises an alert\n" +
+		"and degrades the health of its server until it passes again.",
+}
+
+var (
+	setCanaryCmdTool             string
+	setCanaryCmdInput            string
+	setCanaryCmdExpect           string
+	setCanaryCmdInterval         time.Duration
+	setCanaryCmdFailureThreshold int
+	setCanaryCmdDisabled         bool
+)
+
+var setCanaryCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create a canary or replace the existing one with the same name",
+	Long: "Create a canary or replace the existing one with the same name.\n" +
+		"The assertions are given in YAML (or JSON) like the expect of a test case (see the test command),\n" +
+		"eg- --expect '{is_error: false, text_contains: [Sunny], max_latency_ms: 2000}'",
+	Args: cobra.ExactArgs(1),
+	RunE: runSetCanary,
+}
+
+var listCanariesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List canaries along with the outcome of their latest run",
+	RunE:  runListCanaries,
+}
+
+var deleteCanaryCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a canary and its runs",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteCanary,
+}
+
+var canaryRunsCmdLimit int
+
+var canaryRunsCmd = &cobra.Command{
+	Use:   "runs <name>",
+	Short: "Show the most recent runs of a canary",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCanaryRuns,
+}
+
+var runCanaryCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a canary right away, exiting with an error if it fails",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRunCanary,
+}
+
+func init() {
+	setCanaryCmd.Flags().StringVar(&setCanaryCmdTool, "tool", "", "Name of the tool to call (eg- github/list_repos)")
+	setCanaryCmd.Flags().StringVar(&setCanaryCmdInput, "input", "", "Input of the tool call, a JSON object")
+	setCanaryCmd.Flags().StringVar(&setCanaryCmdExpect, "expect", "", "Assertions that the result must meet, in YAML or JSON")
+	setCanaryCmd.Flags().DurationVar(&setCanaryCmdInterval, "interval", 5*time.Minute, "How often the canary runs")
+	setCanaryCmd.Flags().IntVar(
+		&setCanaryCmdFailureThreshold,
+		"failure-threshold",
+		3,
+		"Number of consecutive failed runs that raises an alert",
+	)
+	setCanaryCmd.Flags().BoolVar(&setCanaryCmdDisabled, "disabled", false, "Create the canary without running it")
+	_ = setCanaryCmd.MarkFlagRequired("tool")
+
+	canaryRunsCmd.Flags().IntVar(&canaryRunsCmdLimit, "limit", 20, "Number of recent runs to show")
+
+	canaryCmd.AddCommand(setCanaryCmd)
+	canaryCmd.AddCommand(listCanariesCmd)
+	canaryCmd.AddCommand(deleteCanaryCmd)
+	canaryCmd.AddCommand(canaryRunsCmd)
+	canaryCmd.AddCommand(runCanaryCmd)
+	rootCmd.AddCommand(canaryCmd)
+}
+
+func runSetCanary(cmd *cobra.Command, args []string) error {
+	canary := &client.Canary{
+		Name:             args[0],
+		ToolName:         setCanaryCmdTool,
+		IntervalSeconds:  int(setCanaryCmdInterval.Seconds()),
+		FailureThreshold: setCanaryCmdFailureThreshold,
+		Enabled:          !setCanaryCmdDisabled,
+	}
+	if setCanaryCmdInput != "" {
+		if err := json.Unmarshal([]byte(setCanaryCmdInput), &canary.Input); err != nil {
+			return fmt.Errorf("invalid input: must be a JSON object: %w", err)
+		}
+	}
+	if setCanaryCmdExpect != "" {
+		if err := yaml.Unmarshal([]byte(setCanaryCmdExpect), &canary.Expect); err != nil {
+			return fmt.Errorf("invalid expect: %w", err)
+		}
+	}
+	if _, err := apiClient.SetCanary(canary); err != nil {
+		return fmt.Errorf("failed to set canary %s: %w", canary.Name, err)
+	}
+	fmt.Printf("Canary %s calls %s every %s\n", canary.Name, canary.ToolName, setCanaryCmdInterval)
+	return nil
+}
+
+func runListCanaries(cmd *cobra.Command, args []string) error {
+	canaries, err := apiClient.ListCanaries()
+	if err != nil {
+		return fmt.Errorf("failed to list canaries: %w", err)
+	}
+	if len(canaries) == 0 {
+		fmt.Println("There are no canaries in the registry")
+		return nil
+	}
+	for i, c := range canaries {
+		status := "enabled"
+		if !c.Enabled {
+			status = "disabled"
+		}
+		fmt.Printf("%d. %s [%s, every %s]\n", i+1, c.Name, status, time.Duration(c.IntervalSeconds)*time.Second)
+		fmt.Printf("tool: %s\n", c.ToolName)
+		switch {
+		case c.LastPassed == nil:
+			fmt.Println("not run yet")
+		case *c.LastPassed:
+			fmt.Printf("passed at %s (latency %dms)\n", c.LastRunAt.Local().Format(time.DateTime), c.LastLatencyMs)
+		default:
+			fmt.Printf("failed %d time(s) in a row, last at %s (latency %dms): %s\n",
+				c.ConsecutiveFailures, c.LastRunAt.Local().Format(time.DateTime), c.LastLatencyMs, c.LastError)
+		}
+		if i < len(canaries)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeleteCanary(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteCanary(args[0]); err != nil {
+		return fmt.Errorf("failed to delete canary %s: %w", args[0], err)
+	}
+	fmt.Printf("Canary %s deleted\n", args[0])
+	return nil
+}
+
+func runCanaryRuns(cmd *cobra.Command, args []string) error {
+	runs, err := apiClient.ListCanaryRuns(args[0], canaryRunsCmdLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get the runs of canary %s: %w", args[0], err)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("Canary %s has not run yet\n", args[0])
+		return nil
+	}
+	for _, r := range runs {
+		fmt.Printf("%s  %6dms  %s\n", r.RanAt.Local().Format(time.DateTime), r.LatencyMs, formatCanaryOutcome(r))
+	}
+	return nil
+}
+
+func runRunCanary(cmd *cobra.Command, args []string) error {
+	run, err := apiClient.RunCanary(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to run canary %s: %w", args[0], err)
+	}
+	fmt.Printf("%s (latency %dms)\n", formatCanaryOutcome(run), run.LatencyMs)
+	if !run.Passed {
+		return fmt.Errorf("canary %s failed", args[0])
+	}
+	return nil
+}
+
+func formatCanaryOutcome(r *client.CanaryRun) string {
+	if r.Passed {
+		return "passed"
+	}
+	return "failed: " + r.Error
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var cassetteCmd = &cobra.Command{
+	Use:   "cassette",
+	Short: "Record the tool calls made to MCP servers and replay them without the servers (eg- in CI)",
+	Long: "In record mode, the tools of a server and the tool calls made to it are recorded to a cassette file.\n" +
+		"In replay mode, the registry serves the recorded tools and tool calls without contacting the server.\n" +
+		"Calls are matched on the tool name and arguments, regardless of the order of the arguments.\n" +
+		"Cassette paths are relative to the cassette directory of the registry (env var CASSETTE_DIR of the start command).",
+}
+
+var recordCassetteCmdFile string
+
+var recordCassetteCmd = &cobra.Command{
+	Use:   "record <server>",
+	Short: "Record the tools of a server and the tool calls made to it",
+	Long:  "Start a new cassette with the tools of the server and record every tool call made to it, overwriting the file.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRecordCassette,
+}
+
+var (
+	replayCassetteCmdFile    string
+	replayCassetteCmdLenient bool
+)
+
+var replayCassetteCmd = &cobra.Command{
+	Use:   "replay <server>",
+	Short: "Serve the tools and tool calls of a server from its cassette",
+	Long: "Serve the tools and tool calls of a server from its cassette, registering the server if it isn't registered.\n" +
+		"With strict matching (the default), every recorded call is replayed once and calls without a recording fail.\n" +
+		"With lenient matching, recorded calls are reused and a call without a recording is served\n" +
+		"with a call of the same tool recorded with other arguments.",
+	Args: cobra.ExactArgs(1),
+	RunE: runReplayCassette,
+}
+
+var stopCassetteCmd = &cobra.Command{
+	Use:   "stop <server>",
+	Short: "Stop recording or replaying a server, its tool calls are forwarded to it again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStopCassette,
+}
+
+var cassetteStatusCmdFailOnUnmatched bool
+
+var cassetteStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report the cassettes in use and the calls that they had no recording of",
+	RunE:  runCassetteStatus,
+}
+
+func init() {
+	recordCassetteCmd.Flags().StringVarP(&recordCassetteCmdFile, "file", "f", "", "Path of the cassette file")
+	_ = recordCassetteCmd.MarkFlagRequired("file")
+
+	replayCassetteCmd.Flags().StringVarP(&replayCassetteCmdFile, "file", "f", "", "Path of the cassette file")
+	replayCassetteCmd.Flags().BoolVar(&replayCassetteCmdLenient, "lenient", false, "Use lenient matching")
+	_ = replayCassetteCmd.MarkFlagRequired("file")
+
+	cassetteStatusCmd.Flags().BoolVar(
+		&cassetteStatusCmdFailOnUnmatched,
+		"fail-on-unmatched",
+		false,
+		"Exit with an error if any call had no recording, eg- to fail a CI job",
+	)
+
+	cassetteCmd.AddCommand(recordCassetteCmd)
+	cassetteCmd.AddCommand(replayCassetteCmd)
+	cassetteCmd.AddCommand(stopCassetteCmd)
+	cassetteCmd.AddCommand(cassetteStatusCmd)
+	rootCmd.AddCommand(cassetteCmd)
+}
+
+func runRecordCassette(cmd *cobra.Command, args []string) error {
+	_, err := apiClient.SetCassette(&client.Cassette{ServerName: args[0], Mode: "record", Path: recordCassetteCmdFile})
+	if err != nil {
+		return fmt.Errorf("failed to start recording %s: %w", args[0], err)
+	}
+	fmt.Printf("Recording the tool calls made to %s to %s\n", args[0], recordCassetteCmdFile)
+	return nil
+}
+
+func runReplayCassette(cmd *cobra.Command, args []string) error {
+	match := "strict"
+	if replayCassetteCmdLenient {
+		match = "lenient"
+	}
+	_, err := apiClient.SetCassette(&client.Cassette{
+		ServerName: args[0],
+		Mode:       "replay",
+		Path:       replayCassetteCmdFile,
+		Match:      match,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start replaying %s: %w", args[0], err)
+	}
+	fmt.Printf("Serving the tool calls made to %s from %s (%s matching)\n", args[0], replayCassetteCmdFile, match)
+	return nil
+}
+
+func runStopCassette(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteCassette(args[0]); err != nil {
+		return fmt.Errorf("failed to stop the cassette of %s: %w", args[0], err)
+	}
+	fmt.Printf("Tool calls made to %s are forwarded to it again\n", args[0])
+	return nil
+}
+
+func runCassetteStatus(cmd *cobra.Command, args []string) error {
+	cassettes, err := apiClient.ListCassettes()
+	if err != nil {
+		return fmt.Errorf("failed to list cassettes: %w", err)
+	}
+	if len(cassettes) == 0 {
+		fmt.Println("No server is in record or replay mode")
+		return nil
+	}
+
+	unmatched := 0
+	for i, c := range cassettes {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s: %s %s\n", c.ServerName, c.Mode, c.Path)
+		if c.Error != "" {
+			fmt.Printf("  failed to load the cassette: %s\n", c.Error)
+		}
+		if c.Mode == "record" {
+			fmt.Printf("  %d call(s) recorded\n", c.RecordedCalls)
+			continue
+		}
+		fmt.Printf("  %s matching, %d of %d recorded call(s) replayed, %d call(s) unmatched\n",
+			c.Match, c.ReplayedCalls, c.RecordedCalls, c.UnmatchedCount)
+		for _, u := range c.Unmatched {
+			note := ""
+			if u.Fallback {
+				note = " (served with a call recorded with other arguments)"
+			}
+			fmt.Printf("  - %s %s%s\n", u.Tool, u.Arguments, note)
+		}
+		if more := c.UnmatchedCount - len(c.Unmatched); more > 0 {
+			fmt.Printf("  ... and %d more\n", more)
+		}
+		unmatched += c.UnmatchedCount
+	}
+	if cassetteStatusCmdFailOnUnmatched && unmatched > 0 {
+		return fmt.Errorf("%d call(s) had no recording in their cassette", unmatched)
+	}
+	return nil
+}
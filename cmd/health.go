@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var healthCmdLimit int
+
+var healthCmd = &cobra.Command{
+	Use:   "health [server]",
+	Short: "Show the health of MCP servers",
+	Long: "Show the health of all registered MCP servers, as determined by the registry's periodic health checks.\n" +
+		"If a server is given, also show its most recent health checks.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHealth,
+}
+
+func init() {
+	healthCmd.Flags().IntVar(
+		&healthCmdLimit,
+		"limit",
+		20,
+		"Number of recent health checks to show for the server",
+	)
+	rootCmd.AddCommand(healthCmd)
+}
+
+func runHealth(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return runServerHealthHistory(args[0])
+	}
+
+	health, err := apiClient.ListServerHealth()
+	if err != nil {
+		return fmt.Errorf("failed to get the health of servers: %w", err)
+	}
+	if len(health) == 0 {
+		fmt.Println("There are no MCP servers in the registry")
+		return nil
+	}
+	for i, h := range health {
+		fmt.Printf("%d. %s\n", i+1, h.ServerName)
+		fmt.Println(formatServerHealth(h))
+		if i < len(health)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runServerHealthHistory(name string) error {
+	history, err := apiClient.GetServerHealthHistory(name, healthCmdLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get the health of server %s: %w", name, err)
+	}
+	fmt.Println(formatServerHealth(&history.ServerHealth))
+	if len(history.History) == 0 {
+		return nil
+	}
+	fmt.Println()
+	for _, check := range history.History {
+		outcome := "healthy"
+		if !check.Healthy {
+			outcome = "unhealthy: " + check.Error
+		}
+		fmt.Printf("%s  %6dms  %s\n", check.CheckedAt.Local().Format(time.DateTime), check.LatencyMs, outcome)
+	}
+	return nil
+}
+
+// formatServerHealth describes the health of a server in a single line.
+func formatServerHealth(h *client.ServerHealth) string {
+	var uptime string
+	if h.Uptime != nil {
+		uptime = fmt.Sprintf(", uptime %.1f%% over 24h", *h.Uptime*100)
+	}
+	switch h.Status {
+	case "healthy":
+		return fmt.Sprintf("status: healthy (latency %dms%s)", h.LatencyMs, uptime)
+	case "degraded":
+		return fmt.Sprintf("status: degraded, failing canaries: %s (latency %dms%s)",
+			strings.Join(h.FailingCanaries, ", "), h.LatencyMs, uptime)
+	case "unhealthy":
+		return fmt.Sprintf("status: unhealthy for %d checks%s: %s", h.ConsecutiveFailures, uptime, h.LastError)
+	default:
+		return "status: " + h.Status
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var limitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Manage rate and concurrency limits of upstream MCP servers and tools",
+	Long: "Limits protect upstream MCP servers from being overloaded by capping the number of concurrent\n" +
+		"in-flight calls and the rate of calls (token bucket). A call must fit within the limits of both\n" +
+		"its tool and its server. Calls that don't fit are either rejected immediately or queued.",
+}
+
+var (
+	setLimitCmdMaxConcurrent int
+	setLimitCmdRate          float64
+	setLimitCmdBurst         int
+	setLimitCmdQueue         bool
+	setLimitCmdQueueTimeout  time.Duration
+)
+
+var setLimitCmd = &cobra.Command{
+	Use:   "set <server name | canonical tool name>",
+	Short: "Set the limit of a server or tool",
+	Long: "Set the limit of a server (eg- github) or a tool (eg- github/create_issue), replacing its previous limit.\n" +
+		"By default, calls that exceed the limit are rejected. With --queue, they wait up to --queue-timeout instead.",
+	Args: cobra.ExactArgs(1),
+	RunE: runSetLimit,
+}
+
+var listLimitsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List limits and how many calls they queued and rejected",
+	RunE:  runListLimits,
+}
+
+var deleteLimitCmd = &cobra.Command{
+	Use:   "delete <server name | canonical tool name>",
+	Short: "Delete the limit of a server or tool",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteLimit,
+}
+
+func init() {
+	setLimitCmd.Flags().IntVar(&setLimitCmdMaxConcurrent, "max-concurrent", 0, "Maximum number of in-flight calls")
+	setLimitCmd.Flags().Float64Var(&setLimitCmdRate, "rps", 0, "Maximum sustained requests per second")
+	setLimitCmd.Flags().IntVar(
+		&setLimitCmdBurst,
+		"burst",
+		0,
+		"Maximum number of requests allowed at once after a quiet period (defaults to --rps rounded up)",
+	)
+	setLimitCmd.Flags().BoolVar(&setLimitCmdQueue, "queue", false, "Queue calls that exceed the limit instead of rejecting them")
+	setLimitCmd.Flags().DurationVar(
+		&setLimitCmdQueueTimeout,
+		"queue-timeout",
+		30*time.Second,
+		"Maximum time a queued call waits before being rejected",
+	)
+
+	limitsCmd.AddCommand(setLimitCmd)
+	limitsCmd.AddCommand(listLimitsCmd)
+	limitsCmd.AddCommand(deleteLimitCmd)
+	rootCmd.AddCommand(limitsCmd)
+}
+
+// callScope determines whether the argument names a server or a tool.
+func callScope(name string) string {
+	if strings.Contains(name, "/") {
+		return "tool"
+	}
+	return "server"
+}
+
+func runSetLimit(cmd *cobra.Command, args []string) error {
+	limit := &client.CallLimit{
+		ScopeType:     callScope(args[0]),
+		ScopeValue:    args[0],
+		MaxConcurrent: setLimitCmdMaxConcurrent,
+		RatePerSecond: setLimitCmdRate,
+		Burst:         setLimitCmdBurst,
+		Mode:          "reject",
+	}
+	if setLimitCmdQueue {
+		limit.Mode = "queue"
+		limit.QueueTimeoutMs = int(setLimitCmdQueueTimeout.Milliseconds())
+	}
+
+	if err := apiClient.SetCallLimit(limit); err != nil {
+		return fmt.Errorf("failed to set the limit of %s: %w", args[0], err)
+	}
+	fmt.Printf("Limit of %s %s set successfully!\n", limit.ScopeType, args[0])
+	return nil
+}
+
+func runListLimits(cmd *cobra.Command, args []string) error {
+	limits, err := apiClient.ListCallLimits()
+	if err != nil {
+		return fmt.Errorf("failed to list limits: %w", err)
+	}
+
+	if len(limits) == 0 {
+		fmt.Println("There are no limits in the registry")
+		return nil
+	}
+	for i, l := range limits {
+		var settings []string
+		if l.MaxConcurrent > 0 {
+			settings = append(settings, fmt.Sprintf("max concurrent: %d", l.MaxConcurrent))
+		}
+		if l.RatePerSecond > 0 {
+			settings = append(settings, fmt.Sprintf("rate: %g/s (burst %d)", l.RatePerSecond, l.Burst))
+		}
+		mode := l.Mode
+		if l.Mode == "queue" {
+			mode = fmt.Sprintf("queue up to %s", time.Duration(l.QueueTimeoutMs)*time.Millisecond)
+		}
+		settings = append(settings, mode)
+
+		fmt.Printf("%d. %s %s\n", i+1, l.ScopeType, l.ScopeValue)
+		fmt.Println(strings.Join(settings, ", "))
+		fmt.Printf(
+			"allowed: %d, queued: %d, rejected by rate: %d, rejected by concurrency: %d, in flight: %d\n",
+			l.Stats.Allowed, l.Stats.Queued, l.Stats.RejectedRate, l.Stats.RejectedConcurrency, l.Stats.InFlight,
+		)
+		if i < len(limits)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeleteLimit(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteCallLimit(callScope(args[0]), args[0]); err != nil {
+		return fmt.Errorf("failed to delete the limit of %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted the limit of %s\n", args[0])
+	return nil
+}
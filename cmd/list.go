@@ -71,8 +71,23 @@ func runListServers(cmd *cobra.Command, args []string) error {
 	}
 	for i, s := range servers {
 		fmt.Printf("%d. %s\n", i+1, s.Name)
-		fmt.Println(s.URL)
+		if len(s.EndpointStatuses) == 0 {
+			fmt.Println(s.URL)
+		}
+		for _, e := range s.EndpointStatuses {
+			health := "healthy"
+			if !e.Healthy {
+				health = "unhealthy: " + e.LastError
+			}
+			fmt.Printf("%s (weight %d, %d in flight, %s)\n", e.URL, e.Weight, e.InFlight, health)
+		}
 		fmt.Println(s.Description)
+		if s.Health != nil {
+			fmt.Println(formatServerHealth(s.Health))
+		}
+		if b := s.CircuitBreaker; b != nil && b.State != "closed" {
+			fmt.Printf("circuit breaker: %s after %d consecutive failures\n", b.State, b.ConsecutiveFailures)
+		}
 		if i < len(servers)-1 {
 			fmt.Println()
 		}
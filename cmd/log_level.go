@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var logLevelCmd = &cobra.Command{
+	Use:   "log-level [debug|info|warn|error]",
+	Short: "Show or change the log level of the server",
+	Long: "Without arguments, show the current log level of the server.\n" +
+		"With a level, change it right away, without restarting the server. The change lasts until the server restarts.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLogLevel,
+}
+
+func init() {
+	rootCmd.AddCommand(logLevelCmd)
+}
+
+func runLogLevel(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		level, err := apiClient.GetLogLevel()
+		if err != nil {
+			return fmt.Errorf("failed to get the log level: %w", err)
+		}
+		fmt.Printf("Log level: %s\n", level)
+		return nil
+	}
+
+	level, err := apiClient.SetLogLevel(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to set the log level: %w", err)
+	}
+	fmt.Printf("Log level set to %s\n", level)
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/mock"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/spf13/cobra"
+)
+
+const mockServerPortDefault = "8090"
+
+var (
+	mockCmdConfig string
+	mockCmdPort   string
+)
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Start a mock MCP server whose tools and responses are declared in YAML",
+	Long: "Start a streamable HTTP MCP server at /mcp whose tools, input schemas and responses are declared in YAML,\n" +
+		"eg- to develop and test MCP clients without the real servers.\n" +
+		"A response can be text, JSON or image content, a tool error or a protocol error, after an optional delay.\n" +
+		"Responses are Go templates executed with the arguments of the call (eg- \"Sunny in {{.city}}\"),\n" +
+		"and a response with `when` only matches calls with the listed argument values.\n" +
+		"The same file can be registered as a mock server served by the registry itself with `register --mock`.",
+	RunE: runMock,
+}
+
+func init() {
+	mockCmd.Flags().StringVar(&mockCmdConfig, "config", "", "Path of the YAML file declaring the mock server")
+	mockCmd.Flags().StringVar(&mockCmdPort, "port", mockServerPortDefault, "Port to bind the mock server to")
+	_ = mockCmd.MarkFlagRequired("config")
+
+	rootCmd.AddCommand(mockCmd)
+}
+
+func runMock(cmd *cobra.Command, args []string) error {
+	cfg, err := mock.Load(mockCmdConfig)
+	if err != nil {
+		return err
+	}
+	s := server.NewStreamableHTTPServer(mock.NewServer(cfg))
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("mock MCP server listening",
+		slog.String("port", mockCmdPort), slog.String("path", "/mcp"), slog.Int("tools", len(cfg.Tools)))
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- s.Start(":" + mockCmdPort)
+	}()
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to run the mock server: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("failed to shut down the mock server gracefully", logging.Err(err))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var alertChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Manage the channels that alerts are delivered to",
+	Long: "Notification channels receive an event whenever an alert is raised or resolved.\n" +
+		"Failed deliveries are retried with exponential backoff.",
+}
+
+var (
+	createChannelCmdType         string
+	createChannelCmdMinSeverity  string
+	createChannelCmdURL          string
+	createChannelCmdSecret       string
+	createChannelCmdSMTPHost     string
+	createChannelCmdSMTPPort     int
+	createChannelCmdSMTPUsername string
+	createChannelCmdSMTPPassword string
+	createChannelCmdEmailFrom    string
+	createChannelCmdEmailTo      string
+)
+
+var createChannelCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a notification channel",
+	Long: "Create a notification channel. Supported types:\n" +
+		"  webhook  POSTs a JSON payload to --url. If --secret is given, the payload is signed with HMAC-SHA256\n" +
+		"           and the signature is sent in the X-MCPJungle-Signature header as sha256=<hex digest>\n" +
+		"  slack    POSTs a message to the Slack incoming webhook given by --url\n" +
+		"  email    sends an email to --to through the SMTP server given by --smtp-host",
+	Args: cobra.ExactArgs(1),
+	RunE: runCreateChannel,
+}
+
+var listChannelsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notification channels",
+	RunE:  runListChannels,
+}
+
+var deleteChannelCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a notification channel",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteChannel,
+}
+
+var testChannelCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Send a test notification to a channel",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTestChannel,
+}
+
+func init() {
+	createChannelCmd.Flags().StringVar(&createChannelCmdType, "type", "", "Channel type: webhook, slack or email")
+	createChannelCmd.Flags().StringVar(
+		&createChannelCmdMinSeverity,
+		"min-severity",
+		"low",
+		"Only deliver alerts of at least this severity: low, medium, high or critical",
+	)
+	createChannelCmd.Flags().StringVar(&createChannelCmdURL, "url", "", "URL to POST to (webhook, slack)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdSecret, "secret", "", "Secret used to sign the payload (webhook)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdSMTPHost, "smtp-host", "", "SMTP server host (email)")
+	createChannelCmd.Flags().IntVar(&createChannelCmdSMTPPort, "smtp-port", 25, "SMTP server port (email)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdSMTPUsername, "smtp-username", "", "SMTP username (email)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdSMTPPassword, "smtp-password", "", "SMTP password (email)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdEmailFrom, "from", "", "Sender address (email)")
+	createChannelCmd.Flags().StringVar(&createChannelCmdEmailTo, "to", "", "Comma-separated recipient addresses (email)")
+	_ = createChannelCmd.MarkFlagRequired("type")
+
+	alertChannelsCmd.AddCommand(createChannelCmd)
+	alertChannelsCmd.AddCommand(listChannelsCmd)
+	alertChannelsCmd.AddCommand(deleteChannelCmd)
+	alertChannelsCmd.AddCommand(testChannelCmd)
+	alertsCmd.AddCommand(alertChannelsCmd)
+}
+
+func runCreateChannel(cmd *cobra.Command, args []string) error {
+	channel := &client.NotificationChannel{
+		Name:         args[0],
+		Type:         createChannelCmdType,
+		MinSeverity:  createChannelCmdMinSeverity,
+		URL:          createChannelCmdURL,
+		Secret:       createChannelCmdSecret,
+		SMTPHost:     createChannelCmdSMTPHost,
+		SMTPPort:     createChannelCmdSMTPPort,
+		SMTPUsername: createChannelCmdSMTPUsername,
+		SMTPPassword: createChannelCmdSMTPPassword,
+		EmailFrom:    createChannelCmdEmailFrom,
+		EmailTo:      createChannelCmdEmailTo,
+	}
+	if err := apiClient.CreateNotificationChannel(channel); err != nil {
+		return fmt.Errorf("failed to create notification channel: %w", err)
+	}
+	fmt.Printf("Notification channel %s created successfully!\n", channel.Name)
+	return nil
+}
+
+func runListChannels(cmd *cobra.Command, args []string) error {
+	channels, err := apiClient.ListNotificationChannels()
+	if err != nil {
+		return fmt.Errorf("failed to list notification channels: %w", err)
+	}
+
+	if len(channels) == 0 {
+		fmt.Println("There are no notification channels in the registry")
+		return nil
+	}
+	for i, c := range channels {
+		destination := c.URL
+		if c.Type == "email" {
+			destination = c.EmailTo
+		}
+		fmt.Printf("%d. %s [%s, min severity: %s]\n", i+1, c.Name, c.Type, c.MinSeverity)
+		fmt.Println(destination)
+		if i < len(channels)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeleteChannel(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteNotificationChannel(args[0]); err != nil {
+		return fmt.Errorf("failed to delete notification channel %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted notification channel %s\n", args[0])
+	return nil
+}
+
+func runTestChannel(cmd *cobra.Command, args []string) error {
+	if err := apiClient.TestNotificationChannel(args[0]); err != nil {
+		return fmt.Errorf("failed to send test notification to channel %s: %w", args[0], err)
+	}
+	fmt.Printf("Test notification delivered to channel %s\n", args[0])
+	return nil
+}
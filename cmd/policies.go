@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var policiesCmd = &cobra.Command{
+	Use:   "policies",
+	Short: "Manage how the registry calls upstream MCP servers and tools",
+	Long: "Policies configure the timeouts and retries of calls to an upstream MCP server or a single tool,\n" +
+		"the circuit breaker that makes calls to an unhealthy server fail fast,\n" +
+		"and whether the arguments of calls are validated against the input schemas of tools.\n" +
+		"A tool's policy overrides its server's policy, which overrides the registry defaults.",
+}
+
+var (
+	setPolicyCmdConnectTimeout   time.Duration
+	setPolicyCmdCallTimeout      time.Duration
+	setPolicyCmdBreakerThreshold int
+	setPolicyCmdBreakerCooldown  time.Duration
+	setPolicyCmdRetryMaxAttempts int
+	setPolicyCmdRetryBackoff     time.Duration
+	setPolicyCmdRetryMaxBackoff  time.Duration
+	setPolicyCmdRetryOn          string
+	setPolicyCmdRetryUnsafe      bool
+	setPolicyCmdValidateArgs     bool
+)
+
+var setPolicyCmd = &cobra.Command{
+	Use:   "set <server name | canonical tool name>",
+	Short: "Set the policy of a server or tool",
+	Long: "Set the policy of a server (eg- github) or a tool (eg- github/create_issue).\n" +
+		"Only the settings passed as flags are changed, the rest of an existing policy is left untouched.\n" +
+		"A timeout of 0 disables the timeout.",
+	Args: cobra.ExactArgs(1),
+	RunE: runSetPolicy,
+}
+
+var listPoliciesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List policies",
+	RunE:  runListPolicies,
+}
+
+var deletePolicyCmd = &cobra.Command{
+	Use:   "delete <server name | canonical tool name>",
+	Short: "Delete the policy of a server or tool, so that the defaults apply again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeletePolicy,
+}
+
+func init() {
+	setPolicyCmd.Flags().DurationVar(
+		&setPolicyCmdConnectTimeout,
+		"connect-timeout",
+		0,
+		"Maximum time to connect to the server and initialize the MCP session (default 10s)",
+	)
+	setPolicyCmd.Flags().DurationVar(
+		&setPolicyCmdCallTimeout,
+		"call-timeout",
+		0,
+		"Maximum time a tool call may take once connected (default 1m)",
+	)
+	setPolicyCmd.Flags().IntVar(
+		&setPolicyCmdBreakerThreshold,
+		"breaker-threshold",
+		0,
+		"Number of consecutive failed calls that opens the server's circuit breaker, 0 disables the breaker (default 5)",
+	)
+	setPolicyCmd.Flags().DurationVar(
+		&setPolicyCmdBreakerCooldown,
+		"breaker-cooldown",
+		0,
+		"How long an open circuit breaker fails calls fast before letting a trial call through (default 30s)",
+	)
+	setPolicyCmd.Flags().IntVar(
+		&setPolicyCmdRetryMaxAttempts,
+		"retry-max-attempts",
+		0,
+		"Maximum number of attempts of a call including retries, 1 disables retries (default 3)",
+	)
+	setPolicyCmd.Flags().DurationVar(
+		&setPolicyCmdRetryBackoff,
+		"retry-backoff",
+		0,
+		"Backoff before the first retry, doubled with every retry (default 200ms)",
+	)
+	setPolicyCmd.Flags().DurationVar(&setPolicyCmdRetryMaxBackoff, "retry-max-backoff", 0, "Maximum backoff between retries (default 5s)")
+	setPolicyCmd.Flags().StringVar(
+		&setPolicyCmdRetryOn,
+		"retry-on",
+		"",
+		"Comma-separated classes of errors to retry, out of connection, upstream, timeout (default connection,upstream)",
+	)
+	setPolicyCmd.Flags().BoolVar(
+		&setPolicyCmdRetryUnsafe,
+		"retry-non-idempotent",
+		false,
+		"Also retry tools that don't declare the readOnlyHint or idempotentHint annotations",
+	)
+	setPolicyCmd.Flags().BoolVar(
+		&setPolicyCmdValidateArgs,
+		"validate-arguments",
+		true,
+		"Validate the arguments of calls against the tool's input schema, pass false for servers with sloppy schemas",
+	)
+
+	policiesCmd.AddCommand(setPolicyCmd)
+	policiesCmd.AddCommand(listPoliciesCmd)
+	policiesCmd.AddCommand(deletePolicyCmd)
+	rootCmd.AddCommand(policiesCmd)
+}
+
+// durationFlagMs returns the value of a duration flag in milliseconds, or nil if the flag was not passed.
+func durationFlagMs(cmd *cobra.Command, name string, value time.Duration) *int {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	ms := int(value.Milliseconds())
+	return &ms
+}
+
+// formatPolicyTimeout describes a timeout setting of a policy.
+func formatPolicyTimeout(ms *int) string {
+	switch {
+	case ms == nil:
+		return "inherited"
+	case *ms == 0:
+		return "none"
+	default:
+		return (time.Duration(*ms) * time.Millisecond).String()
+	}
+}
+
+func runSetPolicy(cmd *cobra.Command, args []string) error {
+	policy := &client.CallPolicy{
+		ScopeType:         callScope(args[0]),
+		ScopeValue:        args[0],
+		ConnectTimeoutMs:  durationFlagMs(cmd, "connect-timeout", setPolicyCmdConnectTimeout),
+		CallTimeoutMs:     durationFlagMs(cmd, "call-timeout", setPolicyCmdCallTimeout),
+		BreakerCooldownMs: durationFlagMs(cmd, "breaker-cooldown", setPolicyCmdBreakerCooldown),
+		RetryBackoffMs:    durationFlagMs(cmd, "retry-backoff", setPolicyCmdRetryBackoff),
+		RetryMaxBackoffMs: durationFlagMs(cmd, "retry-max-backoff", setPolicyCmdRetryMaxBackoff),
+	}
+	if cmd.Flags().Changed("breaker-threshold") {
+		policy.BreakerFailureThreshold = &setPolicyCmdBreakerThreshold
+	}
+	if cmd.Flags().Changed("retry-max-attempts") {
+		policy.RetryMaxAttempts = &setPolicyCmdRetryMaxAttempts
+	}
+	if cmd.Flags().Changed("retry-on") {
+		policy.RetryOn = &setPolicyCmdRetryOn
+	}
+	if cmd.Flags().Changed("retry-non-idempotent") {
+		policy.RetryNonIdempotent = &setPolicyCmdRetryUnsafe
+	}
+	if cmd.Flags().Changed("validate-arguments") {
+		policy.ValidateArguments = &setPolicyCmdValidateArgs
+	}
+	if *policy == (client.CallPolicy{ScopeType: policy.ScopeType, ScopeValue: policy.ScopeValue}) {
+		return errors.New("at least one setting must be passed, see --help")
+	}
+
+	if _, err := apiClient.SetCallPolicy(policy); err != nil {
+		return fmt.Errorf("failed to set the policy of %s: %w", args[0], err)
+	}
+	fmt.Printf("Policy of %s %s set successfully!\n", policy.ScopeType, args[0])
+	return nil
+}
+
+func runListPolicies(cmd *cobra.Command, args []string) error {
+	policies, err := apiClient.ListCallPolicies()
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	if len(policies) == 0 {
+		fmt.Println("There are no policies in the registry")
+		return nil
+	}
+	for i, p := range policies {
+		fmt.Printf("%d. %s %s\n", i+1, p.ScopeType, p.ScopeValue)
+		settings := []string{
+			"connect timeout: " + formatPolicyTimeout(p.ConnectTimeoutMs),
+			"call timeout: " + formatPolicyTimeout(p.CallTimeoutMs),
+		}
+		if p.ScopeType == "server" {
+			threshold := "inherited"
+			if p.BreakerFailureThreshold != nil {
+				threshold = strconv.Itoa(*p.BreakerFailureThreshold)
+			}
+			settings = append(
+				settings,
+				"breaker threshold: "+threshold,
+				"breaker cooldown: "+formatPolicyTimeout(p.BreakerCooldownMs),
+			)
+		}
+		retries := "retries: inherited"
+		if p.RetryMaxAttempts != nil {
+			retries = fmt.Sprintf("retries: up to %d attempts", *p.RetryMaxAttempts)
+		}
+		if p.RetryOn != nil {
+			retries += " on " + *p.RetryOn
+		}
+		if p.RetryBackoffMs != nil || p.RetryMaxBackoffMs != nil {
+			retries += fmt.Sprintf(
+				", backoff %s up to %s", formatPolicyTimeout(p.RetryBackoffMs), formatPolicyTimeout(p.RetryMaxBackoffMs),
+			)
+		}
+		if p.RetryNonIdempotent != nil && *p.RetryNonIdempotent {
+			retries += ", including non-idempotent tools"
+		}
+		settings = append(settings, retries)
+		if p.ValidateArguments != nil {
+			validation := "enabled"
+			if !*p.ValidateArguments {
+				validation = "disabled"
+			}
+			settings = append(settings, "argument validation: "+validation)
+		}
+		fmt.Println(strings.Join(settings, ", "))
+		if i < len(policies)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeletePolicy(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteCallPolicy(callScope(args[0]), args[0]); err != nil {
+		return fmt.Errorf("failed to delete the policy of %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted the policy of %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var pricingCmd = &cobra.Command{
+	Use:   "pricing",
+	Short: "Manage the model pricing catalog",
+	Long: "The pricing catalog is used to compute the cost of usage reported without one.\n" +
+		"Prices are in USD per million tokens. A price applies from its effective date until the model's next price.",
+}
+
+var (
+	setPriceCmdInput         float64
+	setPriceCmdOutput        float64
+	setPriceCmdCacheRead     float64
+	setPriceCmdCacheWrite    float64
+	setPriceCmdEffectiveFrom string
+)
+
+var setPriceCmd = &cobra.Command{
+	Use:   "set <model>",
+	Short: "Set the price of a model",
+	Long: "Set the price of a model from the given effective date.\n" +
+		"If the model already has a price with the same effective date, it is replaced.",
+	Args: cobra.ExactArgs(1),
+	RunE: runSetPrice,
+}
+
+var listPricesCmdModel string
+
+var listPricesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the pricing catalog",
+	RunE:  runListPrices,
+}
+
+var deletePriceCmdEffectiveFrom string
+
+var deletePriceCmd = &cobra.Command{
+	Use:   "delete <model>",
+	Short: "Delete the price of a model",
+	Long:  "Delete the price of a model with the given effective date, or all of its prices if --effective-from is not given.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeletePrice,
+}
+
+var (
+	recomputeCmdFrom  string
+	recomputeCmdTo    string
+	recomputeCmdMonth string
+	recomputeCmdModel string
+)
+
+var recomputeCostsCmd = &cobra.Command{
+	Use:   "recompute",
+	Short: "Recompute historical costs from the pricing catalog",
+	Long: "Recompute the cost of all usage in a time window from the pricing catalog, overwriting the costs sent by clients.\n" +
+		"Usage of models that have no price in the catalog is left untouched.\n" +
+		"Specify the window either with --month or with --from and --to.",
+	RunE: runRecomputeCosts,
+}
+
+func init() {
+	setPriceCmd.Flags().Float64Var(&setPriceCmdInput, "input", 0, "Price of input tokens")
+	setPriceCmd.Flags().Float64Var(&setPriceCmdOutput, "output", 0, "Price of output tokens")
+	setPriceCmd.Flags().Float64Var(&setPriceCmdCacheRead, "cache-read", 0, "Price of prompt cache read tokens")
+	setPriceCmd.Flags().Float64Var(&setPriceCmdCacheWrite, "cache-write", 0, "Price of prompt cache write tokens")
+	setPriceCmd.Flags().StringVar(
+		&setPriceCmdEffectiveFrom,
+		"effective-from",
+		"",
+		"Date (YYYY-MM-DD or RFC3339 timestamp) from which the price applies. If omitted, it applies to all past usage.",
+	)
+	_ = setPriceCmd.MarkFlagRequired("input")
+	_ = setPriceCmd.MarkFlagRequired("output")
+
+	listPricesCmd.Flags().StringVar(&listPricesCmdModel, "model", "", "Only list the prices of this model")
+
+	deletePriceCmd.Flags().StringVar(
+		&deletePriceCmdEffectiveFrom,
+		"effective-from",
+		"",
+		"Effective date of the price to delete",
+	)
+
+	recomputeCostsCmd.Flags().StringVar(
+		&recomputeCmdFrom,
+		"from",
+		"",
+		"Start of the window (YYYY-MM-DD or RFC3339 timestamp), inclusive",
+	)
+	recomputeCostsCmd.Flags().StringVar(
+		&recomputeCmdTo,
+		"to",
+		"",
+		"End of the window (YYYY-MM-DD or RFC3339 timestamp). A date includes the whole day.",
+	)
+	recomputeCostsCmd.Flags().StringVar(&recomputeCmdMonth, "month", "", "Recompute a whole calendar month (YYYY-MM)")
+	recomputeCostsCmd.Flags().StringVar(&recomputeCmdModel, "model", "", "Only recompute the usage of this model")
+	recomputeCostsCmd.MarkFlagsMutuallyExclusive("month", "from")
+	recomputeCostsCmd.MarkFlagsMutuallyExclusive("month", "to")
+	recomputeCostsCmd.MarkFlagsRequiredTogether("from", "to")
+	recomputeCostsCmd.MarkFlagsOneRequired("month", "from")
+
+	pricingCmd.AddCommand(setPriceCmd)
+	pricingCmd.AddCommand(listPricesCmd)
+	pricingCmd.AddCommand(deletePriceCmd)
+	pricingCmd.AddCommand(recomputeCostsCmd)
+	rootCmd.AddCommand(pricingCmd)
+}
+
+func runSetPrice(cmd *cobra.Command, args []string) error {
+	price := &client.ModelPrice{
+		Model:             args[0],
+		InputPerMTok:      setPriceCmdInput,
+		OutputPerMTok:     setPriceCmdOutput,
+		CacheReadPerMTok:  setPriceCmdCacheRead,
+		CacheWritePerMTok: setPriceCmdCacheWrite,
+		EffectiveFrom:     setPriceCmdEffectiveFrom,
+	}
+	if err := apiClient.SetModelPrice(price); err != nil {
+		return fmt.Errorf("failed to set price of model %s: %w", args[0], err)
+	}
+	fmt.Printf("Price of model %s set successfully!\n", args[0])
+	return nil
+}
+
+func runListPrices(cmd *cobra.Command, args []string) error {
+	prices, err := apiClient.ListModelPrices(listPricesCmdModel)
+	if err != nil {
+		return fmt.Errorf("failed to list prices: %w", err)
+	}
+
+	if len(prices) == 0 {
+		fmt.Println("The pricing catalog is empty")
+		return nil
+	}
+	for i, p := range prices {
+		effectiveFrom := "always"
+		if p.EffectiveFrom != "" {
+			effectiveFrom = "from " + p.EffectiveFrom
+		}
+		fmt.Printf("%d. %s (%s)\n", i+1, p.Model, effectiveFrom)
+		fmt.Printf(
+			"input: $%g, output: $%g, cache read: $%g, cache write: $%g per million tokens\n",
+			p.InputPerMTok, p.OutputPerMTok, p.CacheReadPerMTok, p.CacheWritePerMTok,
+		)
+		if i < len(prices)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeletePrice(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteModelPrice(args[0], deletePriceCmdEffectiveFrom); err != nil {
+		return fmt.Errorf("failed to delete price of model %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted price of model %s\n", args[0])
+	return nil
+}
+
+func runRecomputeCosts(cmd *cobra.Command, args []string) error {
+	from, to := recomputeCmdFrom, recomputeCmdTo
+	if recomputeCmdMonth != "" {
+		var err error
+		if from, to, err = monthWindow(recomputeCmdMonth); err != nil {
+			return err
+		}
+	}
+
+	result, err := apiClient.RecomputeCosts(from, to, recomputeCmdModel)
+	if err != nil {
+		return fmt.Errorf("failed to recompute costs: %w", err)
+	}
+	fmt.Printf("Recomputed the cost of %d usage records\n", result.Updated)
+	if result.Unpriced > 0 {
+		fmt.Printf("%d usage records were left untouched because their model has no price in the catalog\n", result.Unpriced)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var quotasCmd = &cobra.Command{
+	Use:   "quotas",
+	Short: "Manage budgets and quotas on tool calls",
+	Long: "Quotas cap the number of tool calls or the LLM cost in a period (UTC calendar minute, day or month).\n" +
+		"Once a quota is exhausted, tool calls within its scope are rejected until the period resets.",
+}
+
+var (
+	createQuotaCmdMetric   string
+	createQuotaCmdPeriod   string
+	createQuotaCmdLimit    float64
+	createQuotaCmdScope    string
+	createQuotaCmdDesc     string
+	createQuotaCmdDisabled bool
+)
+
+var createQuotaCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a quota",
+	Long: "Create a quota.\n" +
+		"Use --metric calls to limit the number of tool calls, or --metric cost to limit the LLM cost in USD.\n" +
+		"Without --scope, the quota applies to all tool calls. Otherwise it is scoped to one of:\n" +
+		"  api_key:<key>           calls made with an API key (sent as 'Authorization: Bearer <key>' or X-API-Key)\n" +
+		"  client:<type>           calls made by a type of client (sent in the X-MCPJungle-Client header)\n" +
+		"  server:<name>           calls to an MCP server\n" +
+		"  tool:<server>/<tool>    calls to a single tool",
+	Args: cobra.ExactArgs(1),
+	RunE: runCreateQuota,
+}
+
+var listQuotasCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List quotas and their current consumption",
+	RunE:  runListQuotas,
+}
+
+var deleteQuotaCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a quota",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteQuota,
+}
+
+func init() {
+	createQuotaCmd.Flags().StringVar(&createQuotaCmdMetric, "metric", "", "What to limit: calls or cost")
+	createQuotaCmd.Flags().StringVar(&createQuotaCmdPeriod, "period", "", "Period of the quota: minute, day or month")
+	createQuotaCmd.Flags().Float64Var(&createQuotaCmdLimit, "limit", 0, "Maximum number of calls or cost (USD) per period")
+	createQuotaCmd.Flags().StringVar(&createQuotaCmdScope, "scope", "", "Scope the quota, as <type>:<value>")
+	createQuotaCmd.Flags().StringVar(&createQuotaCmdDesc, "description", "", "Quota description")
+	createQuotaCmd.Flags().BoolVar(&createQuotaCmdDisabled, "disabled", false, "Create the quota disabled")
+	_ = createQuotaCmd.MarkFlagRequired("metric")
+	_ = createQuotaCmd.MarkFlagRequired("period")
+	_ = createQuotaCmd.MarkFlagRequired("limit")
+
+	quotasCmd.AddCommand(createQuotaCmd)
+	quotasCmd.AddCommand(listQuotasCmd)
+	quotasCmd.AddCommand(deleteQuotaCmd)
+	rootCmd.AddCommand(quotasCmd)
+}
+
+func runCreateQuota(cmd *cobra.Command, args []string) error {
+	quota := &client.Quota{
+		Name:        args[0],
+		Description: createQuotaCmdDesc,
+		Metric:      createQuotaCmdMetric,
+		Period:      createQuotaCmdPeriod,
+		Limit:       createQuotaCmdLimit,
+		Enabled:     !createQuotaCmdDisabled,
+	}
+	if createQuotaCmdScope != "" {
+		scopeType, scopeValue, ok := strings.Cut(createQuotaCmdScope, ":")
+		if !ok || scopeType == "" || scopeValue == "" {
+			return fmt.Errorf("invalid scope '%s', expected <type>:<value>", createQuotaCmdScope)
+		}
+		quota.ScopeType = scopeType
+		quota.ScopeValue = scopeValue
+	}
+
+	if err := apiClient.CreateQuota(quota); err != nil {
+		return fmt.Errorf("failed to create quota: %w", err)
+	}
+	fmt.Printf("Quota %s created successfully!\n", quota.Name)
+	return nil
+}
+
+func runListQuotas(cmd *cobra.Command, args []string) error {
+	quotas, err := apiClient.ListQuotas()
+	if err != nil {
+		return fmt.Errorf("failed to list quotas: %w", err)
+	}
+
+	if len(quotas) == 0 {
+		fmt.Println("There are no quotas in the registry")
+		return nil
+	}
+	for i, q := range quotas {
+		status := "enabled"
+		switch {
+		case !q.Enabled:
+			status = "disabled"
+		case q.Exceeded:
+			status = "EXCEEDED"
+		}
+		scope := q.ScopeType
+		if q.ScopeValue != "" {
+			scope += ":" + q.ScopeValue
+		}
+		used := fmt.Sprintf("%g / %g calls", q.Current, q.Limit)
+		if q.Metric == "cost" {
+			used = fmt.Sprintf("$%.2f / $%.2f", q.Current, q.Limit)
+		}
+		fmt.Printf("%d. %s [%s]\n", i+1, q.Name, status)
+		fmt.Printf("%s this %s, scope: %s, resets at %s\n", used, q.Period, scope, q.ResetsAt.Local().Format("2006-01-02 15:04:05"))
+		if q.Description != "" {
+			fmt.Println(q.Description)
+		}
+		if i < len(quotas)-1 {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func runDeleteQuota(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteQuota(args[0]); err != nil {
+		return fmt.Errorf("failed to delete quota %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted quota %s\n", args[0])
+	return nil
+}
@@ -3,7 +3,10 @@ package cmd
 import (
 	"fmt"
 	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/duaraghav8/mcpjungle/internal/mock"
 	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -11,6 +14,11 @@ var (
 	registerCmdServerURL   string
 	registerCmdServerDesc  string
 	registerCmdBearerToken string
+
+	registerCmdEndpoints     []string
+	registerCmdLoadBalancing string
+
+	registerCmdMockConfig string
 )
 
 var registerMCPServerCmd = &cobra.Command{
@@ -47,10 +55,35 @@ func init() {
 			" This is useful if the MCP server requires static tokens (eg- your API token) for authentication.",
 	)
 
+	registerMCPServerCmd.Flags().StringArrayVar(
+		&registerCmdEndpoints,
+		"endpoint",
+		nil,
+		"URL of one of several endpoints serving the MCP server, eg- its replicas, optionally followed by its weight"+
+			" (eg- http://replica-1:8000/mcp,weight=2). Can be repeated instead of --url."+
+			" Tool calls are load balanced across the endpoints and fail over to another one if an endpoint is unreachable.",
+	)
+	registerMCPServerCmd.Flags().StringVar(
+		&registerCmdLoadBalancing,
+		"load-balancing",
+		"",
+		"Strategy to choose among the endpoints: round_robin (default) or least_in_flight",
+	)
+
+	registerMCPServerCmd.Flags().StringVar(
+		&registerCmdMockConfig,
+		"mock",
+		"",
+		"Path of a YAML file declaring a mock server (see the mock command), which the registry serves in-process"+
+			" instead of connecting to an upstream server. Can be given instead of --url.",
+	)
+
 	// TODO: name should not be mandatory.
 	//  If not supplied, name should be read from MCP server metadata by the registry.
 	_ = registerMCPServerCmd.MarkFlagRequired("name")
-	_ = registerMCPServerCmd.MarkFlagRequired("url")
+	registerMCPServerCmd.MarkFlagsOneRequired("url", "endpoint", "mock")
+	registerMCPServerCmd.MarkFlagsMutuallyExclusive("url", "mock")
+	registerMCPServerCmd.MarkFlagsMutuallyExclusive("endpoint", "mock")
 
 	rootCmd.AddCommand(registerMCPServerCmd)
 }
@@ -61,6 +94,27 @@ func runRegisterMCPServer(cmd *cobra.Command, args []string) error {
 		URL:         registerCmdServerURL,
 		Description: registerCmdServerDesc,
 		BearerToken: registerCmdBearerToken,
+
+		LoadBalancing: registerCmdLoadBalancing,
+	}
+	for _, e := range registerCmdEndpoints {
+		endpoint, err := parseEndpointFlag(e)
+		if err != nil {
+			return err
+		}
+		input.Endpoints = append(input.Endpoints, endpoint)
+	}
+	if registerCmdMockConfig != "" {
+		// images are read from the paths relative to the file, so the registry gets a self-contained config
+		cfg, err := mock.Load(registerCmdMockConfig)
+		if err != nil {
+			return err
+		}
+		data, err := cfg.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode mock config: %w", err)
+		}
+		input.MockConfig = string(data)
 	}
 	s, err := apiClient.RegisterServer(input)
 	if err != nil {
@@ -81,3 +135,16 @@ func runRegisterMCPServer(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// parseEndpointFlag parses an endpoint given as its URL, optionally followed by ",weight=<weight>".
+func parseEndpointFlag(value string) (client.ServerEndpoint, error) {
+	u, weight, ok := strings.Cut(value, ",weight=")
+	if !ok {
+		return client.ServerEndpoint{URL: value}, nil
+	}
+	w, err := strconv.Atoi(weight)
+	if err != nil || w < 1 {
+		return client.ServerEndpoint{}, fmt.Errorf("invalid weight '%s' of endpoint %s: must be a positive integer", weight, u)
+	}
+	return client.ServerEndpoint{URL: u, Weight: w}, nil
+}
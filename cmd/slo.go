@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/client"
+	"github.com/spf13/cobra"
+)
+
+var sloCmd = &cobra.Command{
+	Use:   "slo",
+	Short: "Manage availability objectives (SLOs) of MCP servers and report on them",
+	Long: "The availability of a server is the fraction of its health checks and tool calls that succeeded.\n" +
+		"Tool error results and calls cancelled by the client don't count against the server.",
+}
+
+var setSLOCmdTarget float64
+
+var setSLOCmd = &cobra.Command{
+	Use:   "set <server>",
+	Short: "Set the SLO of a server",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSetSLO,
+}
+
+var listSLOsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List SLOs",
+	RunE:  runListSLOs,
+}
+
+var deleteSLOCmd = &cobra.Command{
+	Use:   "delete <server>",
+	Short: "Delete the SLO of a server",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDeleteSLO,
+}
+
+var (
+	sloReportCmdMonth string
+	sloReportCmdFrom  string
+	sloReportCmdTo    string
+)
+
+var sloReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report the availability of servers against their SLOs",
+	Long: "Report the availability of every server and the error budget it consumed.\n" +
+		"Specify the window either with --month or with --from and --to, it defaults to the current month.",
+	RunE: runSLOReport,
+}
+
+func init() {
+	setSLOCmd.Flags().Float64Var(&setSLOCmdTarget, "target", 0, "Availability target in percent, eg- 99.9")
+	_ = setSLOCmd.MarkFlagRequired("target")
+
+	sloReportCmd.Flags().StringVar(
+		&sloReportCmdMonth,
+		"month",
+		"",
+		"Report on a whole calendar month (YYYY-MM), eg- 2025-06",
+	)
+	sloReportCmd.Flags().StringVar(
+		&sloReportCmdFrom,
+		"from",
+		"",
+		"Start of the report window (YYYY-MM-DD or RFC3339 timestamp), inclusive",
+	)
+	sloReportCmd.Flags().StringVar(
+		&sloReportCmdTo,
+		"to",
+		"",
+		"End of the report window (YYYY-MM-DD or RFC3339 timestamp). A date includes the whole day.",
+	)
+	sloReportCmd.MarkFlagsMutuallyExclusive("month", "from")
+	sloReportCmd.MarkFlagsMutuallyExclusive("month", "to")
+	sloReportCmd.MarkFlagsRequiredTogether("from", "to")
+
+	sloCmd.AddCommand(setSLOCmd)
+	sloCmd.AddCommand(listSLOsCmd)
+	sloCmd.AddCommand(deleteSLOCmd)
+	sloCmd.AddCommand(sloReportCmd)
+	rootCmd.AddCommand(sloCmd)
+}
+
+func runSetSLO(cmd *cobra.Command, args []string) error {
+	if setSLOCmdTarget <= 0 || setSLOCmdTarget >= 100 {
+		return fmt.Errorf("invalid target %v: must be a percentage between 0 and 100 (exclusive)", setSLOCmdTarget)
+	}
+	slo, err := apiClient.SetServerSLO(&client.ServerSLO{ServerName: args[0], Target: setSLOCmdTarget / 100})
+	if err != nil {
+		return fmt.Errorf("failed to set the SLO of %s: %w", args[0], err)
+	}
+	fmt.Printf("SLO of %s set to %s availability\n", slo.ServerName, formatPercent(slo.Target))
+	return nil
+}
+
+func runListSLOs(cmd *cobra.Command, args []string) error {
+	slos, err := apiClient.ListServerSLOs()
+	if err != nil {
+		return fmt.Errorf("failed to list SLOs: %w", err)
+	}
+	if len(slos) == 0 {
+		fmt.Println("There are no SLOs in the registry")
+		return nil
+	}
+	for i, slo := range slos {
+		fmt.Printf("%d. %s: %s availability\n", i+1, slo.ServerName, formatPercent(slo.Target))
+	}
+	return nil
+}
+
+func runDeleteSLO(cmd *cobra.Command, args []string) error {
+	if err := apiClient.DeleteServerSLO(args[0]); err != nil {
+		return fmt.Errorf("failed to delete the SLO of %s: %w", args[0], err)
+	}
+	fmt.Printf("Successfully deleted the SLO of %s\n", args[0])
+	return nil
+}
+
+func runSLOReport(cmd *cobra.Command, args []string) error {
+	from, to := sloReportCmdFrom, sloReportCmdTo
+	if sloReportCmdMonth != "" {
+		var err error
+		if from, to, err = monthWindow(sloReportCmdMonth); err != nil {
+			return err
+		}
+	}
+
+	report, err := apiClient.GetSLOReport(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to get the SLO report: %w", err)
+	}
+
+	fmt.Printf("Availability from %s to %s", report.From.Format(time.RFC3339), report.To.Format(time.RFC3339))
+	if report.Elapsed < 1 {
+		fmt.Printf(" (%s elapsed)", formatPercent(report.Elapsed))
+	}
+	fmt.Println()
+	if len(report.Servers) == 0 {
+		fmt.Println("There are no MCP servers in the registry")
+		return nil
+	}
+	for i, s := range report.Servers {
+		fmt.Println()
+		fmt.Printf("%d. %s\n", i+1, s.ServerName)
+		fmt.Printf(
+			"health checks: %d (%d healthy), tool calls: %d (%d failed)\n",
+			s.HealthChecks, s.HealthyChecks, s.ToolCalls, s.FailedToolCalls,
+		)
+		if s.Availability == nil {
+			fmt.Println("availability: no data")
+			continue
+		}
+		if s.Target == nil {
+			fmt.Printf("availability: %s (no SLO)\n", formatPercent(*s.Availability))
+			continue
+		}
+		verdict := "met"
+		if !*s.Met {
+			verdict = "missed"
+		}
+		fmt.Printf("availability: %s, SLO %s %s\n", formatPercent(*s.Availability), formatPercent(*s.Target), verdict)
+		fmt.Printf("error budget: %s consumed", formatPercent(*s.ErrorBudgetConsumed))
+		if s.BurnRate != nil {
+			fmt.Printf(", burn rate %.2f", *s.BurnRate)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// formatPercent formats a fraction as a percentage, with enough decimals to tell nines apart.
+func formatPercent(f float64) string {
+	p := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", f*100), "0"), ".")
+	return p + "%"
+}
@@ -1,28 +1,69 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"net/http"
-	_ "net/http/pprof"
 	"github.com/duaraghav8/mcpjungle/internal/api"
 	"github.com/duaraghav8/mcpjungle/internal/db"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
 	"github.com/duaraghav8/mcpjungle/internal/migrations"
 	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
+	"log/slog"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
 const (
 	BindPortEnvVar  = "PORT"
 	BindPortDefault = "8080"
+
+	// DebugLevelEnvVar is the minimum level of the logs: debug, info (the default), warn or error
+	DebugLevelEnvVar = "DEBUG_LEVEL"
+	// LogFormatEnvVar is the format of the logs: text (the default) or json
+	LogFormatEnvVar = "LOG_FORMAT"
+
+	AlertCheckIntervalEnvVar  = "ALERT_CHECK_INTERVAL"
+	AlertCheckIntervalDefault = time.Minute
+
+	EndpointHealthCheckIntervalEnvVar  = "ENDPOINT_HEALTH_CHECK_INTERVAL"
+	EndpointHealthCheckIntervalDefault = 15 * time.Second
+
+	HealthCheckIntervalEnvVar  = "HEALTH_CHECK_INTERVAL"
+	HealthCheckIntervalDefault = 30 * time.Second
+
+	PricingFileEnvVar = "PRICING_FILE"
+
+	// CassetteDirEnvVar is the directory of the cassette files, the paths of cassettes are relative to it
+	CassetteDirEnvVar  = "CASSETTE_DIR"
+	CassetteDirDefault = "cassettes"
+
+	// ShutdownTimeoutEnvVar is how long the server waits for the tool calls in progress when shutting down
+	ShutdownTimeoutEnvVar  = "SHUTDOWN_TIMEOUT"
+	ShutdownTimeoutDefault = 30 * time.Second
+
+	// Tracing is enabled by setting the URL of an OpenTelemetry collector that accepts OTLP over HTTP,
+	// either the base URL (eg- http://localhost:4318) or the URL of its traces endpoint.
+	OTLPEndpointEnvVar       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLPTracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	OTLPHeadersEnvVar        = "OTEL_EXPORTER_OTLP_HEADERS"
+	OTelServiceNameEnvVar    = "OTEL_SERVICE_NAME"
+	OTelServiceNameDefault   = "mcpjungle"
 )
 
-var startServerCmdBindPort string
+var (
+	startServerCmdBindPort    string
+	startServerCmdPricingFile string
+)
 
 var startServerCmd = &cobra.Command{
 	Use:   "start",
@@ -37,6 +78,12 @@ func init() {
 		"",
 		fmt.Sprintf("port to bind the server to (overrides env var %s)", BindPortEnvVar),
 	)
+	startServerCmd.Flags().StringVar(
+		&startServerCmdPricingFile,
+		"pricing-file",
+		"",
+		fmt.Sprintf("YAML or JSON file to load the model pricing catalog from (overrides env var %s)", PricingFileEnvVar),
+	)
 	rootCmd.AddCommand(startServerCmd)
 }
 
@@ -44,7 +91,14 @@ func runStartServer(cmd *cobra.Command, args []string) error {
 	_ = godotenv.Load()
 
 	// configure debug level and logging
-	configureDebugLevel()
+	if err := configureDebugLevel(); err != nil {
+		return err
+	}
+
+	tracer, err := configureTracing()
+	if err != nil {
+		return err
+	}
 
 	// connect to the DB and run migrations
 	dsn := os.Getenv("DATABASE_URL")
@@ -66,20 +120,45 @@ func runStartServer(cmd *cobra.Command, args []string) error {
 	}
 
 	// create the MCP proxy server
+	// its hooks are installed by the MCP service once it is created
+	proxyHooks := &server.Hooks{}
 	mcpProxyServer := server.NewMCPServer(
 		"MCPJungle Proxy MCP Server",
 		"0.0.1",
 		server.WithToolCapabilities(true),
+		server.WithHooks(proxyHooks),
 	)
 
-	mcpService, err := service.NewMCPService(dbConn, mcpProxyServer)
+	// create the notification service, which delivers alerts to the configured channels
+	notificationService := service.NewNotificationService(dbConn)
+
+	// create the analytics service
+	analyticsService := service.NewAnalyticsService(dbConn, notificationService)
+
+	// load the model pricing catalog, if any
+	pricingFile := startServerCmdPricingFile
+	if pricingFile == "" {
+		pricingFile = os.Getenv(PricingFileEnvVar)
+	}
+	if pricingFile != "" {
+		if err := analyticsService.LoadPricingFile(pricingFile); err != nil {
+			return fmt.Errorf("failed to load the pricing catalog: %v", err)
+		}
+	}
+
+	cassetteDir := os.Getenv(CassetteDirEnvVar)
+	if cassetteDir == "" {
+		cassetteDir = CassetteDirDefault
+	}
+	mcpService, err := service.NewMCPService(dbConn, mcpProxyServer, analyticsService, cassetteDir)
 	if err != nil {
 		return fmt.Errorf("failed to create MCP service: %v", err)
 	}
+	mcpService.RegisterProxyHooks(proxyHooks)
 
 	// create the client service
 	clientService := service.NewClientService(dbConn)
-	
+
 	// initialize default clients
 	if err := clientService.InitializeDefaultClients(); err != nil {
 		return fmt.Errorf("failed to initialize default clients: %v", err)
@@ -90,47 +169,147 @@ func runStartServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize example servers: %v", err)
 	}
 
-	// create the health service
+	shutdownTimeout, err := intervalFromEnv(ShutdownTimeoutEnvVar, ShutdownTimeoutDefault)
+	if err != nil {
+		return err
+	}
+
+	// the background tasks stop when the server receives a termination signal
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// periodically evaluate the user-defined alert rules
+	alertCheckInterval, err := intervalFromEnv(AlertCheckIntervalEnvVar, AlertCheckIntervalDefault)
+	if err != nil {
+		return err
+	}
+	analyticsService.StartThresholdMonitor(ctx, alertCheckInterval)
+
+	// periodically probe the endpoints of servers that have several of them
+	endpointHealthCheckInterval, err := intervalFromEnv(EndpointHealthCheckIntervalEnvVar, EndpointHealthCheckIntervalDefault)
+	if err != nil {
+		return err
+	}
+	mcpService.StartEndpointHealthChecks(ctx, endpointHealthCheckInterval)
+
+	// run the canary checks on their schedule
+	mcpService.StartCanaries(ctx)
+
+	// create the health service and periodically probe all servers
 	healthService := service.NewHealthService(dbConn)
+	healthCheckInterval, err := intervalFromEnv(HealthCheckIntervalEnvVar, HealthCheckIntervalDefault)
+	if err != nil {
+		return err
+	}
+	healthService.StartProbing(ctx, healthCheckInterval)
 
 	// create the API server
-	s, err := api.NewServer(port, mcpProxyServer, mcpService, clientService, healthService)
+	s, err := api.NewServer(port, mcpProxyServer, mcpService, clientService, analyticsService, notificationService, healthService)
 	if err != nil {
 		return fmt.Errorf("failed to create server: %v", err)
 	}
 
-	fmt.Printf("MCPJungle server listening on :%s", port)
-	if err := s.Start(); err != nil {
-		return fmt.Errorf("failed to run the server: %v", err)
+	slog.Info("MCPJungle server listening", slog.String("port", port))
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- s.Start()
+	}()
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			return fmt.Errorf("failed to run the server: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
 	}
 
+	// a second signal terminates the server right away
+	stop()
+	slog.Info("shutting down, waiting for tool calls in progress", slog.Duration("timeout", shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("failed to shut down the server gracefully", logging.Err(err))
+	}
+	// the calls have been recorded once they complete, only alert deliveries may still be in progress
+	if err := notificationService.Wait(shutdownCtx); err != nil {
+		slog.Warn("alert deliveries did not complete before the shutdown deadline", logging.Err(err))
+	}
+	if tracer != nil {
+		if err := tracer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("failed to export the remaining traces", logging.Err(err))
+		}
+	}
+	if err := db.Close(dbConn); err != nil {
+		return err
+	}
+	slog.Info("server shut down")
 	return nil
 }
 
+// intervalFromEnv returns the interval of a periodic background task, as configured by the env var.
+func intervalFromEnv(envVar string, defaultInterval time.Duration) (time.Duration, error) {
+	v := os.Getenv(envVar)
+	if v == "" {
+		return defaultInterval, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s '%s': must be a positive duration (eg- 30s, 5m)", envVar, v)
+	}
+	return d, nil
+}
+
+// configureTracing enables tracing if an OpenTelemetry collector is configured, and returns the tracer.
+// It returns nil if tracing is disabled.
+func configureTracing() (*tracing.Tracer, error) {
+	tracesURL := os.Getenv(OTLPTracesEndpointEnvVar)
+	if tracesURL == "" {
+		endpoint := os.Getenv(OTLPEndpointEnvVar)
+		if endpoint == "" {
+			return nil, nil
+		}
+		tracesURL = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	headers, err := tracing.ParseHeaders(os.Getenv(OTLPHeadersEnvVar))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", OTLPHeadersEnvVar, err)
+	}
+	serviceName := os.Getenv(OTelServiceNameEnvVar)
+	if serviceName == "" {
+		serviceName = OTelServiceNameDefault
+	}
+
+	tracer := tracing.NewTracer(tracing.NewOTLPExporter(tracesURL, headers, serviceName))
+	tracing.SetTracer(tracer)
+	slog.Info("exporting traces", slog.String("url", tracesURL))
+	return tracer, nil
+}
+
 // configureDebugLevel sets up logging and debug level based on environment variables
-func configureDebugLevel() {
-	debugLevel := strings.ToLower(os.Getenv("DEBUG_LEVEL"))
-	if debugLevel == "" {
-		debugLevel = "info"
+func configureDebugLevel() error {
+	level := slog.LevelInfo
+	debugLevel := os.Getenv(DebugLevelEnvVar)
+	var levelErr error
+	if debugLevel != "" {
+		if level, levelErr = logging.ParseLevel(debugLevel); levelErr != nil {
+			level = slog.LevelInfo
+		}
+	}
+	if err := logging.Configure(os.Stderr, os.Getenv(LogFormatEnvVar), level); err != nil {
+		return err
+	}
+	if levelErr != nil {
+		slog.Warn("unknown debug level, defaulting to info", slog.String("debug_level", debugLevel))
 	}
 
-	switch debugLevel {
-	case "debug":
+	if level == slog.LevelDebug {
 		gin.SetMode(gin.DebugMode)
-		log.Println("[DEBUG] Debug mode enabled")
-	case "info":
-		gin.SetMode(gin.ReleaseMode)
-		log.Println("[INFO] Info mode enabled")
-	case "warn", "warning":
+	} else {
 		gin.SetMode(gin.ReleaseMode)
-		log.Println("[WARN] Warning mode enabled")
-	case "error":
-		gin.SetMode(gin.ReleaseMode)
-		log.Println("[ERROR] Error mode enabled")
-	default:
-		gin.SetMode(gin.ReleaseMode)
-		log.Printf("[WARN] Unknown debug level '%s', defaulting to info mode", debugLevel)
 	}
+	slog.Debug("debug mode enabled")
 
 	// Enable memory profiling if requested
 	enablePprof := strings.ToLower(os.Getenv("ENABLE_PPROF"))
@@ -139,12 +318,13 @@ func configureDebugLevel() {
 		if pprofPort == "" {
 			pprofPort = "6060"
 		}
-		log.Printf("[DEBUG] Memory profiling enabled on port %s", pprofPort)
-		log.Println("[DEBUG] Access profiling at http://localhost:" + pprofPort + "/debug/pprof/")
-		
+		slog.Debug("memory profiling enabled", slog.String("url", "http://localhost:"+pprofPort+"/debug/pprof/"))
+
 		// Start pprof server in a goroutine
 		go func() {
-			log.Println(http.ListenAndServe("localhost:"+pprofPort, nil))
+			err := http.ListenAndServe("localhost:"+pprofPort, nil)
+			slog.Error("profiling server stopped", logging.Err(err))
 		}()
 	}
+	return nil
 }
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/assertion"
+	"github.com/duaraghav8/mcpjungle/internal/testsuite"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testCmdFile     string
+	testCmdJUnit    string
+	testCmdParallel int
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run a test suite of tool calls through the registry",
+	Long: "Run the test cases of a YAML suite through the registry, eg- as regression tests of the registered MCP servers.\n" +
+		"Each case calls a tool with an input and checks the result: whether it is a tool error, that its text contains\n" +
+		"strings or matches regular expressions, JSONPath conditions on its JSON text content, and the latency of the call.\n" +
+		"Cases run in parallel. The command exits with an error if any case fails, eg- to fail a CI job.",
+	RunE: runTest,
+}
+
+func init() {
+	testCmd.Flags().StringVarP(&testCmdFile, "file", "f", "", "Path of the test suite file")
+	testCmd.Flags().StringVar(&testCmdJUnit, "junit", "", "Path to write a JUnit XML report to")
+	testCmd.Flags().IntVar(
+		&testCmdParallel,
+		"parallel",
+		0,
+		fmt.Sprintf("How many cases run at once, overrides the suite's parallel (default %d)", testsuite.DefaultParallel),
+	)
+	_ = testCmd.MarkFlagRequired("file")
+
+	rootCmd.AddCommand(testCmd)
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	suite, err := testsuite.Load(testCmdFile)
+	if err != nil {
+		return err
+	}
+	if suite.Name == "" {
+		suite.Name = testCmdFile
+	}
+	parallel := suite.Parallel
+	if testCmdParallel > 0 {
+		parallel = testCmdParallel
+	}
+
+	started := time.Now()
+	results := suite.Run(invokeTestCase, parallel)
+	elapsed := time.Since(started)
+
+	failed, errored := 0, 0
+	for _, r := range results {
+		d := r.Duration.Round(time.Millisecond)
+		switch {
+		case r.Err != nil:
+			errored++
+			fmt.Printf("ERROR %s (%s)\n      %v\n", r.Case.Name, d, r.Err)
+		case len(r.Failures) > 0:
+			failed++
+			fmt.Printf("FAIL  %s (%s)\n", r.Case.Name, d)
+			for _, f := range r.Failures {
+				fmt.Printf("      - %s\n", f)
+			}
+		default:
+			fmt.Printf("PASS  %s (%s)\n", r.Case.Name, d)
+		}
+	}
+	fmt.Printf("\n%d passed, %d failed, %d errors in %s\n",
+		len(results)-failed-errored, failed, errored, elapsed.Round(time.Millisecond))
+
+	if testCmdJUnit != "" {
+		f, err := os.Create(testCmdJUnit)
+		if err != nil {
+			return fmt.Errorf("failed to create JUnit report: %w", err)
+		}
+		if err := testsuite.WriteJUnit(f, suite, started, results); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+	}
+
+	if failed+errored > 0 {
+		return fmt.Errorf("%d of %d test case(s) failed", failed+errored, len(results))
+	}
+	return nil
+}
+
+// invokeTestCase calls a tool through the registry for a test case.
+func invokeTestCase(tool string, input map[string]any) (assertion.Outcome, error) {
+	result, err := apiClient.InvokeTool(tool, input)
+	if err != nil {
+		return assertion.Outcome{}, err
+	}
+	o := assertion.Outcome{IsError: result.IsError}
+	for _, c := range result.Content {
+		if c["type"] == "text" {
+			text, _ := c["text"].(string)
+			o.Texts = append(o.Texts, text)
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func createAlertRuleHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// rules are enabled unless explicitly disabled in the request
+		req := model.AlertRule{Enabled: true}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := analyticsService.CreateAlertRule(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, req)
+	}
+}
+
+func listAlertRulesHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := analyticsService.ListAlertRules()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, rules)
+	}
+}
+
+func deleteAlertRuleHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if err := analyticsService.DeleteAlertRule(name); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func listAlertsHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var includeResolved bool
+		switch status := c.DefaultQuery("status", "open"); status {
+		case "open":
+		case "all":
+			includeResolved = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status '" + status + "', must be open or all"})
+			return
+		}
+		alerts, err := analyticsService.ListAlerts(includeResolved)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, alerts)
+	}
+}
+
+func acknowledgeAlertHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := analyticsService.AcknowledgeAlert(c.Param("id")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func resolveAlertHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := analyticsService.ResolveAlert(c.Param("id")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
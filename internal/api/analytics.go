@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// reportDateLayout is the date-only layout accepted for report windows (in addition to RFC3339 timestamps).
+const reportDateLayout = "2006-01-02"
+
+// maxUsageBatchSize is the maximum number of usage records accepted in a single request.
+const maxUsageBatchSize = 1000
+
+// recordUsageHandler ingests token usage reported by agents and SDK wrappers.
+// The body is either a single usage object or an array of them (batch).
+// A batch is recorded atomically, ie, if any record in it is invalid, none is stored.
+func recordUsageHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+		batch := len(body) > 0 && body[0] == '['
+		var usages []*model.UsageMetric
+		if batch {
+			err = json.Unmarshal(body, &usages)
+		} else {
+			var u model.UsageMetric
+			err = json.Unmarshal(body, &u)
+			usages = append(usages, &u)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+			return
+		}
+		if len(usages) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "batch must contain at least one usage record"})
+			return
+		}
+		if len(usages) > maxUsageBatchSize {
+			c.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": fmt.Sprintf("batch cannot contain more than %d usage records", maxUsageBatchSize)},
+			)
+			return
+		}
+		caller := service.CallerFromContext(c.Request.Context())
+		for i, u := range usages {
+			if u == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("usage record at index %d is null", i)})
+				return
+			}
+			// usage is attributed to the API key the reporter presented, never to one named in the body
+			u.APIKeyHash = caller.APIKeyHash
+		}
+
+		if err := analyticsService.RecordUsage(usages...); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrInvalidUsage) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if batch {
+			c.JSON(http.StatusCreated, usages)
+			return
+		}
+		c.JSON(http.StatusCreated, usages[0])
+	}
+}
+
+// exportUsageReportHandler streams a usage report for the requested window in the requested format.
+// Query params:
+//   - from: start of the window (RFC3339 timestamp or YYYY-MM-DD), inclusive
+//   - to: end of the window (RFC3339 timestamp or YYYY-MM-DD), exclusive.
+//     A date-only value includes the whole day, eg- to=2025-06-30 covers all of June 30.
+//   - format: csv (default), json or ndjson
+func exportUsageReportHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, err := parseReportTime(c.Query("from"), false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' query parameter: " + err.Error()})
+			return
+		}
+		to, err := parseReportTime(c.Query("to"), true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' query parameter: " + err.Error()})
+			return
+		}
+		if !to.After(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'to' must be after 'from'"})
+			return
+		}
+
+		format := c.DefaultQuery("format", string(service.ReportFormatCSV))
+		reportFormat, err := service.ParseReportFormat(format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		report, err := analyticsService.NewUsageReport(from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := fmt.Sprintf("usage_%s_%s.%s", from.Format(reportDateLayout), to.Format(reportDateLayout), reportFormat)
+		c.Header("Content-Type", reportFormat.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+
+		// The response status has already been sent once the report starts streaming,
+		// so an error midway can only be logged and the response truncated.
+		if err := report.Write(c.Writer, reportFormat); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to export usage report", logging.Err(err))
+			_ = c.Error(err)
+		}
+	}
+}
+
+// parseReportTime parses a report window boundary.
+// If endOfDay is true and the value is a plain date, the returned time is the start of the next day,
+// so that the whole day is included in a window whose end is exclusive.
+func parseReportTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("value is required")
+	}
+	if t, err := time.Parse(reportDateLayout, value); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither a YYYY-MM-DD date nor an RFC3339 timestamp", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// setCallCaptureHandler enables capturing the payloads of the tool calls made to a server, or updates its capture config.
+func setCallCaptureHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CallCaptureConfig
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := mcpService.SetCallCaptureConfig(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+func listCallCapturesHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		configs, err := mcpService.ListCallCaptureConfigs()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, configs)
+	}
+}
+
+func deleteCallCaptureHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := mcpService.DeleteCallCaptureConfig(c.Param("server")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listCapturedCallsHandler lists the captured tool calls, the most recent first.
+// Query params:
+//   - server, tool, session_id, request_id: only list the calls that match
+//   - errors: if true, only list the calls that failed
+//   - since: only list the calls made after this time (RFC3339 timestamp or YYYY-MM-DD), exclusive
+//   - until: only list the calls made until this time (RFC3339 timestamp or YYYY-MM-DD), inclusive
+//   - limit: maximum number of calls to list
+func listCapturedCallsHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := service.CapturedCallFilter{
+			ServerName: c.Query("server"),
+			ToolName:   c.Query("tool"),
+			SessionID:  c.Query("session_id"),
+			RequestID:  c.Query("request_id"),
+		}
+		var err error
+		if v := c.Query("errors"); v != "" {
+			if filter.OnlyErrors, err = strconv.ParseBool(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'errors' query parameter '" + v + "', must be a boolean"})
+				return
+			}
+		}
+		if v := c.Query("since"); v != "" {
+			if filter.Since, err = parseReportTime(v, false); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'since' query parameter: " + err.Error()})
+				return
+			}
+		}
+		if v := c.Query("until"); v != "" {
+			if filter.Until, err = parseReportTime(v, false); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'until' query parameter: " + err.Error()})
+				return
+			}
+		}
+		if v := c.Query("limit"); v != "" {
+			l, err := strconv.Atoi(v)
+			if err != nil || l <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit '" + v + "', must be a positive integer"})
+				return
+			}
+			filter.Limit = l
+		}
+
+		calls, err := mcpService.ListCapturedCalls(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, calls)
+	}
+}
+
+func getCapturedCallHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		call, err := mcpService.GetCapturedCall(c.Param("id"))
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, call)
+	}
+}
+
+// replayCallRequest is the body of a replay request, which is optional.
+type replayCallRequest struct {
+	// Server is the server to replay the call against, the recorded server by default
+	Server string `json:"server"`
+}
+
+// replayCapturedCallHandler calls the tool of a captured call again with the same arguments
+// and responds with the new outcome, compared to the recorded one.
+func replayCapturedCallHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req replayCallRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		replay, err := mcpService.ReplayCapturedCall(c.Request.Context(), c.Param("id"), req.Server)
+		if err != nil {
+			// the call or the tool to replay it against is unknown, other failures are due to the call itself
+			status := http.StatusBadRequest
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, replay)
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setCallLimitHandler creates or replaces the rate and concurrency limit of a server or tool.
+func setCallLimitHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CallLimit
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := mcpService.SetCallLimit(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+// listCallLimitsHandler returns all call limits along with how many calls they queued and rejected.
+func listCallLimitsHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits, err := mcpService.ListCallLimits()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, limits)
+	}
+}
+
+// deleteCallLimitHandler deletes the limit of a server (/limits/server/<name>)
+// or a tool (/limits/tool/<server>/<tool>).
+func deleteCallLimitHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopeType := model.CallScope(c.Param("scope"))
+		scopeValue := strings.TrimPrefix(c.Param("name"), "/")
+		if err := mcpService.DeleteCallLimit(scopeType, scopeValue); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
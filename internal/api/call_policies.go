@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setCallPolicyHandler creates the call policy of a server or tool, or updates the settings present in the request.
+func setCallPolicyHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CallPolicy
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := mcpService.SetCallPolicy(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+func listCallPoliciesHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policies, err := mcpService.ListCallPolicies()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, policies)
+	}
+}
+
+// deleteCallPolicyHandler deletes the policy of a server (/policies/server/<name>)
+// or a tool (/policies/tool/<server>/<tool>).
+func deleteCallPolicyHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopeType := model.CallScope(c.Param("scope"))
+		scopeValue := strings.TrimPrefix(c.Param("name"), "/")
+		if err := mcpService.DeleteCallPolicy(scopeType, scopeValue); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
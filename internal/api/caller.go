@@ -0,0 +1,35 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries the caller's API key, as an alternative to "Authorization: Bearer <key>"
+	APIKeyHeader = "X-API-Key"
+	// ClientTypeHeader carries the type of client making the request (eg- cursor, claude)
+	ClientTypeHeader = "X-MCPJungle-Client"
+)
+
+// callerMiddleware identifies the caller of every request from its headers and
+// stores it in the request's context, so that the MCP proxy and the API handlers
+// can attribute usage and enforce quotas.
+func callerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader(APIKeyHeader)
+		if apiKey == "" {
+			if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+				apiKey = strings.TrimSpace(token)
+			}
+		}
+		caller := service.Caller{
+			APIKeyHash: service.HashAPIKey(apiKey),
+			ClientType: strings.TrimSpace(c.GetHeader(ClientTypeHeader)),
+		}
+		c.Request = c.Request.WithContext(service.ContextWithCaller(c.Request.Context(), caller))
+		c.Next()
+	}
+}
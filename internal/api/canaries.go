@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setCanaryHandler creates a canary check or replaces the existing one with the same name.
+func setCanaryHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CanaryCheck
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := mcpService.SetCanaryCheck(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+func listCanariesHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		canaries, err := mcpService.ListCanaryChecks()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, canaries)
+	}
+}
+
+func deleteCanaryHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := mcpService.DeleteCanaryCheck(c.Param("name")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listCanaryRunsHandler returns the most recent runs of a canary.
+// Query params:
+//   - limit: maximum number of runs to return
+func listCanaryRunsHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := service.DefaultCanaryRunsLimit
+		if v := c.Query("limit"); v != "" {
+			l, err := strconv.Atoi(v)
+			if err != nil || l <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit '" + v + "', must be a positive integer"})
+				return
+			}
+			limit = l
+		}
+		runs, err := mcpService.ListCanaryRuns(c.Param("name"), limit)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, runs)
+	}
+}
+
+// runCanaryHandler runs a canary right away and returns the outcome.
+func runCanaryHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		run, err := mcpService.RunCanaryCheck(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			status := errorStatus(err)
+			if errors.Is(err, service.ErrCanaryRunning) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, run)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setCassetteHandler puts a server in record or replay mode.
+func setCassetteHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CassetteConfig
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := mcpService.SetCassetteConfig(c.Request.Context(), &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+// listCassettesHandler reports the cassettes of the servers in record or replay mode, including the calls they didn't match.
+func listCassettesHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cassettes, err := mcpService.ListCassettes()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, cassettes)
+	}
+}
+
+func deleteCassetteHandler(mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := mcpService.DeleteCassetteConfig(c.Request.Context(), c.Param("server")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
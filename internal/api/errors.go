@@ -0,0 +1,18 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// errorStatus returns the HTTP status code for an error returned by a service:
+// 404 if the resource that the request refers to doesn't exist, 500 otherwise.
+// Services wrap gorm.ErrRecordNotFound in their errors for missing resources.
+func errorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
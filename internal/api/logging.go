@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, so that the caller can correlate it with the registry's logs.
+// The registry uses the ID sent by the caller, if any, and returns it in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from callers, longer IDs are replaced
+const maxRequestIDLength = 128
+
+// loggingMiddleware assigns an ID to every request, attaches it to the request's context so that
+// everything logged while serving the request carries it, and logs the request once it completes.
+// Requests to the probes and metrics are only logged at the debug level.
+func loggingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		attrs := []slog.Attr{slog.String(logging.KeyRequestID, requestID)}
+		if sessionID := c.GetHeader(mcpSessionIDHeader); sessionID != "" {
+			attrs = append(attrs, slog.String(logging.KeySession, sessionID))
+		}
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), attrs...))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case untracedRoutes[route]:
+			level = slog.LevelDebug
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "request served",
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// getLogLevelHandler returns the current log level.
+func getLogLevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName()})
+	}
+}
+
+// setLogLevelHandler changes the log level without restarting the server.
+func setLogLevelHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Level string `json:"level" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		level, err := logging.ParseLevel(req.Level)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		previous := logging.LevelName()
+		logging.SetLevel(level)
+		slog.InfoContext(c.Request.Context(), "log level changed",
+			slog.String("from", previous), slog.String("to", logging.LevelName()))
+		c.JSON(http.StatusOK, gin.H{"level": logging.LevelName()})
+	}
+}
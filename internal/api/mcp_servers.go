@@ -33,13 +33,27 @@ func deregisterServerHandler(mcpService *service.MCPService) gin.HandlerFunc {
 	}
 }
 
-func listServersHandler(mcpService *service.MCPService) gin.HandlerFunc {
+func listServersHandler(mcpService *service.MCPService, healthService *service.HealthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		servers, err := mcpService.ListMcpServers()
+		servers, err := mcpService.ListMcpServerStatuses()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		health, err := healthService.ListServerHealth()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		byName := make(map[string]service.ServerHealth, len(health))
+		for _, h := range health {
+			byName[h.ServerName] = h
+		}
+		for i := range servers {
+			if h, ok := byName[servers[i].Name]; ok {
+				servers[i].Health = &h
+			}
+		}
 		c.JSON(http.StatusOK, servers)
 	}
 }
@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/duaraghav8/mcpjungle/internal/model"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/duaraghav8/mcpjungle/internal/service"
 	"github.com/gin-gonic/gin"
@@ -57,7 +60,43 @@ func invokeToolHandler(mcpService *service.MCPService) gin.HandlerFunc {
 		// remove name from args since it was an input for the api, not for the tool
 		delete(args, "name")
 
-		resp, err := mcpService.InvokeTool(c, name, args)
+		resp, err := mcpService.InvokeTool(c.Request.Context(), name, args)
+		var invalidArgsErr *service.InvalidArgumentsError
+		if errors.As(err, &invalidArgsErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalidArgsErr.Error(), "invalid_arguments": invalidArgsErr})
+			return
+		}
+		var quotaErr *service.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": quotaErr.Error(), "quota": quotaErr})
+			return
+		}
+		var throttledErr *service.CallThrottledError
+		if errors.As(err, &throttledErr) {
+			if throttledErr.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttledErr.RetryAfter.Seconds()))))
+			}
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": throttledErr.Error(), "throttled": throttledErr})
+			return
+		}
+		var unavailableErr *service.ServerUnavailableError
+		if errors.As(err, &unavailableErr) {
+			if unavailableErr.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(unavailableErr.RetryAfter.Seconds()))))
+			}
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": unavailableErr.Error(), "unavailable": unavailableErr})
+			return
+		}
+		var shuttingDownErr *service.RegistryShuttingDownError
+		if errors.As(err, &shuttingDownErr) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": shuttingDownErr.Error()})
+			return
+		}
+		var timeoutErr *service.ToolCallTimeoutError
+		if errors.As(err, &timeoutErr) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": timeoutErr.Error(), "timeout": timeoutErr})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to invoke tool: " + err.Error()})
 			return
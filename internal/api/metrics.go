@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	apiRequestsTotal = metrics.DefaultRegistry.NewCounter(
+		"mcpjungle_api_requests_total",
+		"HTTP requests served by the registry, by route and status code.",
+		"method", "route", "status",
+	)
+	apiRequestDuration = metrics.DefaultRegistry.NewHistogram(
+		"mcpjungle_api_request_duration_seconds",
+		"Duration of the HTTP requests served by the registry, by route.",
+		metrics.DefBuckets,
+		"method", "route",
+	)
+)
+
+// knownMethods are the HTTP methods reported as is by metrics, other methods are reported as OTHER.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// metricsMiddleware counts and times every request.
+// Requests are labelled with their route pattern (eg- /api/v0/servers/:name) rather than their path,
+// so that the number of series stays bounded.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		apiRequestsTotal.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+		apiRequestDuration.Observe(time.Since(start).Seconds(), method, route)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func createNotificationChannelHandler(notificationService *service.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.NotificationChannel
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := notificationService.CreateNotificationChannel(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// never expose credentials through the API, not even back to their sender
+		req.Secret = ""
+		req.SMTPPassword = ""
+		c.JSON(http.StatusCreated, req)
+	}
+}
+
+func listNotificationChannelsHandler(notificationService *service.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		channels, err := notificationService.ListNotificationChannels()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// never expose credentials through the API
+		for i := range channels {
+			channels[i].Secret = ""
+			channels[i].SMTPPassword = ""
+		}
+		c.JSON(http.StatusOK, channels)
+	}
+}
+
+func deleteNotificationChannelHandler(notificationService *service.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if err := notificationService.DeleteNotificationChannel(name); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func testNotificationChannelHandler(notificationService *service.NotificationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		err := notificationService.TestNotificationChannel(c.Request.Context(), name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			// the channel exists but the notification couldn't be delivered to it
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
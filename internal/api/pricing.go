@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// setModelPriceRequest is the body of a request to add a price to the catalog.
+// It mirrors the pricing file format, so effective_from may be a plain date.
+type setModelPriceRequest service.PricingFileEntry
+
+// recomputeCostsRequest is the body of a request to recompute historical costs.
+type recomputeCostsRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Model string `json:"model"`
+}
+
+func setModelPriceHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req setModelPriceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		effectiveFrom, err := service.ParseEffectiveFrom(req.EffectiveFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effective_from: " + err.Error()})
+			return
+		}
+		p := &model.ModelPrice{
+			Model:             req.Model,
+			InputPerMTok:      req.InputPerMTok,
+			OutputPerMTok:     req.OutputPerMTok,
+			CacheReadPerMTok:  req.CacheReadPerMTok,
+			CacheWritePerMTok: req.CacheWritePerMTok,
+			EffectiveFrom:     effectiveFrom,
+		}
+		if err := analyticsService.SetModelPrice(p); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, p)
+	}
+}
+
+func listModelPricesHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prices, err := analyticsService.ListModelPrices(c.Query("model"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, prices)
+	}
+}
+
+// deleteModelPriceHandler deletes the model's price with the effective_from given in the query,
+// or all of the model's prices if effective_from is not given.
+func deleteModelPriceHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modelName := strings.TrimPrefix(c.Param("model"), "/")
+		if modelName == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "model name is required"})
+			return
+		}
+		var effectiveFrom *time.Time
+		if v, ok := c.GetQuery("effective_from"); ok {
+			t, err := service.ParseEffectiveFrom(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid effective_from: " + err.Error()})
+				return
+			}
+			effectiveFrom = &t
+		}
+		if err := analyticsService.DeleteModelPrice(modelName, effectiveFrom); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func recomputeCostsHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req recomputeCostsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		from, err := parseReportTime(req.From, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from': " + err.Error()})
+			return
+		}
+		to, err := parseReportTime(req.To, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to': " + err.Error()})
+			return
+		}
+		if !to.After(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'to' must be after 'from'"})
+			return
+		}
+
+		result, err := analyticsService.RecomputeCosts(from, to, req.Model)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
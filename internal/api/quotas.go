@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func createQuotaHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// quotas are enabled unless explicitly disabled in the request
+		req := model.Quota{Enabled: true}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := analyticsService.CreateQuota(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, req)
+	}
+}
+
+// listQuotasHandler returns all quotas along with their consumption in the current period.
+func listQuotasHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quotas, err := analyticsService.ListQuotas()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, quotas)
+	}
+}
+
+func deleteQuotaHandler(analyticsService *service.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if err := analyticsService.DeleteQuota(name); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -1,64 +1,118 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/metrics"
 	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/mark3labs/mcp-go/server"
+	"log/slog"
+	"net"
+	"net/http"
 )
 
 const V0PathPrefix = "/api/v0"
 
 type Server struct {
-	port           string
-	router         *gin.Engine
-	mcpProxyServer *server.MCPServer
-	mcpService     *service.MCPService
-	clientService  *service.ClientService
+	port             string
+	router           *gin.Engine
+	mcpProxyServer   *server.MCPServer
+	mcpService       *service.MCPService
+	clientService    *service.ClientService
+	analyticsService *service.AnalyticsService
+
+	notificationService *service.NotificationService
+	healthService       *service.HealthService
+
+	// httpServer serves the router, baseCtx is the parent of the context of every request it serves
+	httpServer    *http.Server
+	baseCtx       context.Context
+	cancelBaseCtx context.CancelFunc
 }
 
 // NewServer initializes a new Gin server for MCPJungle registry and MCP proxy
-func NewServer(port string, mcpProxyServer *server.MCPServer, mcpService *service.MCPService, clientService *service.ClientService) (*Server, error) {
-	r, err := newRouter(mcpProxyServer, mcpService, clientService)
+func NewServer(port string, mcpProxyServer *server.MCPServer, mcpService *service.MCPService, clientService *service.ClientService, analyticsService *service.AnalyticsService, notificationService *service.NotificationService, healthService *service.HealthService) (*Server, error) {
+	r, err := newRouter(mcpProxyServer, mcpService, clientService, analyticsService, notificationService, healthService)
 	if err != nil {
 		return nil, err
 	}
 	s := &Server{
-		port:           port,
-		router:         r,
-		mcpProxyServer: mcpProxyServer,
-		mcpService:     mcpService,
-		clientService:  clientService,
+		port:             port,
+		router:           r,
+		mcpProxyServer:   mcpProxyServer,
+		mcpService:       mcpService,
+		clientService:    clientService,
+		analyticsService: analyticsService,
+
+		notificationService: notificationService,
+		healthService:       healthService,
+	}
+	s.baseCtx, s.cancelBaseCtx = context.WithCancel(context.Background())
+	s.httpServer = &http.Server{
+		Addr:        ":" + port,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return s.baseCtx },
 	}
 	return s, nil
 }
 
-// Start runs the Gin server (blocking call)
+// Start runs the HTTP server (blocking call)
+// It returns nil once the server is shut down with Shutdown.
 func (s *Server) Start() error {
-	if err := s.router.Run(":" + s.port); err != nil {
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("failed to run the server: %w", err)
 	}
 	return nil
 }
 
+// Shutdown gracefully stops the server.
+// It reports the registry as not ready and stops accepting new MCP sessions, waits for the tool calls in progress
+// to complete, then closes the remaining MCP sessions and HTTP connections.
+// Tool calls still in progress when ctx is done are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.healthService.MarkShuttingDown()
+
+	if err := s.mcpService.Drain(ctx); err != nil {
+		slog.WarnContext(ctx, "tool calls did not complete before the shutdown deadline", logging.Err(err))
+	}
+
+	// end the streams that MCP clients listen to for server messages, which would otherwise keep their connections open
+	s.cancelBaseCtx()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down the server: %w", err)
+	}
+	return nil
+}
+
 // newRouter sets up the Gin router with the MCP proxy server and API endpoints.
-func newRouter(mcpProxyServer *server.MCPServer, mcpService *service.MCPService, clientService *service.ClientService) (*gin.Engine, error) {
-	r := gin.Default()
+func newRouter(mcpProxyServer *server.MCPServer, mcpService *service.MCPService, clientService *service.ClientService, analyticsService *service.AnalyticsService, notificationService *service.NotificationService, healthService *service.HealthService) (*gin.Engine, error) {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(metricsMiddleware())
+	r.Use(tracingMiddleware())
+	r.Use(loggingMiddleware())
 
 	// Enable CORS for web interface
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, "+APIKeyHeader+", "+ClientTypeHeader+", "+tracing.TraceparentHeader+", "+RequestIDHeader)
+		c.Header("Access-Control-Expose-Headers", RequestIDHeader)
+
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
 		}
-		
+
 		c.Next()
 	})
 
+	r.Use(callerMiddleware())
+
 	r.GET(
 		"/health",
 		func(c *gin.Context) {
@@ -66,27 +120,129 @@ func newRouter(mcpProxyServer *server.MCPServer, mcpService *service.MCPService,
 		},
 	)
 
+	// Liveness and readiness probes, eg- for Kubernetes
+	r.GET("/livez", livenessHandler(healthService))
+	r.GET("/readyz", readinessHandler(healthService, mcpService))
+
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(metrics.DefaultRegistry.Handler()))
+
 	// Set up the MCP proxy server on /mcp
 	streamableHttpServer := server.NewStreamableHTTPServer(mcpProxyServer)
-	r.Any("/mcp", gin.WrapH(streamableHttpServer))
+	r.Any("/mcp", rejectNewSessionsMiddleware(healthService), gin.WrapH(streamableHttpServer))
 
 	// Setup API endpoints
 	apiV0 := r.Group(V0PathPrefix)
 	{
 		apiV0.POST("/servers", registerServerHandler(mcpService))
 		apiV0.DELETE("/servers/:name", deregisterServerHandler(mcpService))
-		apiV0.GET("/servers", listServersHandler(mcpService))
+		apiV0.GET("/servers", listServersHandler(mcpService, healthService))
+		apiV0.GET("/servers/health", listServerHealthHandler(healthService))
+		apiV0.GET("/servers/:name/health", getServerHealthHistoryHandler(healthService))
 		apiV0.GET("/tools", listToolsHandler(mcpService))
 		apiV0.POST("/tools/invoke", invokeToolHandler(mcpService))
 		apiV0.GET("/tool", getToolHandler(mcpService))
-		
+
+		// Rate and concurrency limits of upstream servers and tools
+		apiV0.PUT("/limits", setCallLimitHandler(mcpService))
+		apiV0.GET("/limits", listCallLimitsHandler(mcpService))
+		apiV0.DELETE("/limits/:scope/*name", deleteCallLimitHandler(mcpService))
+
+		// Call policies (timeouts) of upstream servers and tools
+		apiV0.PUT("/policies", setCallPolicyHandler(mcpService))
+		apiV0.GET("/policies", listCallPoliciesHandler(mcpService))
+		apiV0.DELETE("/policies/:scope/*name", deleteCallPolicyHandler(mcpService))
+
+		// Capture of the full payloads of tool calls, for inspection
+		apiV0.PUT("/capture", setCallCaptureHandler(mcpService))
+		apiV0.GET("/capture", listCallCapturesHandler(mcpService))
+		apiV0.DELETE("/capture/:server", deleteCallCaptureHandler(mcpService))
+		apiV0.GET("/calls", listCapturedCallsHandler(mcpService))
+		apiV0.GET("/calls/:id", getCapturedCallHandler(mcpService))
+		apiV0.POST("/calls/:id/replay", replayCapturedCallHandler(mcpService))
+
+		// Record/replay mode of upstream servers
+		apiV0.PUT("/cassettes", setCassetteHandler(mcpService))
+		apiV0.GET("/cassettes", listCassettesHandler(mcpService))
+		apiV0.DELETE("/cassettes/:server", deleteCassetteHandler(mcpService))
+
+		// Canary checks, synthetic tool calls made on a schedule
+		apiV0.PUT("/canaries", setCanaryHandler(mcpService))
+		apiV0.GET("/canaries", listCanariesHandler(mcpService))
+		apiV0.DELETE("/canaries/:name", deleteCanaryHandler(mcpService))
+		apiV0.GET("/canaries/:name/runs", listCanaryRunsHandler(mcpService))
+		apiV0.POST("/canaries/:name/run", runCanaryHandler(mcpService))
+
+		// Availability objectives (SLOs) of upstream servers
+		apiV0.PUT("/slos", setServerSLOHandler(healthService))
+		apiV0.GET("/slos", listServerSLOsHandler(healthService))
+		apiV0.DELETE("/slos/:server", deleteServerSLOHandler(healthService))
+		apiV0.GET("/slos/report", getSLOReportHandler(healthService))
+
 		// Client management endpoints
 		apiV0.GET("/clients", listClientsGinHandler(clientService))
 		apiV0.GET("/clients/:clientType/servers", getClientServersGinHandler(clientService))
 		apiV0.POST("/clients/:clientType/servers/:serverId/toggle", toggleServerForClientGinHandler(clientService))
 		apiV0.GET("/clients/:clientType/config", generateClientConfigGinHandler(clientService))
 		apiV0.GET("/client-server-matrix", getClientServerMatrixGinHandler(clientService))
+
+		// Analytics endpoints
+		apiV0.POST("/analytics/usage", recordUsageHandler(analyticsService))
+		apiV0.GET("/analytics/export", exportUsageReportHandler(analyticsService))
+
+		// Quota endpoints
+		apiV0.POST("/quotas", createQuotaHandler(analyticsService))
+		apiV0.GET("/quotas", listQuotasHandler(analyticsService))
+		apiV0.DELETE("/quotas/:name", deleteQuotaHandler(analyticsService))
+
+		// Pricing catalog endpoints
+		apiV0.POST("/pricing", setModelPriceHandler(analyticsService))
+		apiV0.GET("/pricing", listModelPricesHandler(analyticsService))
+		// model names may contain slashes, eg- openai/gpt-4o
+		apiV0.DELETE("/pricing/*model", deleteModelPriceHandler(analyticsService))
+		apiV0.POST("/pricing/recompute", recomputeCostsHandler(analyticsService))
+
+		// Alerting endpoints
+		apiV0.POST("/alert-rules", createAlertRuleHandler(analyticsService))
+		apiV0.GET("/alert-rules", listAlertRulesHandler(analyticsService))
+		apiV0.DELETE("/alert-rules/:name", deleteAlertRuleHandler(analyticsService))
+		apiV0.GET("/alerts", listAlertsHandler(analyticsService))
+		apiV0.POST("/alerts/:id/acknowledge", acknowledgeAlertHandler(analyticsService))
+		apiV0.POST("/alerts/:id/resolve", resolveAlertHandler(analyticsService))
+
+		// Notification channel endpoints
+		apiV0.POST("/notification-channels", createNotificationChannelHandler(notificationService))
+		apiV0.GET("/notification-channels", listNotificationChannelsHandler(notificationService))
+		apiV0.DELETE("/notification-channels/:name", deleteNotificationChannelHandler(notificationService))
+		apiV0.POST("/notification-channels/:name/test", testNotificationChannelHandler(notificationService))
+
+		// Log level of the server, changeable at runtime
+		apiV0.GET("/log-level", getLogLevelHandler())
+		apiV0.PUT("/log-level", setLogLevelHandler())
 	}
 
 	return r, nil
 }
+
+// mcpSessionIDHeader carries the ID of the MCP session that a request to the MCP proxy belongs to
+const mcpSessionIDHeader = "Mcp-Session-Id"
+
+// rejectNewSessionsMiddleware stops the MCP proxy from accepting new MCP sessions once the registry is shutting down,
+// while existing sessions can still complete their tool calls.
+// New sessions start with an initialize request, the only one that carries no session ID.
+func rejectNewSessionsMiddleware(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !healthService.ShuttingDown() {
+			c.Next()
+			return
+		}
+		newSession := c.Request.Method == http.MethodPost && c.GetHeader(mcpSessionIDHeader) == ""
+		// streams for server messages are closed during shutdown, so don't open new ones
+		if newSession || c.Request.Method == http.MethodGet {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "the MCPJungle registry is shutting down"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func listServerHealthHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		health, err := healthService.ListServerHealth()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, health)
+	}
+}
+
+func getServerHealthHistoryHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := service.DefaultHealthHistoryLimit
+		if v := c.Query("limit"); v != "" {
+			l, err := strconv.Atoi(v)
+			if err != nil || l <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit '" + v + "', must be a positive integer"})
+				return
+			}
+			limit = l
+		}
+		history, err := healthService.GetServerHealthHistory(c.Param("name"), limit)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+func livenessHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, healthService.CheckLiveness())
+	}
+}
+
+func readinessHandler(healthService *service.HealthService, mcpService *service.MCPService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := healthService.CheckReadiness(c.Request.Context(), mcpService)
+		if !report.OK() {
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
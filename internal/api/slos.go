@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func setServerSLOHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.ServerSLO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := healthService.SetServerSLO(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	}
+}
+
+func listServerSLOsHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slos, err := healthService.ListServerSLOs()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, slos)
+	}
+}
+
+func deleteServerSLOHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := healthService.DeleteServerSLO(c.Param("server")); err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getSLOReportHandler reports the availability of every server against its SLO.
+// Query params:
+//   - from: start of the window (RFC3339 timestamp or YYYY-MM-DD), inclusive
+//   - to: end of the window (RFC3339 timestamp or YYYY-MM-DD), exclusive.
+//     A date-only value includes the whole day.
+//
+// Without a window, the report covers the current calendar month (UTC).
+func getSLOReportHandler(healthService *service.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0)
+		var err error
+		if v := c.Query("from"); v != "" {
+			if from, err = parseReportTime(v, false); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' query parameter: " + err.Error()})
+				return
+			}
+		}
+		if v := c.Query("to"); v != "" {
+			if to, err = parseReportTime(v, true); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' query parameter: " + err.Error()})
+				return
+			}
+		}
+		if !to.After(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'to' must be after 'from'"})
+			return
+		}
+
+		report, err := healthService.GetSLOReport(from, to, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
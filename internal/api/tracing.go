@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// untracedRoutes are polled by probes and scrapers, tracing them would only drown the traces that matter.
+var untracedRoutes = map[string]bool{
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// tracingMiddleware starts a server span for every request.
+// The span continues the trace of the caller if the request carries a traceparent header.
+func tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if untracedRoutes[route] {
+			c.Next()
+			return
+		}
+		// the GET stream of the MCP proxy stays open for as long as the client is connected
+		if route == "/mcp" && c.Request.Method == http.MethodGet {
+			c.Next()
+			return
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := c.Request.Context()
+		if sc, err := tracing.ParseTraceparent(c.GetHeader(tracing.TraceparentHeader)); err == nil {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+		}
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route, tracing.SpanKindServer,
+			tracing.String("http.request.method", c.Request.Method),
+			tracing.String("http.route", route),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, strconv.Itoa(status))
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package assertion checks the result of a tool call against declared expectations,
+// eg- in the test suites of MCP servers and the canary checks run by the registry.
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Assertions are the conditions that the result of a tool call must meet.
+type Assertions struct {
+	// IsError is whether the result must be a tool error, it isn't checked if unset.
+	IsError *bool `json:"is_error,omitempty" yaml:"is_error,omitempty"`
+
+	// TextContains lists strings that the text content of the result must contain.
+	TextContains []string `json:"text_contains,omitempty" yaml:"text_contains,omitempty"`
+	// TextMatches lists regular expressions that the text content of the result must match.
+	TextMatches []string `json:"text_matches,omitempty" yaml:"text_matches,omitempty"`
+
+	// JSONPath lists conditions on the structured output of the tool, ie- its JSON text content.
+	JSONPath []JSONPathAssertion `json:"json_path,omitempty" yaml:"json_path,omitempty"`
+
+	// MaxLatencyMs is the longest the call may take, 0 means no limit.
+	MaxLatencyMs int `json:"max_latency_ms,omitempty" yaml:"max_latency_ms,omitempty"`
+
+	textMatches []*regexp.Regexp
+}
+
+// JSONPathAssertion is a condition on the values selected by a JSONPath expression, eg- $.items[0].id.
+// Without a condition, the expression must select a value.
+// With Equals or Matches, it must select at least one value and every selected value must meet the condition.
+type JSONPathAssertion struct {
+	Path string `json:"path" yaml:"path"`
+
+	// Exists false requires the expression to select no value
+	Exists *bool `json:"exists,omitempty" yaml:"exists,omitempty"`
+	// Equals is the value that the selected values must be equal to
+	Equals any `json:"equals,omitempty" yaml:"equals,omitempty"`
+	// Matches is a regular expression that the selected values must match, values that aren't strings are matched as JSON
+	Matches string `json:"matches,omitempty" yaml:"matches,omitempty"`
+
+	path    jsonPath
+	matches *regexp.Regexp
+}
+
+// Outcome is the outcome of a tool call that the assertions are checked against.
+type Outcome struct {
+	IsError bool
+	// Texts are the text contents of the result
+	Texts   []string
+	Latency time.Duration
+}
+
+// Validate checks the assertions and compiles their regular expressions and JSONPath expressions.
+// It must be called before Check.
+func (a *Assertions) Validate() error {
+	if a.MaxLatencyMs < 0 {
+		return fmt.Errorf("invalid max_latency_ms %d: must not be negative", a.MaxLatencyMs)
+	}
+	a.textMatches = make([]*regexp.Regexp, len(a.TextMatches))
+	for i, expr := range a.TextMatches {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid text_matches expression '%s': %w", expr, err)
+		}
+		a.textMatches[i] = re
+	}
+	for i := range a.JSONPath {
+		if err := a.JSONPath[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *JSONPathAssertion) validate() error {
+	path, err := parsePath(j.Path)
+	if err != nil {
+		return fmt.Errorf("invalid json_path '%s': %w", j.Path, err)
+	}
+	j.path = path
+	if j.Exists != nil && !*j.Exists && (j.Equals != nil || j.Matches != "") {
+		return fmt.Errorf("json_path %s: exists false can't be combined with equals or matches", j.Path)
+	}
+	if j.Matches != "" {
+		if j.matches, err = regexp.Compile(j.Matches); err != nil {
+			return fmt.Errorf("invalid matches expression '%s' of json_path %s: %w", j.Matches, j.Path, err)
+		}
+	}
+	if j.Equals != nil {
+		if _, err := json.Marshal(j.Equals); err != nil {
+			return fmt.Errorf("invalid equals value of json_path %s: %w", j.Path, err)
+		}
+	}
+	return nil
+}
+
+// Check returns a description of every assertion that the outcome fails, none if it meets all of them.
+func (a *Assertions) Check(o Outcome) []string {
+	var failures []string
+	if a.IsError != nil && o.IsError != *a.IsError {
+		if o.IsError {
+			failures = append(failures, "expected a successful result, got a tool error: "+truncate(strings.Join(o.Texts, "\n")))
+		} else {
+			failures = append(failures, "expected a tool error, got a successful result")
+		}
+	}
+
+	text := strings.Join(o.Texts, "\n")
+	for _, s := range a.TextContains {
+		if !strings.Contains(text, s) {
+			failures = append(failures, fmt.Sprintf("expected the text to contain %q, got %q", s, truncate(text)))
+		}
+	}
+	for _, re := range a.textMatches {
+		if !re.MatchString(text) {
+			failures = append(failures, fmt.Sprintf("expected the text to match %q, got %q", re.String(), truncate(text)))
+		}
+	}
+
+	if len(a.JSONPath) > 0 {
+		doc, ok := structuredOutput(o.Texts)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("expected JSON text content to evaluate json_path against, got %q", truncate(text)))
+		} else {
+			for i := range a.JSONPath {
+				if f := a.JSONPath[i].check(doc); f != "" {
+					failures = append(failures, f)
+				}
+			}
+		}
+	}
+
+	if a.MaxLatencyMs > 0 && o.Latency > time.Duration(a.MaxLatencyMs)*time.Millisecond {
+		failures = append(failures, fmt.Sprintf("expected a latency of at most %dms, took %dms", a.MaxLatencyMs, o.Latency.Milliseconds()))
+	}
+	return failures
+}
+
+func (j *JSONPathAssertion) check(doc any) string {
+	values := j.path.eval(doc)
+	if j.Exists != nil && !*j.Exists {
+		if len(values) > 0 {
+			return fmt.Sprintf("expected %s to select nothing, got %s", j.Path, encode(values[0]))
+		}
+		return ""
+	}
+	if len(values) == 0 {
+		return fmt.Sprintf("expected %s to select a value, got nothing", j.Path)
+	}
+	for _, v := range values {
+		if j.Equals != nil && encode(v) != encode(j.Equals) {
+			return fmt.Sprintf("expected %s to equal %s, got %s", j.Path, encode(j.Equals), truncate(encode(v)))
+		}
+		if j.matches != nil {
+			s, ok := v.(string)
+			if !ok {
+				s = encode(v)
+			}
+			if !j.matches.MatchString(s) {
+				return fmt.Sprintf("expected %s to match %q, got %s", j.Path, j.Matches, truncate(encode(v)))
+			}
+		}
+	}
+	return ""
+}
+
+// structuredOutput returns the first text content that is a JSON object or array.
+func structuredOutput(texts []string) (any, bool) {
+	for _, t := range texts {
+		t = strings.TrimSpace(t)
+		if !strings.HasPrefix(t, "{") && !strings.HasPrefix(t, "[") {
+			continue
+		}
+		var doc any
+		if err := json.Unmarshal([]byte(t), &doc); err == nil {
+			return doc, true
+		}
+	}
+	return nil, false
+}
+
+// encode encodes a value as JSON, so that values decoded from YAML and JSON compare equal, eg- 1 and 1.0
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// truncate shortens long texts quoted in failures.
+func truncate(s string) string {
+	const maxLen = 200
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
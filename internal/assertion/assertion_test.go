@@ -0,0 +1,103 @@
+package assertion
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestParsePath(t *testing.T) {
+	doc := map[string]any{
+		"items": []any{
+			map[string]any{"id": 1.0, "tags": []any{"a", "b"}},
+			map[string]any{"id": 2.0},
+		},
+		"a key": "v",
+	}
+	cases := map[string]int{
+		"$":               1,
+		"$.items[0].id":   1,
+		"$.items[-1].id":  1,
+		"$.items[*].id":   2,
+		"$.items.*.id":    2,
+		"$['a key']":      1,
+		`$["items"][5]`:   0,
+		"$.missing.field": 0,
+		"$.items[0].id.x": 0,
+	}
+	for expr, want := range cases {
+		p, err := parsePath(expr)
+		if err != nil {
+			t.Errorf("failed to parse %s: %v", expr, err)
+			continue
+		}
+		if got := len(p.eval(doc)); got != want {
+			t.Errorf("expected %s to select %d values, got %d", expr, want, got)
+		}
+	}
+
+	for _, expr := range []string{"items", "$..id", "$.items[", "$.items[x]", "$x"} {
+		if _, err := parsePath(expr); err == nil {
+			t.Errorf("expected %s to be invalid", expr)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	a := &Assertions{
+		IsError:      boolPtr(false),
+		TextContains: []string{"forecast"},
+		TextMatches:  []string{`"days":\s*\d+`},
+		JSONPath: []JSONPathAssertion{
+			{Path: "$.days", Equals: 3},
+			{Path: "$.forecast[*].summary", Matches: "^(sunny|rainy)$"},
+			{Path: "$.city"},
+			{Path: "$.error", Exists: boolPtr(false)},
+		},
+		MaxLatencyMs: 100,
+	}
+	if err := a.Validate(); err != nil {
+		t.Fatalf("expected the assertions to be valid: %v", err)
+	}
+
+	pass := Outcome{
+		Texts:   []string{"the forecast", `{"city": "Oslo", "days": 3, "forecast": [{"summary": "sunny"}, {"summary": "rainy"}]}`},
+		Latency: 10 * time.Millisecond,
+	}
+	if failures := a.Check(pass); len(failures) != 0 {
+		t.Errorf("expected the outcome to pass, got %v", failures)
+	}
+
+	fail := Outcome{
+		IsError: true,
+		Texts:   []string{`{"days": 2, "forecast": [{"summary": "snowy"}], "error": "x"}`},
+		Latency: time.Second,
+	}
+	failures := a.Check(fail)
+	// every assertion but the text ones fails
+	if len(failures) != 6 {
+		t.Errorf("expected every failed assertion to be reported, got %d:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+
+	noJSON := a.Check(Outcome{Texts: []string{"plain forecast"}})
+	if !strings.Contains(strings.Join(noJSON, "\n"), "expected JSON text content") {
+		t.Errorf("expected json_path to fail without JSON content, got %v", noJSON)
+	}
+
+	invalid := []*Assertions{
+		{TextMatches: []string{"("}},
+		{JSONPath: []JSONPathAssertion{{Path: "items"}}},
+		{JSONPath: []JSONPathAssertion{{Path: "$.a", Matches: "["}}},
+		{JSONPath: []JSONPathAssertion{{Path: "$.a", Exists: boolPtr(false), Equals: 1}}},
+		{MaxLatencyMs: -1},
+	}
+	for _, a := range invalid {
+		if err := a.Validate(); err == nil {
+			t.Errorf("expected assertions %+v to be invalid", a)
+		}
+	}
+}
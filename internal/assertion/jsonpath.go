@@ -0,0 +1,121 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a step of a JSONPath expression.
+// It selects a member of an object by key, an element of an array by index, or every member or element.
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+type jsonPath []pathSegment
+
+// parsePath parses the subset of JSONPath made of member and index selectors and wildcards,
+// eg- $.items[0].id, $['a key'].values[*], $.items[-1]
+func parsePath(expr string) (jsonPath, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return nil, errors.New("must start with $")
+	}
+	var path jsonPath
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return nil, errors.New("empty member name, recursive descent is not supported")
+			case "*":
+				path = append(path, pathSegment{wildcard: true})
+			default:
+				path = append(path, pathSegment{key: name})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [")
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			seg, err := parseBracketSelector(selector)
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, seg)
+		default:
+			return nil, fmt.Errorf("unexpected '%c', expected . or [", rest[0])
+		}
+	}
+	return path, nil
+}
+
+func parseBracketSelector(selector string) (pathSegment, error) {
+	if selector == "*" {
+		return pathSegment{wildcard: true}, nil
+	}
+	if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+		return pathSegment{key: selector[1 : len(selector)-1]}, nil
+	}
+	i, err := strconv.Atoi(selector)
+	if err != nil {
+		return pathSegment{}, fmt.Errorf("invalid selector [%s]: must be an index, a quoted name or *", selector)
+	}
+	return pathSegment{index: i, isIndex: true}, nil
+}
+
+// eval returns the values that the path selects in a decoded JSON document.
+func (p jsonPath) eval(doc any) []any {
+	values := []any{doc}
+	for _, seg := range p {
+		var next []any
+		for _, v := range values {
+			next = append(next, seg.selectFrom(v)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (s pathSegment) selectFrom(v any) []any {
+	switch v := v.(type) {
+	case map[string]any:
+		if s.wildcard {
+			out := make([]any, 0, len(v))
+			for _, e := range v {
+				out = append(out, e)
+			}
+			return out
+		}
+		if e, ok := v[s.key]; ok && !s.isIndex {
+			return []any{e}
+		}
+	case []any:
+		if s.wildcard {
+			return v
+		}
+		if s.isIndex {
+			i := s.index
+			if i < 0 {
+				i += len(v)
+			}
+			if i >= 0 && i < len(v) {
+				return []any{v[i]}
+			}
+		}
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package db
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // TODO: Turn this into a singleton class.
@@ -17,15 +19,54 @@ import (
 func NewDBConnection(dsn string) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 	if dsn == "" {
-		log.Println("[db] DATABASE_URL not set – falling back to embedded SQLite ./mcp.db")
+		slog.Info("DATABASE_URL not set, falling back to embedded SQLite ./mcp.db")
 		dialector = sqlite.Open("mcp.db?_busy_timeout=5000&_journal_mode=WAL")
 	} else {
 		dialector = postgres.Open(dsn)
 	}
 
-	db, err := gorm.Open(dialector, &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.New(slogWriter{}, logger.Config{
+			SlowThreshold:             slowQueryThreshold,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := registerMetricsCallbacks(db); err != nil {
+		return nil, err
+	}
+	if err := registerTracingCallbacks(db); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the database connection: %w", err)
+	}
+	registerPoolMetrics(sqlDB)
 	return db, nil
 }
+
+// slowQueryThreshold is the duration beyond which queries are logged as slow
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogWriter writes the logs of gorm, ie, failed and slow queries, as warnings of the default logger.
+type slogWriter struct{}
+
+func (slogWriter) Printf(format string, args ...any) {
+	slog.Warn("database query", slog.String("detail", fmt.Sprintf(format, args...)))
+}
+
+// Close closes the database connection.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get the database connection: %w", err)
+	}
+	if err := sqlDB.Close(); err != nil {
+		return fmt.Errorf("failed to close the database connection: %w", err)
+	}
+	return nil
+}
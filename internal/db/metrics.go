@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/metrics"
+	"gorm.io/gorm"
+)
+
+const (
+	queryStartCallback   = "mcpjungle:query_start"
+	queryObserveCallback = "mcpjungle:query_observe"
+)
+
+var queryDuration = metrics.DefaultRegistry.NewHistogram(
+	"mcpjungle_db_query_duration_seconds",
+	"Duration of database queries, by operation and table.",
+	[]float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	"operation", "table",
+)
+
+// registerMetricsCallbacks times every query made through the connection.
+func registerMetricsCallbacks(db *gorm.DB) error {
+	start := func(tx *gorm.DB) {
+		tx.InstanceSet(queryStartCallback, time.Now())
+	}
+	observe := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(queryStartCallback)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			if table == "" {
+				table = "none"
+			}
+			queryDuration.Observe(time.Since(v.(time.Time)).Seconds(), operation, table)
+		}
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register(queryStartCallback, start),
+		cb.Create().After("gorm:create").Register(queryObserveCallback, observe("create")),
+		cb.Query().Before("gorm:query").Register(queryStartCallback, start),
+		cb.Query().After("gorm:query").Register(queryObserveCallback, observe("query")),
+		cb.Update().Before("gorm:update").Register(queryStartCallback, start),
+		cb.Update().After("gorm:update").Register(queryObserveCallback, observe("update")),
+		cb.Delete().Before("gorm:delete").Register(queryStartCallback, start),
+		cb.Delete().After("gorm:delete").Register(queryObserveCallback, observe("delete")),
+		cb.Row().Before("gorm:row").Register(queryStartCallback, start),
+		cb.Row().After("gorm:row").Register(queryObserveCallback, observe("row")),
+		cb.Raw().Before("gorm:raw").Register(queryStartCallback, start),
+		cb.Raw().After("gorm:raw").Register(queryObserveCallback, observe("raw")),
+	} {
+		if err != nil {
+			return fmt.Errorf("failed to register database metrics callbacks: %w", err)
+		}
+	}
+	return nil
+}
+
+// registerPoolMetrics exposes the state of the connection pool of the database, read when the metrics are scraped.
+func registerPoolMetrics(sqlDB *sql.DB) {
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_db_connections",
+		"Connections of the database connection pool, by state (in_use or idle).",
+		[]string{"state"},
+		func(emit func(float64, ...string)) {
+			stats := sqlDB.Stats()
+			emit(float64(stats.InUse), "in_use")
+			emit(float64(stats.Idle), "idle")
+		},
+	)
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_db_max_open_connections",
+		"Maximum number of open connections of the database connection pool, 0 if unlimited.",
+		nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(sqlDB.Stats().MaxOpenConnections))
+		},
+	)
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_db_connection_waits_total",
+		"Queries that had to wait for a connection because the database connection pool was exhausted.",
+		nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(sqlDB.Stats().WaitCount))
+		},
+	)
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_db_connection_wait_seconds_total",
+		"Total time spent waiting for a connection of the database connection pool.",
+		nil,
+		func(emit func(float64, ...string)) {
+			emit(sqlDB.Stats().WaitDuration.Seconds())
+		},
+	)
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	spanStartCallback = "mcpjungle:span_start"
+	spanEndCallback   = "mcpjungle:span_end"
+)
+
+// registerTracingCallbacks traces the queries made as part of a trace, eg- while serving a traced request.
+func registerTracingCallbacks(db *gorm.DB) error {
+	start := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if ctx == nil {
+				return
+			}
+			table := tx.Statement.Table
+			name := "db." + operation
+			if table != "" {
+				name += " " + table
+			}
+			_, span := tracing.StartChild(ctx, name, tracing.SpanKindClient,
+				tracing.String("db.system", tx.Dialector.Name()),
+				tracing.String("db.operation.name", operation),
+				tracing.String("db.collection.name", table),
+			)
+			if span != nil {
+				tx.InstanceSet(spanStartCallback, span)
+			}
+		}
+	}
+	end := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(spanStartCallback)
+		if !ok {
+			return
+		}
+		span := v.(*tracing.Span)
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+		}
+		span.End()
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register(spanStartCallback, start("create")),
+		cb.Create().After("gorm:create").Register(spanEndCallback, end),
+		cb.Query().Before("gorm:query").Register(spanStartCallback, start("query")),
+		cb.Query().After("gorm:query").Register(spanEndCallback, end),
+		cb.Update().Before("gorm:update").Register(spanStartCallback, start("update")),
+		cb.Update().After("gorm:update").Register(spanEndCallback, end),
+		cb.Delete().Before("gorm:delete").Register(spanStartCallback, start("delete")),
+		cb.Delete().After("gorm:delete").Register(spanEndCallback, end),
+		cb.Row().Before("gorm:row").Register(spanStartCallback, start("row")),
+		cb.Row().After("gorm:row").Register(spanEndCallback, end),
+		cb.Raw().Before("gorm:raw").Register(spanStartCallback, start("raw")),
+		cb.Raw().After("gorm:raw").Register(spanEndCallback, end),
+	} {
+		if err != nil {
+			return fmt.Errorf("failed to register database tracing callbacks: %w", err)
+		}
+	}
+	return nil
+}
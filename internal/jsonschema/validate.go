@@ -0,0 +1,597 @@
+// Package jsonschema validates JSON values against a subset of JSON Schema (draft 2020-12),
+// eg- the arguments of tool calls against the input schemas of MCP tools.
+//
+// The supported keywords are type, enum, const, the object keywords (properties, patternProperties,
+// required, additionalProperties, minProperties, maxProperties), the array keywords (items,
+// prefixItems, minItems, maxItems, uniqueItems), the numeric ranges (minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf), the string keywords (minLength, maxLength, pattern),
+// the applicators allOf, anyOf, oneOf and not, and local references ($ref to #/...).
+// Other keywords are ignored, and so are malformed ones, so that sloppy schemas don't reject valid values.
+package jsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxRefDepth bounds the references followed without descending into the value, which guards against cyclic references.
+const maxRefDepth = 32
+
+// maxEvaluations bounds the subschemas evaluated to validate a value. maxRefDepth alone doesn't bound the work:
+// applicators can multiply it, eg- {"allOf": [{"$ref": "#"}, {"$ref": "#"}]} doubles it at every reference followed.
+const maxEvaluations = 100_000
+
+// ctxCheckInterval is the number of evaluations between two checks of the cancellation of the validation.
+const ctxCheckInterval = 1024
+
+// ErrSchemaTooComplex is returned when validating a value against a schema takes more than maxEvaluations evaluations.
+var ErrSchemaTooComplex = errors.New("schema is too complex to validate against")
+
+// maxListedEnumValues is the number of allowed values listed by the violation of an enum.
+const maxListedEnumValues = 20
+
+var knownTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+}
+
+// Violation is a way in which a value doesn't conform to a schema.
+type Violation struct {
+	// Path locates the offending value in JSONPath notation, eg- $.items[0].name
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Validate checks a value decoded from JSON against a schema and returns all the violations, nil if the value conforms.
+// The schema is a JSON Schema decoded from JSON, ie- a map[string]any or a bool.
+// A value that is too costly to check against the schema is reported as conforming, see ValidateContext.
+func Validate(schema, value any) []Violation {
+	violations, _ := ValidateContext(context.Background(), schema, value)
+	return violations
+}
+
+// ValidateContext is like Validate, but it gives up when ctx is cancelled, returning ctx's error,
+// or when the schema is too complex to check the value against, returning ErrSchemaTooComplex.
+// The violations found so far are not returned in either case.
+func ValidateContext(ctx context.Context, schema, value any) ([]Violation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	v := &validator{root: schema, budget: &budget{ctx: ctx, remaining: maxEvaluations}}
+	v.validate(schema, value, "$", 0)
+	if v.budget.err != nil {
+		return nil, v.budget.err
+	}
+	return v.violations, nil
+}
+
+type validator struct {
+	root       any
+	violations []Violation
+	// budget is shared with the validators of the trials, so that it bounds the whole validation
+	budget *budget
+}
+
+// budget bounds the work of a validation.
+type budget struct {
+	ctx       context.Context
+	remaining int
+	// err is the reason why the validation stopped, if it did
+	err error
+}
+
+// spend accounts for the evaluation of a subschema, it returns false once the validation must stop.
+func (b *budget) spend() bool {
+	if b.err != nil {
+		return false
+	}
+	b.remaining--
+	if b.remaining < 0 {
+		b.err = ErrSchemaTooComplex
+		return false
+	}
+	if b.remaining%ctxCheckInterval == 0 {
+		if err := b.ctx.Err(); err != nil {
+			b.err = err
+			return false
+		}
+	}
+	return true
+}
+
+func (v *validator) report(path, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks a value against a (sub)schema, refDepth counts the references followed at the current path.
+func (v *validator) validate(schema, value any, path string, refDepth int) {
+	if !v.budget.spend() {
+		return
+	}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.report(path, "no value is allowed here")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(s, value, path, refDepth)
+	}
+}
+
+func (v *validator) validateObjectSchema(s map[string]any, value any, path string, refDepth int) {
+	if ref, ok := s["$ref"].(string); ok && refDepth < maxRefDepth {
+		if target, ok := v.resolveRef(ref); ok {
+			v.validate(target, value, path, refDepth+1)
+		}
+	}
+
+	// the keywords that apply to other types of values are meaningless once the type is wrong,
+	// so they are only checked for values of an allowed type
+	if !v.checkType(s, value, path) {
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		allowed := false
+		for _, e := range enum {
+			if equal(e, value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			v.report(path, "must be one of %s", formatValues(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.report(path, "must be %s", formatValue(c))
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.checkObject(s, val, path)
+	case []any:
+		v.checkArray(s, val, path)
+	case string:
+		v.checkString(s, val, path)
+	default:
+		if n, ok := toNumber(value); ok {
+			v.checkNumber(s, n, path)
+		}
+	}
+
+	v.checkApplicators(s, value, path, refDepth)
+}
+
+// resolveRef resolves a reference to a subschema of the root schema, eg- #/$defs/address.
+// References to other documents can't be resolved, so they are ignored.
+func (v *validator) resolveRef(ref string) (any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	pointer := strings.TrimPrefix(ref, "#")
+	target := v.root
+	if pointer == "" {
+		return target, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch t := target.(type) {
+		case map[string]any:
+			next, ok := t[token]
+			if !ok {
+				return nil, false
+			}
+			target = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			target = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return target, true
+}
+
+// checkType reports a value whose type isn't allowed by the schema, returning whether the type is allowed.
+func (v *validator) checkType(s map[string]any, value any, path string) bool {
+	var allowed []string
+	switch t := s["type"].(type) {
+	case string:
+		if knownTypes[t] {
+			allowed = []string{t}
+		}
+	case []any:
+		for _, e := range t {
+			if name, ok := e.(string); ok && knownTypes[name] {
+				allowed = append(allowed, name)
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+
+	actual := typeOf(value)
+	for _, t := range allowed {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	v.report(path, "expected %s, got %s", strings.Join(allowed, " or "), actual)
+	return false
+}
+
+func (v *validator) checkObject(s map[string]any, obj map[string]any, path string) {
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, present := obj[name]; !present {
+				v.report(propertyPath(path, name), "required property is missing")
+			}
+		}
+	}
+	if n, ok := toInt(s["minProperties"]); ok && len(obj) < n {
+		v.report(path, "must have at least %d properties, got %d", n, len(obj))
+	}
+	if n, ok := toInt(s["maxProperties"]); ok && len(obj) > n {
+		v.report(path, "must have at most %d properties, got %d", n, len(obj))
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	patternProperties, _ := s["patternProperties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+
+	// properties are checked in a stable order so that the violations are reported deterministically
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		matched := false
+		if propSchema, ok := properties[name]; ok {
+			v.validate(propSchema, obj[name], propertyPath(path, name), 0)
+			matched = true
+		}
+		for pattern, propSchema := range patternProperties {
+			if re := compilePattern(pattern); re != nil && re.MatchString(name) {
+				v.validate(propSchema, obj[name], propertyPath(path, name), 0)
+				matched = true
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			v.report(propertyPath(path, name), "unexpected property, %s", describeAllowedProperties(properties))
+			continue
+		}
+		v.validate(additional, obj[name], propertyPath(path, name), 0)
+	}
+}
+
+func (v *validator) checkArray(s map[string]any, arr []any, path string) {
+	if n, ok := toInt(s["minItems"]); ok && len(arr) < n {
+		v.report(path, "must have at least %d items, got %d", n, len(arr))
+	}
+	if n, ok := toInt(s["maxItems"]); ok && len(arr) > n {
+		v.report(path, "must have at most %d items, got %d", n, len(arr))
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					v.report(path, "items must be unique, items %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	// prefixItems constrain the leading items, items constrains the rest
+	prefix, _ := s["prefixItems"].([]any)
+	for i, item := range arr {
+		itemPath := path + "[" + strconv.Itoa(i) + "]"
+		if i < len(prefix) {
+			v.validate(prefix[i], item, itemPath, 0)
+		} else if items, ok := s["items"]; ok {
+			v.validate(items, item, itemPath, 0)
+		}
+	}
+}
+
+func (v *validator) checkString(s map[string]any, str string, path string) {
+	length := utf8.RuneCountInString(str)
+	if n, ok := toInt(s["minLength"]); ok && length < n {
+		v.report(path, "must be at least %d characters long, got %d", n, length)
+	}
+	if n, ok := toInt(s["maxLength"]); ok && length > n {
+		v.report(path, "must be at most %d characters long, got %d", n, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if re := compilePattern(pattern); re != nil && !re.MatchString(str) {
+			v.report(path, "must match the pattern %s", strconv.Quote(pattern))
+		}
+	}
+}
+
+func (v *validator) checkNumber(s map[string]any, n float64, path string) {
+	if min, ok := toNumber(s["minimum"]); ok && n < min {
+		v.report(path, "must be greater than or equal to %s, got %s", formatNumber(min), formatNumber(n))
+	}
+	if max, ok := toNumber(s["maximum"]); ok && n > max {
+		v.report(path, "must be less than or equal to %s, got %s", formatNumber(max), formatNumber(n))
+	}
+	if min, ok := toNumber(s["exclusiveMinimum"]); ok && n <= min {
+		v.report(path, "must be greater than %s, got %s", formatNumber(min), formatNumber(n))
+	}
+	if max, ok := toNumber(s["exclusiveMaximum"]); ok && n >= max {
+		v.report(path, "must be less than %s, got %s", formatNumber(max), formatNumber(n))
+	}
+	if m, ok := toNumber(s["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.report(path, "must be a multiple of %s, got %s", formatNumber(m), formatNumber(n))
+		}
+	}
+}
+
+func (v *validator) checkApplicators(s map[string]any, value any, path string, refDepth int) {
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path, refDepth)
+		}
+	}
+
+	if anyOf, ok := s["anyOf"].([]any); ok && len(anyOf) > 0 {
+		var failures [][]Violation
+		for _, sub := range anyOf {
+			violations := v.trial(sub, value, path, refDepth)
+			if len(violations) == 0 {
+				failures = nil
+				break
+			}
+			failures = append(failures, violations)
+		}
+		if failures != nil {
+			v.report(path, "must match at least one of the allowed schemas: %s", describeAlternatives(path, failures))
+		}
+	}
+
+	if oneOf, ok := s["oneOf"].([]any); ok && len(oneOf) > 0 {
+		var failures [][]Violation
+		var matched []int
+		for i, sub := range oneOf {
+			violations := v.trial(sub, value, path, refDepth)
+			if len(violations) == 0 {
+				matched = append(matched, i+1)
+			} else {
+				failures = append(failures, violations)
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			v.report(path, "must match exactly one of the allowed schemas: %s", describeAlternatives(path, failures))
+		case len(matched) > 1:
+			v.report(path, "must match exactly one of the allowed schemas, but matches schemas %s", joinInts(matched))
+		}
+	}
+
+	if not, ok := s["not"]; ok && len(v.trial(not, value, path, refDepth)) == 0 {
+		v.report(path, "must not match the disallowed schema")
+	}
+}
+
+// trial validates a value against a subschema without reporting its violations.
+func (v *validator) trial(schema, value any, path string, refDepth int) []Violation {
+	sub := &validator{root: v.root, budget: v.budget}
+	sub.validate(schema, value, path, refDepth)
+	return sub.violations
+}
+
+// describeAlternatives describes why a value doesn't match any of the alternatives of anyOf or oneOf.
+func describeAlternatives(path string, failures [][]Violation) string {
+	parts := make([]string, len(failures))
+	for i, violations := range failures {
+		msgs := make([]string, len(violations))
+		for j, violation := range violations {
+			msgs[j] = violation.Message
+			if violation.Path != path {
+				msgs[j] = violation.String()
+			}
+		}
+		parts[i] = fmt.Sprintf("(%d) %s", i+1, strings.Join(msgs, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func describeAllowedProperties(properties map[string]any) string {
+	if len(properties) == 0 {
+		return "no properties are allowed"
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "the allowed properties are " + strings.Join(names, ", ")
+}
+
+// typeOf returns the JSON Schema type of a value decoded from JSON, whole numbers are integers.
+func typeOf(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		if n, ok := toNumber(val); ok {
+			if n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toInt(value any) (int, bool) {
+	n, ok := toNumber(value)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// equal compares values decoded from JSON, numbers are equal if their values are.
+func equal(a, b any) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+var patterns sync.Map
+
+// compilePattern compiles and caches a regular expression of a schema,
+// it returns nil for patterns that aren't supported by Go's regexp syntax.
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	patterns.Store(pattern, re)
+	return re
+}
+
+// propertyPath appends a property to a JSONPath, using the bracket notation for names that aren't identifiers.
+func propertyPath(path, name string) string {
+	if isIdentifier(name) {
+		return path + "." + name
+	}
+	return path + "['" + strings.ReplaceAll(name, "'", `\'`) + "']"
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func formatValue(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func formatValues(values []any) string {
+	n := min(len(values), maxListedEnumValues)
+	parts := make([]string, n)
+	for i := range n {
+		parts[i] = formatValue(values[i])
+	}
+	s := strings.Join(parts, ", ")
+	if len(values) > n {
+		s += fmt.Sprintf(" (and %d more)", len(values)-n)
+	}
+	return s
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, " and ")
+}
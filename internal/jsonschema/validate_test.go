@@ -0,0 +1,165 @@
+package jsonschema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustDecode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("failed to decode %s: %v", s, err)
+	}
+	return v
+}
+
+const forecastSchema = `{
+	"type": "object",
+	"properties": {
+		"city": {"type": "string", "minLength": 2, "pattern": "^[A-Z]"},
+		"days": {"type": "integer", "minimum": 1, "maximum": 14},
+		"units": {"enum": ["metric", "imperial"]},
+		"hours": {"type": "array", "items": {"type": "integer", "exclusiveMaximum": 24}, "maxItems": 3, "uniqueItems": true},
+		"location": {"$ref": "#/$defs/location"},
+		"note": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+	},
+	"required": ["city", "days"],
+	"additionalProperties": false,
+	"$defs": {
+		"location": {
+			"type": "object",
+			"properties": {"lat": {"type": "number"}, "lon": {"type": "number"}},
+			"required": ["lat", "lon"]
+		}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema := mustDecode(t, forecastSchema)
+
+	valid := []string{
+		`{"city": "London", "days": 3}`,
+		`{"city": "London", "days": 3.0, "units": "metric", "hours": [9, 18], "note": null}`,
+		`{"city": "London", "days": 14, "location": {"lat": 51.5, "lon": -0.12}, "note": "hi"}`,
+	}
+	for _, args := range valid {
+		if violations := Validate(schema, mustDecode(t, args)); len(violations) != 0 {
+			t.Errorf("expected %s to be valid, got %v", args, violations)
+		}
+	}
+
+	cases := map[string][]string{
+		`{}`: {
+			"$.city: required property is missing",
+			"$.days: required property is missing",
+		},
+		`{"city": "l", "days": 1.5}`: {
+			"$.city: must be at least 2 characters long, got 1",
+			`$.city: must match the pattern "^[A-Z]"`,
+			"$.days: expected integer, got number",
+		},
+		`{"city": "London", "days": 0, "units": "kelvin", "extra": true}`: {
+			"$.days: must be greater than or equal to 1, got 0",
+			"$.extra: unexpected property, the allowed properties are city, days, hours, location, note, units",
+			`$.units: must be one of "metric", "imperial"`,
+		},
+		`{"city": "London", "days": 3, "hours": [9, 24, 9, "x"]}`: {
+			"$.hours: must have at most 3 items, got 4",
+			"$.hours: items must be unique, items 0 and 2 are equal",
+			"$.hours[1]: must be less than 24, got 24",
+			"$.hours[3]: expected integer, got string",
+		},
+		`{"city": "London", "days": 3, "location": {"lat": "51.5"}, "note": 1}`: {
+			"$.location.lon: required property is missing",
+			"$.location.lat: expected number, got string",
+			"$.note: must match at least one of the allowed schemas: (1) expected string, got integer; (2) expected null, got integer",
+		},
+		`"London"`: {
+			"$: expected object, got string",
+		},
+	}
+	for args, want := range cases {
+		violations := Validate(schema, mustDecode(t, args))
+		got := make([]string, len(violations))
+		for i, v := range violations {
+			got[i] = v.String()
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected violations of %s\ngot:\n%s\nwant:\n%s", args, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
+
+func TestValidateSloppySchemas(t *testing.T) {
+	// unknown types and keywords, unsupported patterns and unresolvable references don't reject anything
+	schema := mustDecode(t, `{
+		"type": "object",
+		"properties": {
+			"a": {"type": "any"},
+			"b": {"type": "string", "pattern": "(?<=x)y", "format": "uuid"},
+			"c": {"$ref": "https://example.com/schema.json"},
+			"d": {"$ref": "#/definitions/missing"}
+		},
+		"required": "a"
+	}`)
+	args := `{"a": [1], "b": "not a uuid", "c": 1, "d": {}}`
+	if violations := Validate(schema, mustDecode(t, args)); len(violations) != 0 {
+		t.Errorf("expected %s to be valid, got %v", args, violations)
+	}
+
+	// a cyclic reference doesn't loop forever
+	cyclic := mustDecode(t, `{"$defs": {"a": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`)
+	if violations := Validate(cyclic, mustDecode(t, `{}`)); len(violations) != 0 {
+		t.Errorf("expected a cyclic reference to be ignored, got %v", violations)
+	}
+
+	if violations := Validate(false, mustDecode(t, `{}`)); len(violations) != 1 {
+		t.Errorf("expected the false schema to reject any value, got %v", violations)
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	schema := mustDecode(t, `{"oneOf": [{"type": "number"}, {"type": "integer"}, {"type": "string", "minLength": 3}]}`)
+	cases := map[string]string{
+		`"abc"`: "",
+		`1.5`:   "",
+		`2`:     "$: must match exactly one of the allowed schemas, but matches schemas 1 and 2",
+		`"a"`: "$: must match exactly one of the allowed schemas: " +
+			"(1) expected number, got string; (2) expected integer, got string; (3) must be at least 3 characters long, got 1",
+	}
+	for value, want := range cases {
+		var got []string
+		for _, v := range Validate(schema, mustDecode(t, value)) {
+			got = append(got, v.String())
+		}
+		if strings.Join(got, "\n") != want {
+			t.Errorf("unexpected violations of %s: got %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestValidateBoundsTheWork(t *testing.T) {
+	// every reference followed doubles the work, the depth bound of references alone would allow 2^32 evaluations
+	bomb := mustDecode(t, `{"allOf": [{"$ref": "#"}, {"$ref": "#"}]}`)
+	start := time.Now()
+	if _, err := ValidateContext(context.Background(), bomb, mustDecode(t, `{}`)); !errors.Is(err, ErrSchemaTooComplex) {
+		t.Errorf("expected the validation to give up with ErrSchemaTooComplex, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the validation to give up quickly, took %s", elapsed)
+	}
+	if violations := Validate(bomb, mustDecode(t, `{}`)); violations != nil {
+		t.Errorf("expected a value that can't be checked to be reported as conforming, got %v", violations)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ValidateContext(ctx, mustDecode(t, forecastSchema), mustDecode(t, `{}`)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled validation to fail with context.Canceled, got %v", err)
+	}
+}
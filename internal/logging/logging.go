@@ -0,0 +1,156 @@
+// Package logging sets up the structured logger of the registry.
+// Log records carry the fields of the request they are logged for, eg- its request ID and the server and tool it calls,
+// which are attached to the request's context with With and added by the logger to every record logged with that context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+)
+
+// The keys of the fields that identify what a record is about, shared by all log records.
+const (
+	KeyRequestID = "request_id"
+	KeyServer    = "server"
+	KeyTool      = "tool"
+	KeySession   = "session_id"
+	KeyTraceID   = "trace_id"
+	KeyError     = "error"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// level is the minimum level of the records logged, it can be changed at runtime with SetLevel.
+var level = new(slog.LevelVar)
+
+// ParseLevel parses a log level: debug, info, warn (or warning) or error.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level '%s': must be one of debug, info, warn, error", s)
+	}
+}
+
+// Level returns the minimum level of the records logged.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// LevelName returns the name of the minimum level of the records logged, as accepted by ParseLevel.
+func LevelName() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// SetLevel changes the minimum level of the records logged.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Configure makes a logger that writes records in the format to w the default logger.
+// The standard library's log package writes through it too, at the info level.
+func Configure(w io.Writer, format string, l slog.Level) error {
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format '%s': must be %s or %s", format, FormatText, FormatJSON)
+	}
+	level.Set(l)
+	slog.SetDefault(slog.New(&contextHandler{Handler: h}))
+	// slog.SetDefault strips the flags of the log package, which would otherwise duplicate the timestamp
+	log.SetFlags(0)
+	return nil
+}
+
+type attrsKey struct{}
+
+// With returns a context whose log records carry the attributes, in addition to those of ctx.
+// An attribute replaces any attribute of ctx with the same key.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := attrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	for _, a := range existing {
+		if !hasKey(attrs, a.Key) {
+			merged = append(merged, a)
+		}
+	}
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// RequestID returns the ID of the request that ctx belongs to, or an empty string.
+func RequestID(ctx context.Context) string {
+	for _, a := range attrsFromContext(ctx) {
+		if a.Key == KeyRequestID {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+// Err is the attribute of an error.
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the attributes of the context of a record to it, along with the ID of its trace.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(attrsFromContext(ctx)...)
+	if ctx != nil {
+		if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String(KeyTraceID, sc.TraceID.String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		" error ": slog.LevelError,
+	}
+	for s, want := range cases {
+		if got, err := ParseLevel(s); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("expected an unknown level to be rejected")
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	var out bytes.Buffer
+	if err := Configure(&out, FormatJSON, slog.LevelInfo); err != nil {
+		t.Fatalf("failed to configure logging: %v", err)
+	}
+
+	sc, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), sc)
+	ctx = With(ctx, slog.String(KeyRequestID, "req-1"), slog.String(KeyServer, "github"))
+	ctx = With(ctx, slog.String(KeyServer, "weather"), slog.String(KeyTool, "forecast"))
+	if RequestID(ctx) != "req-1" {
+		t.Fatalf("expected the request ID of the context, got '%s'", RequestID(ctx))
+	}
+
+	slog.DebugContext(ctx, "dropped")
+	slog.ErrorContext(ctx, "tool call failed", Err(errors.New("boom")))
+
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single JSON record, got %s", out.String())
+	}
+	want := map[string]any{
+		"msg":        "tool call failed",
+		"level":      "ERROR",
+		KeyRequestID: "req-1",
+		KeyServer:    "weather",
+		KeyTool:      "forecast",
+		KeyTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		KeyError:     "boom",
+	}
+	for k, v := range want {
+		if record[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, record[k])
+		}
+	}
+
+	// the level changes at runtime
+	out.Reset()
+	SetLevel(slog.LevelDebug)
+	slog.DebugContext(ctx, "kept")
+	if !strings.Contains(out.String(), `"msg":"kept"`) || LevelName() != "debug" {
+		t.Fatalf("expected debug records to be logged once the level is debug, got %s", out.String())
+	}
+}
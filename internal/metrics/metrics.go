@@ -0,0 +1,369 @@
+// Package metrics keeps counters, gauges and histograms of the registry and
+// exposes them to Prometheus in its text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxSeries is the maximum number of label combinations of a metric.
+// Observations with new label combinations beyond it are folded into a single series
+// whose labels are all OverflowLabelValue, so that unexpected label values can't blow up the scrapes.
+const MaxSeries = 1000
+
+// OverflowLabelValue is the value of every label of the series that collects observations beyond MaxSeries.
+const OverflowLabelValue = "_other"
+
+// DefBuckets are the default histogram buckets, in seconds, suitable for the latency of network calls.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// DefaultRegistry is the registry that the metrics of MCPJungle are registered with.
+var DefaultRegistry = NewRegistry()
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Registry holds metrics and writes them in the Prometheus text exposition format.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]family
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// register adds a metric to the registry.
+// Registering the same name twice is a programming error unless replace is set.
+func (r *Registry) register(name string, f family, replace bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; ok && !replace {
+		panic(fmt.Sprintf("metric %s is already registered", name))
+	}
+	r.families[name] = f
+}
+
+// Write writes all the metrics of the registry in the Prometheus text exposition format, ordered by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the metrics of the registry to Prometheus.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(w)
+	})
+}
+
+// vec holds the series of a metric, keyed by their label values.
+type vec struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	// value is the value of counters and gauges
+	value float64
+	// buckets, sum and count are the state of histograms, buckets are not cumulative
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newVec(name, help, typ string, labels []string) *vec {
+	return &vec{name: name, help: help, typ: typ, labels: labels, series: make(map[string]*series)}
+}
+
+// get returns the series of the label values, creating it if needed. The lock must be held.
+func (v *vec) get(labelValues []string, newSeries func([]string) *series) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if s, ok := v.series[key]; ok {
+		return s
+	}
+	if len(v.series) >= MaxSeries {
+		labelValues = make([]string, len(v.labels))
+		for i := range labelValues {
+			labelValues[i] = OverflowLabelValue
+		}
+		key = strings.Join(labelValues, "\xff")
+		if s, ok := v.series[key]; ok {
+			return s
+		}
+	}
+	s := newSeries(append([]string(nil), labelValues...))
+	v.series[key] = s
+	return s
+}
+
+func newScalarSeries(labelValues []string) *series {
+	return &series{labelValues: labelValues}
+}
+
+// sorted returns the series ordered by label values. The lock must be held.
+func (v *vec) sorted() []*series {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]*series, len(keys))
+	for i, k := range keys {
+		out[i] = v.series[k]
+	}
+	return out
+}
+
+func (v *vec) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.typ)
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeHeader(w)
+	for _, s := range v.sorted() {
+		writeSample(w, v.name, v.labels, s.labelValues, "", "", s.value)
+	}
+}
+
+// Counter is a metric whose value only goes up.
+type Counter struct {
+	v *vec
+}
+
+// NewCounter registers a counter with the given label names.
+// A counter without labels is exposed from the start, with a value of 0.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{v: newVec(name, help, typeCounter, labels)}
+	if len(labels) == 0 {
+		c.v.get(nil, newScalarSeries)
+	}
+	r.register(name, c.v, false)
+	return c
+}
+
+// Inc adds 1 to the series of the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative delta to the series of the label values.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.v.name))
+	}
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	c.v.get(labelValues, newScalarSeries).value += delta
+}
+
+// Gauge is a metric whose value can go up and down.
+type Gauge struct {
+	v *vec
+}
+
+// NewGauge registers a gauge with the given label names.
+// A gauge without labels is exposed from the start, with a value of 0.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{v: newVec(name, help, typeGauge, labels)}
+	if len(labels) == 0 {
+		g.v.get(nil, newScalarSeries)
+	}
+	r.register(name, g.v, false)
+	return g
+}
+
+// Set sets the series of the label values to value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.get(labelValues, newScalarSeries).value = value
+}
+
+// Add adds delta, which may be negative, to the series of the label values.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.get(labelValues, newScalarSeries).value += delta
+}
+
+// Inc adds 1 to the series of the label values.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts 1 from the series of the label values.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Histogram counts observations, eg- latencies, in buckets.
+type Histogram struct {
+	v *vec
+	// buckets are the upper bounds of the buckets, in increasing order, without +Inf
+	buckets []float64
+}
+
+// NewHistogram registers a histogram with the given buckets and label names.
+// A histogram without labels is exposed from the start, empty.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{v: newVec(name, help, typeHistogram, labels), buckets: buckets}
+	if len(labels) == 0 {
+		h.v.get(nil, h.newSeries)
+	}
+	r.register(name, h, false)
+	return h
+}
+
+// Observe adds an observation to the series of the label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.get(labelValues, h.newSeries)
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.buckets[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *Histogram) newSeries(labelValues []string) *series {
+	return &series{labelValues: labelValues, buckets: make([]uint64, len(h.buckets))}
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	h.v.writeHeader(w)
+	for _, s := range h.v.sorted() {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.buckets[i]
+			writeSample(w, h.v.name+"_bucket", h.v.labels, s.labelValues, "le", formatValue(upper), float64(cumulative))
+		}
+		writeSample(w, h.v.name+"_bucket", h.v.labels, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(w, h.v.name+"_sum", h.v.labels, s.labelValues, "", "", s.sum)
+		writeSample(w, h.v.name+"_count", h.v.labels, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+// CollectFunc reports the current values of a metric through emit, once per label combination.
+type CollectFunc func(emit func(value float64, labelValues ...string))
+
+// collected is a metric whose values are collected when the registry is scraped.
+type collected struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	collect CollectFunc
+}
+
+// NewGaugeFunc registers a gauge whose values are collected when the registry is scraped.
+// It replaces any metric registered with the same name, so that it can be registered again with fresh state.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect CollectFunc) {
+	r.register(name, &collected{name: name, help: help, typ: typeGauge, labels: labels, collect: collect}, true)
+}
+
+// NewCounterFunc registers a counter whose values are collected when the registry is scraped.
+// It replaces any metric registered with the same name, so that it can be registered again with fresh state.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect CollectFunc) {
+	r.register(name, &collected{name: name, help: help, typ: typeCounter, labels: labels, collect: collect}, true)
+}
+
+func (c *collected) write(w *bufio.Writer) {
+	v := newVec(c.name, c.help, c.typ, c.labels)
+	c.collect(func(value float64, labelValues ...string) {
+		v.get(labelValues, newScalarSeries).value += value
+	})
+	v.write(w)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, extraLabel, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l, escapeLabelValue(labelValues[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatValue(value))
+	w.WriteByte('\n')
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
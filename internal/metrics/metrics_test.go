@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounter("test_calls_total", "Calls made.", "server", "outcome")
+	calls.Inc("github", "success")
+	calls.Add(2, "github", "success")
+	calls.Inc("weather", `timed "out"`)
+
+	latency := r.NewHistogram("test_latency_seconds", "Latency of calls.", []float64{0.1, 1}, "server")
+	latency.Observe(0.05, "github")
+	latency.Observe(0.5, "github")
+	latency.Observe(5, "github")
+
+	r.NewGaugeFunc("test_servers", "Registered servers.", nil, func(emit func(float64, ...string)) {
+		emit(2)
+	})
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	want := `# HELP test_calls_total Calls made.
+# TYPE test_calls_total counter
+test_calls_total{server="github",outcome="success"} 3
+test_calls_total{server="weather",outcome="timed \"out\""} 1
+# HELP test_latency_seconds Latency of calls.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{server="github",le="0.1"} 1
+test_latency_seconds_bucket{server="github",le="1"} 2
+test_latency_seconds_bucket{server="github",le="+Inf"} 3
+test_latency_seconds_sum{server="github"} 5.55
+test_latency_seconds_count{server="github"} 3
+# HELP test_servers Registered servers.
+# TYPE test_servers gauge
+test_servers 2
+`
+	if out.String() != want {
+		t.Fatalf("unexpected metrics:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestSeriesAreBounded(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounter("test_calls_total", "Calls made.", "tool")
+	for i := 0; i < MaxSeries+10; i++ {
+		calls.Inc(strings.Repeat("x", i))
+	}
+	if n := len(calls.v.series); n != MaxSeries+1 {
+		t.Fatalf("expected %d series including the overflow series, got %d", MaxSeries+1, n)
+	}
+	if overflow := calls.v.series[OverflowLabelValue]; overflow == nil || overflow.value != 10 {
+		t.Fatalf("expected the overflow series to count the 10 calls beyond the limit, got %+v", overflow)
+	}
+}
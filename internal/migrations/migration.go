@@ -4,13 +4,23 @@ import (
 	"fmt"
 	"github.com/duaraghav8/mcpjungle/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
+// SchemaVersion is the version of the database schema this build of MCPJungle expects.
+// Bump it whenever a change to the models requires a migration.
+const SchemaVersion = 6
+
 // Migrate performs the database migration for the application.
+// Once all models are migrated, it records SchemaVersion as applied.
 func Migrate(db *gorm.DB) error {
 	if err := db.AutoMigrate(&model.McpServer{}); err != nil {
 		return fmt.Errorf("auto‑migration failed for McpServer model: %v", err)
 	}
+	if err := db.AutoMigrate(&model.ServerEndpoint{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for ServerEndpoint model: %v", err)
+	}
 	if err := db.AutoMigrate(&model.Tool{}); err != nil {
 		return fmt.Errorf("auto‑migration failed for Tool model: %v", err)
 	}
@@ -41,5 +51,62 @@ func Migrate(db *gorm.DB) error {
 	if err := db.AutoMigrate(&model.Alert{}); err != nil {
 		return fmt.Errorf("auto‑migration failed for Alert model: %v", err)
 	}
+	if err := db.AutoMigrate(&model.AlertRule{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for AlertRule model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.NotificationChannel{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for NotificationChannel model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ModelPrice{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for ModelPrice model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Quota{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for Quota model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CallLimit{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CallLimit model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CallPolicy{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CallPolicy model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ServerHealthCheck{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for ServerHealthCheck model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ServerUptimeDay{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for ServerUptimeDay model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.ServerSLO{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for ServerSLO model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CallCaptureConfig{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CallCaptureConfig model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CapturedCall{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CapturedCall model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CassetteConfig{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CassetteConfig model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CanaryCheck{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CanaryCheck model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.CanaryRun{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for CanaryRun model: %v", err)
+	}
+	if err := db.AutoMigrate(&model.SchemaMigration{}); err != nil {
+		return fmt.Errorf("auto‑migration failed for SchemaMigration model: %v", err)
+	}
+
+	applied := model.SchemaMigration{Version: SchemaVersion, AppliedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&applied).Error; err != nil {
+		return fmt.Errorf("failed to record schema version %d: %v", SchemaVersion, err)
+	}
 	return nil
 }
+
+// AppliedSchemaVersion returns the latest schema version the database was migrated to, 0 if it never was.
+func AppliedSchemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Model(&model.SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Row().Scan(&version)
+	return version, err
+}
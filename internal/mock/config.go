@@ -0,0 +1,247 @@
+// Package mock serves MCP servers whose tools and responses are declared in YAML,
+// to develop and test MCP clients and the registry without real upstream servers.
+package mock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultServerName    = "mcpjungle-mock"
+	defaultServerVersion = "0.0.1"
+)
+
+// Config declares a mock MCP server.
+type Config struct {
+	Name         string `yaml:"name,omitempty"`
+	Version      string `yaml:"version,omitempty"`
+	Instructions string `yaml:"instructions,omitempty"`
+	Tools        []Tool `yaml:"tools"`
+}
+
+// Tool declares a tool of a mock server and the responses to its calls.
+type Tool struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	InputSchema map[string]any `yaml:"input_schema,omitempty"`
+
+	// Responses are tried in order and the first one whose When matches the arguments of a call is returned.
+	Responses []Response `yaml:"responses"`
+}
+
+// Response is the response of a mock tool to a call.
+// Text, JSON and Image make up the content of the result and can be combined,
+// while Error and ProtocolError fail the call.
+// Text, Error, ProtocolError and the strings in JSON are Go templates executed with the arguments of the call,
+// eg- "Sunny in {{.city}}".
+type Response struct {
+	// When lists arguments and their values, the response only matches calls that have all of them.
+	// A response without When matches every call.
+	When map[string]any `yaml:"when,omitempty"`
+
+	// Delay is how long the mock waits before responding, eg- 500ms
+	Delay time.Duration `yaml:"delay,omitempty"`
+
+	Text  string `yaml:"text,omitempty"`
+	JSON  any    `yaml:"json,omitempty"`
+	Image *Image `yaml:"image,omitempty"`
+
+	// Error returns a tool error result with the message
+	Error string `yaml:"error,omitempty"`
+	// ProtocolError fails the call with a JSON-RPC error instead of a tool result
+	ProtocolError string `yaml:"protocol_error,omitempty"`
+
+	text, err, protocolErr *template.Template
+}
+
+// Image is image content, given as base64 data or as the path of a file relative to the config file.
+// Paths are only allowed in config files, see Load.
+type Image struct {
+	Data     string `yaml:"data,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	MimeType string `yaml:"mime_type,omitempty"`
+}
+
+// Load reads and validates the mock server declared in the YAML file at path.
+// Images given as paths are read into their data, so the config is self-contained.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock config: %w", err)
+	}
+	return parse(data, filepath.Dir(path))
+}
+
+// Parse parses and validates a mock server declared in YAML, eg- a config stored by the registry.
+// Images must be given as data: the config doesn't come from a file that image paths could be relative to,
+// and resolving them would let whoever provides the config read files of the host parsing it.
+func Parse(data []byte) (*Config, error) {
+	return parse(data, "")
+}
+
+// parse parses and validates a mock server declared in YAML.
+// The paths of images are relative to dir, if dir is empty images can't be given as paths.
+func parse(data []byte, dir string) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse mock config: %w", err)
+	}
+	if err := cfg.validate(dir); err != nil {
+		return nil, fmt.Errorf("invalid mock config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Marshal encodes the config back to YAML.
+func (c *Config) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+func (c *Config) validate(dir string) error {
+	if c.Name == "" {
+		c.Name = defaultServerName
+	}
+	if c.Version == "" {
+		c.Version = defaultServerVersion
+	}
+	if len(c.Tools) == 0 {
+		return errors.New("no tools declared")
+	}
+	seen := make(map[string]bool, len(c.Tools))
+	for i := range c.Tools {
+		t := &c.Tools[i]
+		if t.Name == "" {
+			return fmt.Errorf("tool #%d has no name", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("tool %s is declared more than once", t.Name)
+		}
+		seen[t.Name] = true
+		if len(t.Responses) == 0 {
+			return fmt.Errorf("tool %s has no responses", t.Name)
+		}
+		for j := range t.Responses {
+			if err := t.Responses[j].validate(dir); err != nil {
+				return fmt.Errorf("response #%d of tool %s: %w", j+1, t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Response) validate(dir string) error {
+	if r.Delay < 0 {
+		return fmt.Errorf("delay %s must not be negative", r.Delay)
+	}
+	hasContent := r.Text != "" || r.JSON != nil || r.Image != nil
+	switch {
+	case r.Error != "" && r.ProtocolError != "":
+		return errors.New("error and protocol_error are mutually exclusive")
+	case (r.Error != "" || r.ProtocolError != "") && hasContent:
+		return errors.New("an error response must not have text, json or image content")
+	case r.Error == "" && r.ProtocolError == "" && !hasContent:
+		return errors.New("must have text, json or image content, or an error")
+	}
+
+	var err error
+	if r.text, err = parseTemplate("text", r.Text); err != nil {
+		return err
+	}
+	if r.err, err = parseTemplate("error", r.Error); err != nil {
+		return err
+	}
+	if r.protocolErr, err = parseTemplate("protocol_error", r.ProtocolError); err != nil {
+		return err
+	}
+	if r.JSON != nil {
+		if _, err := json.Marshal(r.JSON); err != nil {
+			return fmt.Errorf("invalid json: %w", err)
+		}
+		if err := checkTemplates(r.JSON); err != nil {
+			return err
+		}
+	}
+	if r.Image != nil {
+		return r.Image.load(dir)
+	}
+	return nil
+}
+
+// load reads the image file into its data and checks the data and mime type.
+func (i *Image) load(dir string) error {
+	if (i.Data == "") == (i.Path == "") {
+		return errors.New("image must have exactly one of data or path")
+	}
+	if i.Path != "" {
+		if dir == "" {
+			return errors.New("image path is only allowed in a mock config file, give the image as data instead")
+		}
+		path := i.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read image: %w", err)
+		}
+		if i.MimeType == "" {
+			i.MimeType = mime.TypeByExtension(filepath.Ext(path))
+		}
+		i.Data = base64.StdEncoding.EncodeToString(data)
+		i.Path = ""
+	} else if _, err := base64.StdEncoding.DecodeString(i.Data); err != nil {
+		return fmt.Errorf("image data must be base64 encoded: %w", err)
+	}
+	if i.MimeType == "" {
+		return errors.New("image has no mime_type")
+	}
+	if !strings.HasPrefix(i.MimeType, "image/") {
+		return fmt.Errorf("invalid image mime_type '%s'", i.MimeType)
+	}
+	return nil
+}
+
+// parseTemplate parses a response template, nil is returned for an empty one.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+// checkTemplates checks that the strings in a json response are valid templates.
+func checkTemplates(v any) error {
+	switch v := v.(type) {
+	case string:
+		_, err := parseTemplate("json", v)
+		return err
+	case map[string]any:
+		for _, e := range v {
+			if err := checkTemplates(e); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if err := checkTemplates(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
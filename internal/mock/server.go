@@ -0,0 +1,172 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// templateFuncs are the functions available to response templates.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, eg- {{json .filters}}
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewServer creates an MCP server that serves the tools of the config.
+// It can be served over streamable HTTP or connected to in-process.
+func NewServer(cfg *Config) *server.MCPServer {
+	opts := []server.ServerOption{server.WithToolCapabilities(false)}
+	if cfg.Instructions != "" {
+		opts = append(opts, server.WithInstructions(cfg.Instructions))
+	}
+	s := server.NewMCPServer(cfg.Name, cfg.Version, opts...)
+	for _, t := range cfg.Tools {
+		s.AddTool(t.mcpTool(), t.handle)
+	}
+	return s
+}
+
+func (t Tool) mcpTool() mcp.Tool {
+	schema := t.InputSchema
+	if schema == nil {
+		schema = map[string]any{"type": "object"}
+	}
+	// the schema was checked to be encodable when the config was parsed
+	raw, _ := json.Marshal(schema)
+	return mcp.NewToolWithRawSchema(t.Name, t.Description, raw)
+}
+
+// handle responds to a call with the first response that matches its arguments.
+func (t Tool) handle(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	if args == nil {
+		args = map[string]any{}
+	}
+	for i := range t.Responses {
+		r := &t.Responses[i]
+		if !r.matches(args) {
+			continue
+		}
+		if r.Delay > 0 {
+			timer := time.NewTimer(r.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		return r.result(args)
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("no response of mock tool %s matches the arguments", t.Name)), nil
+}
+
+// matches reports whether the arguments of a call have all the values listed in When.
+// Values are compared by their JSON encoding, so that eg- the YAML integer 1 matches the JSON number 1.
+func (r *Response) matches(args map[string]any) bool {
+	for k, want := range r.When {
+		got, ok := args[k]
+		if !ok {
+			return false
+		}
+		w, err1 := json.Marshal(want)
+		g, err2 := json.Marshal(got)
+		if err1 != nil || err2 != nil || !bytes.Equal(w, g) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Response) result(args map[string]any) (*mcp.CallToolResult, error) {
+	if r.protocolErr != nil {
+		msg, err := execute(r.protocolErr, args)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(msg)
+	}
+	if r.err != nil {
+		msg, err := execute(r.err, args)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultError(msg), nil
+	}
+
+	result := &mcp.CallToolResult{}
+	if r.text != nil {
+		text, err := execute(r.text, args)
+		if err != nil {
+			return nil, err
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(text))
+	}
+	if r.JSON != nil {
+		v, err := renderJSON(r.JSON, args)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode json response: %w", err)
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(string(b)))
+	}
+	if r.Image != nil {
+		result.Content = append(result.Content, mcp.NewImageContent(r.Image.Data, r.Image.MimeType))
+	}
+	return result, nil
+}
+
+// renderJSON returns a copy of a json response with its strings executed as templates.
+func renderJSON(v any, args map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		t, err := parseTemplate("json", v)
+		if err != nil || t == nil {
+			return v, err
+		}
+		return execute(t, args)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := renderJSON(e, args)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			r, err := renderJSON(e, args)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func execute(t *template.Template, args map[string]any) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, args); err != nil {
+		return "", fmt.Errorf("failed to render the %s of the response: %w", t.Name(), err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,159 @@
+package mock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const testConfig = `
+name: weather
+tools:
+  - name: forecast
+    description: Get the forecast of a city
+    input_schema:
+      type: object
+      properties:
+        city: {type: string}
+      required: [city]
+    responses:
+      - when: {city: Atlantis}
+        error: "unknown city {{.city}}"
+      - when: {city: Nowhere}
+        protocol_error: "upstream unavailable"
+      - when: {city: Slow}
+        delay: 1s
+        text: "too late"
+      - when: {days: 2}
+        json: {city: "{{.city}}", days: 2, summary: [sunny, rainy]}
+      - text: "Sunny in {{.city}}"
+        image: {path: sun.png}
+`
+
+// loadTestConfig loads testConfig from a file next to the image that it refers to.
+func loadTestConfig(t *testing.T) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sun.png"), []byte("png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "weather.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestParse(t *testing.T) {
+	cfg := loadTestConfig(t)
+	img := cfg.Tools[0].Responses[4].Image
+	if img.Data != "cG5n" || img.MimeType != "image/png" || img.Path != "" {
+		t.Errorf("expected the image file to be read into its data, got %+v", img)
+	}
+	if cfg.Version != defaultServerVersion {
+		t.Errorf("expected the default version, got %s", cfg.Version)
+	}
+
+	// the encoded config is self-contained
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	if _, err := Parse(data); err != nil {
+		t.Errorf("failed to parse the encoded config: %v\n%s", err, data)
+	}
+
+	// a config that isn't loaded from a file can't read files
+	if _, err := Parse([]byte(testConfig)); err == nil || !strings.Contains(err.Error(), "image path") {
+		t.Errorf("expected an image path to be rejected by Parse, got %v", err)
+	}
+
+	invalid := []string{
+		`tools: []`,
+		`tools: [{name: a}]`,
+		`tools: [{name: a, responses: [{text: x}]}, {name: a, responses: [{text: y}]}]`,
+		`tools: [{name: a, responses: [{delay: 1s}]}]`,
+		`tools: [{name: a, responses: [{text: x, error: y}]}]`,
+		`tools: [{name: a, responses: [{error: x, protocol_error: y}]}]`,
+		`tools: [{name: a, responses: [{text: "{{.x"}]}]`,
+		`tools: [{name: a, responses: [{image: {data: "!!"}}]}]`,
+		`tools: [{name: a, responses: [{image: {data: cG5n}}]}]`,
+	}
+	for _, c := range invalid {
+		if _, err := Parse([]byte(c)); err == nil {
+			t.Errorf("expected config %s to be invalid", c)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	cfg := loadTestConfig(t)
+	c, err := client.NewInProcessClient(NewServer(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil || len(tools.Tools) != 1 {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	schema, _ := tools.Tools[0].MarshalJSON()
+	if !strings.Contains(string(schema), `"required":["city"]`) {
+		t.Errorf("expected the declared input schema, got %s", schema)
+	}
+
+	call := func(ctx context.Context, args map[string]any) (*mcp.CallToolResult, error) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "forecast"
+		req.Params.Arguments = args
+		return c.CallTool(ctx, req)
+	}
+
+	result, err := call(ctx, map[string]any{"city": "Paris"})
+	if err != nil || len(result.Content) != 2 {
+		t.Fatalf("expected text and image content, got %+v (%v)", result, err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Sunny in Paris" {
+		t.Errorf("expected the text template to be rendered, got %q", text)
+	}
+	if img := result.Content[1].(mcp.ImageContent); img.MIMEType != "image/png" {
+		t.Errorf("expected image content, got %+v", img)
+	}
+
+	result, err = call(ctx, map[string]any{"city": "Oslo", "days": 2})
+	if err != nil || result.Content[0].(mcp.TextContent).Text != `{"city":"Oslo","days":2,"summary":["sunny","rainy"]}` {
+		t.Errorf("expected the json response to match the JSON number, got %+v (%v)", result, err)
+	}
+
+	result, err = call(ctx, map[string]any{"city": "Atlantis"})
+	if err != nil || !result.IsError || result.Content[0].(mcp.TextContent).Text != "unknown city Atlantis" {
+		t.Errorf("expected a tool error, got %+v (%v)", result, err)
+	}
+	if _, err := call(ctx, map[string]any{"city": "Nowhere"}); err == nil || !strings.Contains(err.Error(), "upstream unavailable") {
+		t.Errorf("expected a protocol error, got %v", err)
+	}
+	if result, err := call(ctx, map[string]any{}); err == nil {
+		t.Errorf("expected a template referencing a missing argument to fail, got %+v", result)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := call(timeout, map[string]any{"city": "Slow"}); err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected the delay to be cut short by the cancellation of the call, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AlertRuleType string
+
+const (
+	// AlertRuleDailyCost triggers when the cost since the start of the current day (UTC) reaches the threshold.
+	AlertRuleDailyCost AlertRuleType = "daily_cost"
+	// AlertRuleMonthlyCost triggers when the cost since the start of the current month (UTC) reaches the threshold.
+	AlertRuleMonthlyCost AlertRuleType = "monthly_cost"
+	// AlertRuleErrorRate triggers when the ratio of failed tool calls in the window reaches the threshold (0 to 1).
+	AlertRuleErrorRate AlertRuleType = "error_rate"
+	// AlertRuleUsageSpike triggers when the token usage in the window is at least threshold times
+	// the average usage of an equally long window during the baseline period.
+	AlertRuleUsageSpike AlertRuleType = "usage_spike"
+	// AlertRuleServerUnreachable triggers when an MCP server cannot be connected to.
+	AlertRuleServerUnreachable AlertRuleType = "server_unreachable"
+)
+
+// AlertRule is a user-defined condition that is periodically evaluated by the registry.
+// While the condition holds, the rule has exactly one open (unresolved) Alert.
+// The alert is resolved automatically once the condition clears.
+type AlertRule struct {
+	ID          uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey"`
+	Name        string        `json:"name" gorm:"uniqueIndex;not null"`
+	Description string        `json:"description"`
+	Type        AlertRuleType `json:"type" gorm:"not null;index"`
+	Severity    string        `json:"severity" gorm:"not null"` // low, medium, high, critical
+
+	// Threshold is interpreted according to the rule type:
+	// cost in USD for cost rules, a ratio between 0 and 1 for error_rate and
+	// a multiplier of the baseline for usage_spike. It is ignored by server_unreachable.
+	Threshold float64 `json:"threshold"`
+
+	// WindowMinutes is the evaluation window for error_rate and usage_spike rules.
+	WindowMinutes int `json:"window_minutes"`
+	// BaselineWindowMinutes is the period preceding the evaluation window used as baseline by usage_spike rules.
+	BaselineWindowMinutes int `json:"baseline_window_minutes"`
+	// MinSamples is the minimum number of tool calls (error_rate) or usage records (usage_spike)
+	// required in the window for the rule to trigger. It avoids alerting on statistically meaningless data.
+	MinSamples int `json:"min_samples"`
+
+	// ResourceType and ResourceID optionally narrow the rule down to a single resource.
+	// Cost and usage_spike rules can be scoped to a model or client,
+	// error_rate rules to a server and server_unreachable rules require a server.
+	ResourceType string  `json:"resource_type" gorm:"not null;default:global"` // model, server, client, global
+	ResourceID   *string `json:"resource_id,omitempty"`
+
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *AlertRule) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return nil
+}
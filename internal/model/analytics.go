@@ -15,14 +15,34 @@ type UsageMetric struct {
 	InputTokens  int       `json:"input_tokens" gorm:"not null"`
 	OutputTokens int       `json:"output_tokens" gorm:"not null"`
 	TotalTokens  int       `json:"total_tokens" gorm:"not null"`
-	Cost         float64   `json:"cost" gorm:"type:decimal(10,6)"`
-	SessionID    string    `json:"session_id" gorm:"index"`
-	UserID       *string   `json:"user_id,omitempty" gorm:"index"`
-	Timestamp    time.Time `json:"timestamp" gorm:"not null;index"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	// Cost in USD. It is nil in a report that leaves the cost to the pricing catalog, and set once recorded.
+	Cost      *float64  `json:"cost" gorm:"type:decimal(10,6)"`
+	SessionID string    `json:"session_id" gorm:"index"`
+	UserID    *string   `json:"user_id,omitempty" gorm:"index"`
+	Timestamp time.Time `json:"timestamp" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Prompt caching token counts, billed separately from InputTokens.
+	CacheReadTokens  int `json:"cache_read_tokens"`
+	CacheWriteTokens int `json:"cache_write_tokens"`
+
+	// CostSource tells where Cost comes from, see the CostSource* constants.
+	CostSource string `json:"cost_source"`
+
+	// APIKeyHash is the hash of the API key presented by the client that reported the usage, if any.
+	APIKeyHash string `json:"api_key_hash,omitempty" gorm:"index"`
 }
 
+const (
+	// CostSourceReported means the cost was sent by the client that reported the usage
+	CostSourceReported = "reported"
+	// CostSourceCatalog means the cost was computed from the pricing catalog
+	CostSourceCatalog = "catalog"
+	// CostSourceUnpriced means the client sent no cost and the catalog has no price for the model
+	CostSourceUnpriced = "unpriced"
+)
+
 func (u *UsageMetric) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New()
 	return nil
@@ -49,8 +69,30 @@ type ToolCall struct {
 	// Relations
 	UsageMetricID *uuid.UUID   `json:"usage_metric_id,omitempty" gorm:"type:uuid;index"`
 	UsageMetric   *UsageMetric `json:"usage_metric,omitempty" gorm:"foreignKey:UsageMetricID"`
+
+	// APIKeyHash is the hash of the API key presented by the caller, if any.
+	APIKeyHash string `json:"api_key_hash,omitempty" gorm:"index"`
+
+	// ErrorType classifies why a failed call failed, see the ToolCallError* constants.
+	ErrorType string `json:"error_type,omitempty" gorm:"index"`
+
+	// Attempts is the number of times the call was forwarded upstream, including retries.
+	Attempts int `json:"attempts"`
 }
 
+const (
+	// ToolCallErrorTimeout means connecting to the upstream server or the call itself timed out
+	ToolCallErrorTimeout = "timeout"
+	// ToolCallErrorCancelled means the caller cancelled the call before it completed
+	ToolCallErrorCancelled = "cancelled"
+	// ToolCallErrorConnection means the registry could not connect to the upstream server
+	ToolCallErrorConnection = "connection"
+	// ToolCallErrorUpstream means the upstream server failed to process the call (transport or protocol error)
+	ToolCallErrorUpstream = "upstream"
+	// ToolCallErrorTool means the tool ran but returned an error result
+	ToolCallErrorTool = "tool"
+)
+
 func (t *ToolCall) BeforeCreate(tx *gorm.DB) (err error) {
 	t.ID = uuid.New()
 	return nil
@@ -156,9 +198,14 @@ type Alert struct {
 	Resolved     bool      `json:"resolved" gorm:"default:false"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// RuleID is set if the alert was raised by an AlertRule.
+	// A rule has at most one unresolved alert at any time.
+	RuleID     *uuid.UUID `json:"rule_id,omitempty" gorm:"type:uuid;index"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
 }
 
 func (a *Alert) BeforeCreate(tx *gorm.DB) (err error) {
 	a.ID = uuid.New()
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CallCaptureConfig opts an upstream MCP server into capturing the full payloads of the tool calls made to it.
+// Captures may contain sensitive data, so they are only stored for servers that have a capture config.
+type CallCaptureConfig struct {
+	ID         uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ServerName string    `json:"server_name" gorm:"uniqueIndex;not null"`
+
+	// MaxPayloadBytes caps the size of the captured arguments and of the captured result, separately.
+	// Larger payloads are truncated. 0 means the registry default.
+	MaxPayloadBytes int `json:"max_payload_bytes"`
+	// RedactFields is a comma-separated list of field names whose values are redacted from the payloads,
+	// in addition to the fields that are always redacted (eg- password, token).
+	RedactFields string `json:"redact_fields"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *CallCaptureConfig) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+// CapturedCall is a tool call made to an MCP server with capture enabled, along with its full arguments and result.
+type CapturedCall struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	ServerName string    `json:"server_name" gorm:"not null;index"`
+	ToolName   string    `json:"tool_name" gorm:"not null;index"`
+	SessionID  string    `json:"session_id,omitempty" gorm:"index"`
+	RequestID  string    `json:"request_id,omitempty" gorm:"index"`
+	ClientType string    `json:"client_type"`
+
+	// Arguments and Result are the JSON encoding of the call's arguments and of the upstream's result,
+	// with sensitive fields redacted. A truncated payload is no longer valid JSON.
+	Arguments          string `json:"arguments"`
+	ArgumentsTruncated bool   `json:"arguments_truncated,omitempty"`
+	Result             string `json:"result,omitempty"`
+	ResultTruncated    bool   `json:"result_truncated,omitempty"`
+
+	// IsError is set for calls that failed, be it with an error result or without a result.
+	IsError bool `json:"is_error" gorm:"not null;index"`
+	// Error is the reason why the call failed without a result.
+	Error string `json:"error,omitempty"`
+	// ErrorType classifies why a failed call failed, see the ToolCallError* constants.
+	ErrorType string `json:"error_type,omitempty"`
+
+	DurationMs int       `json:"duration_ms"`
+	Attempts   int       `json:"attempts"`
+	Timestamp  time.Time `json:"timestamp" gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c *CapturedCall) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CallLimitMode string
+
+const (
+	// CallLimitModeReject rejects a call immediately if it would exceed the limit
+	CallLimitModeReject CallLimitMode = "reject"
+	// CallLimitModeQueue makes a call wait until it fits within the limit, up to the queue timeout
+	CallLimitModeQueue CallLimitMode = "queue"
+)
+
+// CallLimit protects an upstream MCP server (or one of its tools) from being overloaded by
+// capping the number of concurrent in-flight calls and the rate of calls (token bucket).
+type CallLimit struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+
+	// ScopeValue is the server name for server limits and the canonical tool name (<server>/<tool>) for tool limits.
+	ScopeType  CallScope `json:"scope_type" gorm:"not null;uniqueIndex:idx_call_limit_scope"`
+	ScopeValue string    `json:"scope_value" gorm:"not null;uniqueIndex:idx_call_limit_scope"`
+
+	// MaxConcurrent is the maximum number of in-flight calls, 0 means unlimited.
+	MaxConcurrent int `json:"max_concurrent"`
+	// RatePerSecond is the rate at which the token bucket refills, 0 means unlimited.
+	RatePerSecond float64 `json:"rate_per_second"`
+	// Burst is the capacity of the token bucket, ie, how many calls can be made at once after a quiet period.
+	Burst int `json:"burst"`
+
+	Mode CallLimitMode `json:"mode" gorm:"not null;default:reject"`
+	// QueueTimeoutMs is the longest a call waits for the limit in queue mode.
+	QueueTimeoutMs int `json:"queue_timeout_ms"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (l *CallLimit) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.New()
+	return nil
+}
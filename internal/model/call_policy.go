@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CallScope is what a call limit or call policy applies to.
+type CallScope string
+
+const (
+	// CallScopeServer applies to all calls to an MCP server
+	CallScopeServer CallScope = "server"
+	// CallScopeTool applies to the calls to a single tool
+	CallScopeTool CallScope = "tool"
+)
+
+// CallPolicy configures how the proxy calls an upstream MCP server (or one of its tools).
+// Every setting is optional: a tool's policy overrides its server's policy, which overrides the registry defaults.
+type CallPolicy struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+
+	// ScopeValue is the server name for server policies and the canonical tool name (<server>/<tool>) for tool policies.
+	ScopeType  CallScope `json:"scope_type" gorm:"not null;uniqueIndex:idx_call_policy_scope"`
+	ScopeValue string    `json:"scope_value" gorm:"not null;uniqueIndex:idx_call_policy_scope"`
+
+	// ConnectTimeoutMs bounds connecting to the upstream server and initializing the MCP session, 0 means no timeout.
+	ConnectTimeoutMs *int `json:"connect_timeout_ms,omitempty"`
+	// CallTimeoutMs bounds a single tool call once connected, 0 means no timeout.
+	CallTimeoutMs *int `json:"call_timeout_ms,omitempty"`
+
+	// BreakerFailureThreshold is the number of consecutive failed calls that opens the circuit breaker
+	// of the server, 0 disables the breaker. Only server policies can configure the breaker.
+	BreakerFailureThreshold *int `json:"breaker_failure_threshold,omitempty"`
+	// BreakerCooldownMs is how long an open breaker fails calls fast before letting a trial call through.
+	BreakerCooldownMs *int `json:"breaker_cooldown_ms,omitempty"`
+
+	// RetryMaxAttempts is the maximum number of times a call is forwarded upstream, 1 disables retries.
+	RetryMaxAttempts *int `json:"retry_max_attempts,omitempty"`
+	// RetryBackoffMs is the backoff before the first retry, it doubles with every retry up to RetryMaxBackoffMs.
+	RetryBackoffMs    *int `json:"retry_backoff_ms,omitempty"`
+	RetryMaxBackoffMs *int `json:"retry_max_backoff_ms,omitempty"`
+	// RetryOn is a comma-separated list of the classes of errors that are retried (see the ToolCallError* constants).
+	RetryOn *string `json:"retry_on,omitempty"`
+	// RetryNonIdempotent allows retrying tools that don't declare the readOnlyHint or idempotentHint annotations.
+	RetryNonIdempotent *bool `json:"retry_non_idempotent,omitempty"`
+
+	// ValidateArguments is whether the arguments of calls are validated against the tool's input schema
+	// before being forwarded, it's enabled by default. Disable it for servers whose schemas are sloppy.
+	ValidateArguments *bool `json:"validate_arguments,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (p *CallPolicy) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// CanaryCheck is a synthetic tool call that the registry makes on a schedule to check that a tool actually works,
+// which a successful health check of its server doesn't tell.
+type CanaryCheck struct {
+	ID   uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Name string    `json:"name" gorm:"uniqueIndex;not null"`
+
+	// ToolName is the canonical name of the tool to call, ie- <server>/<tool>
+	ToolName   string `json:"tool_name" gorm:"not null"`
+	ServerName string `json:"server_name" gorm:"not null;index"`
+
+	// Input is the arguments of the call, a JSON object
+	Input datatypes.JSON `json:"input,omitempty" gorm:"type:jsonb"`
+	// Expect holds the assertions that the result must meet, eg- {"is_error": false, "text_contains": ["ok"]}
+	Expect datatypes.JSON `json:"expect,omitempty" gorm:"type:jsonb"`
+
+	// IntervalSeconds is how often the canary runs
+	IntervalSeconds int `json:"interval_seconds" gorm:"not null"`
+	// FailureThreshold is the number of consecutive failed runs that raises an alert and degrades the server's health
+	FailureThreshold int  `json:"failure_threshold" gorm:"not null"`
+	Enabled          bool `json:"enabled" gorm:"not null"`
+
+	// The outcome of the latest run, kept up to date by the registry
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastPassed          *bool      `json:"last_passed,omitempty"`
+	LastLatencyMs       int64      `json:"last_latency_ms"`
+	LastError           string     `json:"last_error,omitempty" gorm:"type:text"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *CanaryCheck) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+// CanaryRun is the outcome of one run of a canary check.
+type CanaryRun struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	CanaryName string    `json:"canary_name" gorm:"not null;index:idx_canary_run"`
+	ServerName string    `json:"server_name" gorm:"not null"`
+	RanAt      time.Time `json:"ran_at" gorm:"not null;index:idx_canary_run"`
+	Passed     bool      `json:"passed" gorm:"not null"`
+	LatencyMs  int64     `json:"latency_ms"`
+	// Error is why the run failed: the error of the call or the assertions that its result failed
+	Error string `json:"error,omitempty" gorm:"type:text"`
+}
+
+func (r *CanaryRun) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CassetteMode is what the registry does with the cassette of an MCP server.
+type CassetteMode string
+
+const (
+	// CassetteModeRecord forwards tool calls to the server and records them to the cassette
+	CassetteModeRecord CassetteMode = "record"
+	// CassetteModeReplay serves tool calls from the cassette, without contacting the server
+	CassetteModeReplay CassetteMode = "replay"
+)
+
+// CassetteMatch is how a tool call is matched to the calls recorded in a cassette when replaying it.
+type CassetteMatch string
+
+const (
+	// CassetteMatchStrict only matches a call recorded with the same tool and arguments,
+	// and every recorded call is replayed once, so repeated calls must be repeated in the recording too.
+	CassetteMatchStrict CassetteMatch = "strict"
+	// CassetteMatchLenient reuses recorded calls, and falls back to a call of the same tool
+	// with different arguments when there is no call with the same arguments.
+	CassetteMatchLenient CassetteMatch = "lenient"
+)
+
+// CassetteConfig puts an upstream MCP server in record or replay mode, with a cassette file
+// that holds the server's tools and the tool calls made to it.
+type CassetteConfig struct {
+	ID         uuid.UUID    `json:"-" gorm:"type:uuid;primaryKey"`
+	ServerName string       `json:"server_name" gorm:"uniqueIndex;not null"`
+	Mode       CassetteMode `json:"mode" gorm:"not null"`
+	// Path is the path of the cassette file, relative to the registry's cassette directory
+	Path  string        `json:"path" gorm:"not null"`
+	Match CassetteMatch `json:"match"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *CassetteConfig) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
@@ -14,10 +14,22 @@ type McpServer struct {
 	// MCPJungle only supports streamable HTTP transport as of now.
 	URL string `json:"url" gorm:"not null"`
 
+	// Endpoints optionally lists several URLs serving the server, eg- its replicas.
+	// Tool calls are load balanced across them and fail over to a healthy endpoint if one is unreachable.
+	// When a server has endpoints, URL is the first of them.
+	Endpoints []ServerEndpoint `json:"endpoints,omitempty" gorm:"foreignKey:ServerID"`
+
+	// LoadBalancing is the strategy used to choose among the endpoints, it defaults to round robin.
+	LoadBalancing LoadBalancingStrategy `json:"load_balancing,omitempty"`
+
 	// TODO: Store the bearer token in a more secure way, e.g., encrypted in the database.
 	// BearerToken is an optional token used for authenticating requests to the MCP server.
 	// If present, it will be used to set the Authorization header in all requests to this MCP server.
 	BearerToken string `json:"bearer_token,omitempty" gorm:"type:text"`
+
+	// MockConfig optionally declares a mock server in YAML, which the registry serves in-process
+	// instead of connecting to an upstream server. The URL of a mock server is mock://<name>.
+	MockConfig string `json:"mock_config,omitempty" gorm:"type:text"`
 }
 
 func (s *McpServer) BeforeCreate(tx *gorm.DB) (err error) {
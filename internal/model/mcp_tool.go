@@ -12,6 +12,9 @@ type Tool struct {
 	Description string         `json:"description"`
 	InputSchema datatypes.JSON `json:"input_schema" gorm:"type:jsonb"`
 
+	// Annotations are the hints about the tool's behavior declared by its MCP server (eg- readOnlyHint).
+	Annotations datatypes.JSON `json:"annotations,omitempty" gorm:"type:jsonb"`
+
 	ServerID uuid.UUID `json:"-" gorm:"type:uuid"`
 	Server   McpServer `json:"-" gorm:"foreignKey:ServerID;references:ID"`
 }
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModelPrice is an entry of the pricing catalog, which is used to compute the cost of LLM usage.
+// Prices are in USD per million tokens.
+// An entry applies to usage from its EffectiveFrom time until the EffectiveFrom of the model's next entry,
+// which allows price changes to be recorded without affecting the cost of older usage.
+type ModelPrice struct {
+	ID    uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Model string    `json:"model" gorm:"not null;uniqueIndex:idx_model_price_effective_from"`
+
+	InputPerMTok      float64 `json:"input_per_mtok"`
+	OutputPerMTok     float64 `json:"output_per_mtok"`
+	CacheReadPerMTok  float64 `json:"cache_read_per_mtok"`
+	CacheWritePerMTok float64 `json:"cache_write_per_mtok"`
+
+	EffectiveFrom time.Time `json:"effective_from" gorm:"not null;uniqueIndex:idx_model_price_effective_from"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (p *ModelPrice) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return nil
+}
+
+// Cost returns the cost in USD of the given token counts at this price.
+func (p *ModelPrice) Cost(inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok +
+		float64(outputTokens)*p.OutputPerMTok +
+		float64(cacheReadTokens)*p.CacheReadPerMTok +
+		float64(cacheWriteTokens)*p.CacheWritePerMTok) / 1_000_000
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationChannelType string
+
+const (
+	// NotificationChannelWebhook POSTs a generic JSON payload to a URL.
+	// If a secret is configured, the payload is signed with HMAC-SHA256.
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	// NotificationChannelSlack POSTs a Slack-compatible incoming-webhook payload to a URL.
+	NotificationChannelSlack NotificationChannelType = "slack"
+	// NotificationChannelEmail sends an email through an SMTP server.
+	NotificationChannelEmail NotificationChannelType = "email"
+)
+
+// NotificationChannel is a destination that alerts are delivered to.
+type NotificationChannel struct {
+	ID   uuid.UUID               `json:"id" gorm:"type:uuid;primaryKey"`
+	Name string                  `json:"name" gorm:"uniqueIndex;not null"`
+	Type NotificationChannelType `json:"type" gorm:"not null"`
+
+	// MinSeverity routes alerts to this channel only if their severity is at least this high.
+	// Defaults to low, ie, all alerts are delivered.
+	MinSeverity string `json:"min_severity" gorm:"not null;default:low"`
+
+	// URL is the endpoint of webhook and slack channels.
+	URL string `json:"url,omitempty"`
+	// Secret is used by webhook channels to sign the payload.
+	// The signature is sent in the X-MCPJungle-Signature header as "sha256=<hex digest>".
+	Secret string `json:"secret,omitempty" gorm:"type:text"`
+
+	// SMTP settings of email channels.
+	SMTPHost     string `json:"smtp_host,omitempty"`
+	SMTPPort     int    `json:"smtp_port,omitempty"`
+	SMTPUsername string `json:"smtp_username,omitempty"`
+	SMTPPassword string `json:"smtp_password,omitempty" gorm:"type:text"`
+	EmailFrom    string `json:"email_from,omitempty"`
+	// EmailTo is a comma-separated list of recipients.
+	EmailTo string `json:"email_to,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (c *NotificationChannel) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
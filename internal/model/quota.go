@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuotaMetric string
+
+const (
+	// QuotaMetricCalls limits the number of tool calls
+	QuotaMetricCalls QuotaMetric = "calls"
+	// QuotaMetricCost limits the cost (USD) of the LLM usage
+	QuotaMetricCost QuotaMetric = "cost"
+)
+
+type QuotaPeriod string
+
+const (
+	QuotaPeriodMinute QuotaPeriod = "minute"
+	QuotaPeriodDay    QuotaPeriod = "day"
+	QuotaPeriodMonth  QuotaPeriod = "month"
+)
+
+type QuotaScope string
+
+const (
+	// QuotaScopeGlobal applies the quota to all tool calls
+	QuotaScopeGlobal QuotaScope = "global"
+	// QuotaScopeAPIKey applies the quota to the calls made with an API key
+	QuotaScopeAPIKey QuotaScope = "api_key"
+	// QuotaScopeClient applies the quota to the calls made by a type of client
+	QuotaScopeClient QuotaScope = "client"
+	// QuotaScopeServer applies the quota to the calls to an MCP server
+	QuotaScopeServer QuotaScope = "server"
+	// QuotaScopeTool applies the quota to the calls to a single tool
+	QuotaScopeTool QuotaScope = "tool"
+)
+
+// Quota caps the number of tool calls or the cost incurred in a period.
+// Once a quota is exhausted, tool calls within its scope are rejected until the period resets.
+// Periods are calendar periods in UTC, eg- a daily quota resets at midnight UTC.
+type Quota struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey"`
+	Name        string      `json:"name" gorm:"uniqueIndex;not null"`
+	Description string      `json:"description"`
+	Metric      QuotaMetric `json:"metric" gorm:"not null"`
+	Period      QuotaPeriod `json:"period" gorm:"not null"`
+	Limit       float64     `json:"limit" gorm:"column:limit_value;not null"`
+
+	// ScopeValue identifies the resource that the quota is scoped to:
+	// the hash of the API key, the client type, the server name or the canonical tool name (<server>/<tool>).
+	// It is empty for global quotas.
+	ScopeType  QuotaScope `json:"scope_type" gorm:"not null;default:global;index"`
+	ScopeValue string     `json:"scope_value,omitempty" gorm:"index"`
+
+	Enabled bool `json:"enabled" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Quota) BeforeCreate(tx *gorm.DB) (err error) {
+	q.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// SchemaMigration records that the database schema was migrated to a version.
+type SchemaMigration struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
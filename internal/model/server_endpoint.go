@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoadBalancingStrategy decides which endpoint of an MCP server a tool call is sent to.
+type LoadBalancingStrategy string
+
+const (
+	// LoadBalancingRoundRobin spreads calls over the endpoints in proportion to their weights.
+	LoadBalancingRoundRobin LoadBalancingStrategy = "round_robin"
+	// LoadBalancingLeastInFlight sends calls to the endpoint with the fewest in-flight calls relative to its weight.
+	LoadBalancingLeastInFlight LoadBalancingStrategy = "least_in_flight"
+)
+
+// ServerEndpoint is one of several URLs serving the same MCP server, eg- a replica of it.
+type ServerEndpoint struct {
+	ID       uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ServerID uuid.UUID `json:"-" gorm:"type:uuid;index;not null"`
+
+	// URL must be a valid http/https URL serving the MCP server over streamable HTTP transport.
+	URL string `json:"url" gorm:"not null"`
+
+	// Weight is the relative share of calls sent to the endpoint, it defaults to 1.
+	Weight int `json:"weight" gorm:"not null;default:1"`
+}
+
+func (e *ServerEndpoint) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.New()
+	return nil
+}
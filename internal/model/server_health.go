@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ServerHealthCheck is the outcome of one periodic probe of an upstream MCP server.
+type ServerHealthCheck struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	ServerName string    `json:"server_name" gorm:"not null;index:idx_server_health_check"`
+	CheckedAt  time.Time `json:"checked_at" gorm:"not null;index:idx_server_health_check"`
+	Healthy    bool      `json:"healthy" gorm:"not null"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+}
+
+func (c *ServerHealthCheck) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+// ServerUptimeDay counts the health checks of an upstream MCP server during a day (UTC).
+// Unlike individual health checks, daily counts are kept indefinitely for uptime and SLO reporting.
+type ServerUptimeDay struct {
+	ID         uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ServerName string    `json:"server_name" gorm:"not null;uniqueIndex:idx_server_uptime_day"`
+	// Day is formatted as YYYY-MM-DD
+	Day           string `json:"day" gorm:"not null;uniqueIndex:idx_server_uptime_day"`
+	Checks        int64  `json:"checks" gorm:"not null"`
+	HealthyChecks int64  `json:"healthy_checks" gorm:"not null"`
+}
+
+func (d *ServerUptimeDay) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return nil
+}
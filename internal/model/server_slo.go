@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ServerSLO is the availability objective of an upstream MCP server, eg- 0.999 for "three nines".
+type ServerSLO struct {
+	ID         uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ServerName string    `json:"server_name" gorm:"uniqueIndex;not null"`
+
+	// Target is the fraction of health checks and tool calls that must succeed, between 0 and 1 (exclusive).
+	Target float64 `json:"target" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *ServerSLO) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+// serverProbeTimeout is the maximum time spent trying to connect to an MCP server when probing its health.
+const serverProbeTimeout = 10 * time.Second
+
+var validAlertSeverities = map[string]bool{
+	"low":      true,
+	"medium":   true,
+	"high":     true,
+	"critical": true,
+}
+
+// validateAlertRule checks that the rule is well-formed and fills in defaults.
+func validateAlertRule(r *model.AlertRule) error {
+	if r.Name == "" {
+		return errors.New("alert rule name is required")
+	}
+	if r.Severity == "" {
+		r.Severity = "medium"
+	}
+	if !validAlertSeverities[r.Severity] {
+		return fmt.Errorf("invalid severity '%s', must be one of low, medium, high, critical", r.Severity)
+	}
+	if r.ResourceType == "" {
+		r.ResourceType = "global"
+	}
+	if r.ResourceType != "global" && (r.ResourceID == nil || *r.ResourceID == "") {
+		return fmt.Errorf("resource_id is required for resource type '%s'", r.ResourceType)
+	}
+
+	switch r.Type {
+	case model.AlertRuleDailyCost, model.AlertRuleMonthlyCost:
+		if r.ResourceType != "global" && r.ResourceType != "model" && r.ResourceType != "client" {
+			return fmt.Errorf("%s rules can only be scoped to a model or client", r.Type)
+		}
+		if r.Threshold <= 0 {
+			return errors.New("threshold must be a positive cost")
+		}
+	case model.AlertRuleErrorRate:
+		if r.ResourceType != "global" && r.ResourceType != "server" {
+			return errors.New("error_rate rules can only be scoped to a server")
+		}
+		if r.Threshold <= 0 || r.Threshold > 1 {
+			return errors.New("threshold of an error_rate rule must be a ratio between 0 and 1")
+		}
+		if r.WindowMinutes <= 0 {
+			return errors.New("window_minutes must be positive")
+		}
+	case model.AlertRuleUsageSpike:
+		if r.ResourceType != "global" && r.ResourceType != "model" && r.ResourceType != "client" {
+			return errors.New("usage_spike rules can only be scoped to a model or client")
+		}
+		if r.Threshold <= 1 {
+			return errors.New("threshold of a usage_spike rule must be a multiplier greater than 1")
+		}
+		if r.WindowMinutes <= 0 || r.BaselineWindowMinutes <= 0 {
+			return errors.New("window_minutes and baseline_window_minutes must be positive")
+		}
+	case model.AlertRuleServerUnreachable:
+		if r.ResourceType != "server" {
+			return errors.New("server_unreachable rules must be scoped to a server")
+		}
+	default:
+		return fmt.Errorf(
+			"invalid alert rule type '%s', must be one of daily_cost, monthly_cost, error_rate, usage_spike, server_unreachable",
+			r.Type,
+		)
+	}
+
+	if r.MinSamples < 0 {
+		return errors.New("min_samples must not be negative")
+	}
+	return nil
+}
+
+// CreateAlertRule validates and stores a new alert rule.
+func (s *AnalyticsService) CreateAlertRule(r *model.AlertRule) error {
+	if err := validateAlertRule(r); err != nil {
+		return err
+	}
+	if err := s.db.Create(r).Error; err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return nil
+}
+
+// ListAlertRules returns all alert rules.
+func (s *AnalyticsService) ListAlertRules() ([]model.AlertRule, error) {
+	var rules []model.AlertRule
+	if err := s.db.Order("name").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule deletes an alert rule by name.
+// Any open alert raised by the rule is resolved because nothing would resolve it anymore.
+func (s *AnalyticsService) DeleteAlertRule(name string) error {
+	var r model.AlertRule
+	if err := s.db.Where("name = ?", name).First(&r).Error; err != nil {
+		return fmt.Errorf("failed to get alert rule %s: %w", name, err)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&model.Alert{}).
+			Where("rule_id = ? AND resolved = ?", r.ID, false).
+			Updates(map[string]any{"resolved": true, "resolved_at": &now}).Error
+		if err != nil {
+			return fmt.Errorf("failed to resolve open alerts of rule %s: %w", name, err)
+		}
+		if err := tx.Delete(&r).Error; err != nil {
+			return fmt.Errorf("failed to delete alert rule %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// StartThresholdMonitor runs CheckThresholds every interval in the background until ctx is cancelled.
+func (s *AnalyticsService) StartThresholdMonitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.CheckThresholds(ctx); err != nil {
+					slog.ErrorContext(ctx, "threshold check failed", logging.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// CheckThresholds evaluates all enabled alert rules.
+// A rule whose condition holds raises an alert, unless it already has an open one, in which case
+// the open alert is updated with the latest value. A rule whose condition has cleared resolves its open alert.
+// A failure to evaluate one rule does not prevent the others from being evaluated.
+func (s *AnalyticsService) CheckThresholds(ctx context.Context) error {
+	var rules []model.AlertRule
+	if err := s.db.Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	var errs []error
+	for i := range rules {
+		if err := s.checkAlertRule(ctx, &rules[i]); err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", rules[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkAlertRule evaluates a single rule and raises, updates or resolves its alert accordingly.
+func (s *AnalyticsService) checkAlertRule(ctx context.Context, r *model.AlertRule) error {
+	value, triggered, err := s.evaluateAlertRule(ctx, r, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	var open model.Alert
+	err = s.db.Where("rule_id = ? AND resolved = ?", r.ID, false).First(&open).Error
+	hasOpen := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to look up open alert: %w", err)
+	}
+
+	switch {
+	case triggered && hasOpen:
+		// the condition persists, only refresh the alert instead of raising a duplicate
+		return s.db.Model(&open).Updates(map[string]any{
+			"current_value": value,
+			"message":       alertRuleMessage(r, value),
+		}).Error
+	case triggered:
+		alert := &model.Alert{
+			Type:         string(r.Type),
+			Title:        alertRuleTitle(r),
+			Message:      alertRuleMessage(r, value),
+			Severity:     r.Severity,
+			Threshold:    &r.Threshold,
+			CurrentValue: &value,
+			ResourceType: r.ResourceType,
+			ResourceID:   r.ResourceID,
+			RuleID:       &r.ID,
+		}
+		return s.CreateAlert(alert)
+	case hasOpen:
+		return s.ResolveAlert(open.ID.String())
+	}
+	return nil
+}
+
+// ResolveAlert marks an alert as resolved.
+// Resolving an alert that is already resolved is a no-op.
+func (s *AnalyticsService) ResolveAlert(id string) error {
+	var alert model.Alert
+	if err := s.db.Where("id = ?", id).First(&alert).Error; err != nil {
+		return fmt.Errorf("failed to get alert %s: %w", id, err)
+	}
+	if alert.Resolved {
+		return nil
+	}
+
+	now := time.Now()
+	err := s.db.Model(&alert).Updates(map[string]any{"resolved": true, "resolved_at": &now}).Error
+	if err != nil {
+		return fmt.Errorf("failed to resolve alert %s: %w", id, err)
+	}
+	alert.Resolved = true
+	alert.ResolvedAt = &now
+	if s.notificationService != nil {
+		s.notificationService.Notify(AlertEventResolved, &alert)
+	}
+	return nil
+}
+
+// evaluateAlertRule computes the current value of the rule's metric and whether the rule is triggered.
+func (s *AnalyticsService) evaluateAlertRule(ctx context.Context, r *model.AlertRule, now time.Time) (float64, bool, error) {
+	switch r.Type {
+	case model.AlertRuleDailyCost:
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		cost, err := s.costSince(r, start)
+		return cost, err == nil && cost >= r.Threshold, err
+
+	case model.AlertRuleMonthlyCost:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		cost, err := s.costSince(r, start)
+		return cost, err == nil && cost >= r.Threshold, err
+
+	case model.AlertRuleErrorRate:
+		q := s.db.Model(&model.ToolCall{}).
+			Where("timestamp >= ?", now.Add(-time.Duration(r.WindowMinutes)*time.Minute))
+		if r.ResourceType == "server" {
+			q = q.Where("server_name = ?", *r.ResourceID)
+		}
+		var total, failed int64
+		err := q.Select("COUNT(*), COALESCE(SUM(CASE WHEN success THEN 0 ELSE 1 END), 0)").
+			Row().Scan(&total, &failed)
+		if err != nil {
+			return 0, false, err
+		}
+		if total == 0 {
+			return 0, false, nil
+		}
+		rate := float64(failed) / float64(total)
+		return rate, total >= int64(r.MinSamples) && rate >= r.Threshold, nil
+
+	case model.AlertRuleUsageSpike:
+		window := time.Duration(r.WindowMinutes) * time.Minute
+		baselineWindow := time.Duration(r.BaselineWindowMinutes) * time.Minute
+		current, count, err := s.tokensBetween(r, now.Add(-window), now)
+		if err != nil {
+			return 0, false, err
+		}
+		baseline, _, err := s.tokensBetween(r, now.Add(-window-baselineWindow), now.Add(-window))
+		if err != nil {
+			return 0, false, err
+		}
+		// normalize the baseline to the length of the evaluation window
+		baseline = baseline * window.Seconds() / baselineWindow.Seconds()
+		if baseline == 0 {
+			// without a baseline there is nothing to compare against
+			return 0, false, nil
+		}
+		ratio := current / baseline
+		return ratio, count >= int64(r.MinSamples) && ratio >= r.Threshold, nil
+
+	case model.AlertRuleServerUnreachable:
+		// the server isn't probed again, the outcome of the latest periodic health check decides
+		var check model.ServerHealthCheck
+		err := s.db.WithContext(ctx).Where("server_name = ?", *r.ResourceID).Order("checked_at DESC").First(&check).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the server hasn't been checked yet
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to get the latest health check of MCP server %s: %w", *r.ResourceID, err)
+		}
+		if !check.Healthy {
+			return 1, true, nil
+		}
+		return 0, false, nil
+	}
+	return 0, false, fmt.Errorf("unsupported alert rule type '%s'", r.Type)
+}
+
+// usageMetricScope narrows a usage metric query down to the resource the rule is scoped to.
+func usageMetricScope(q *gorm.DB, r *model.AlertRule) *gorm.DB {
+	switch r.ResourceType {
+	case "model":
+		return q.Where("model = ?", *r.ResourceID)
+	case "client":
+		return q.Where("client_type = ?", *r.ResourceID)
+	}
+	return q
+}
+
+// costSince returns the total cost of usage recorded since the given time.
+func (s *AnalyticsService) costSince(r *model.AlertRule, start time.Time) (float64, error) {
+	var cost float64
+	q := usageMetricScope(s.db.Model(&model.UsageMetric{}).Where("timestamp >= ?", start), r)
+	err := q.Select("COALESCE(SUM(cost), 0)").Row().Scan(&cost)
+	return cost, err
+}
+
+// tokensBetween returns the total number of tokens and the number of usage records in [start, end).
+func (s *AnalyticsService) tokensBetween(r *model.AlertRule, start, end time.Time) (float64, int64, error) {
+	var tokens float64
+	var count int64
+	q := usageMetricScope(
+		s.db.Model(&model.UsageMetric{}).Where("timestamp >= ? AND timestamp < ?", start, end),
+		r,
+	)
+	err := q.Select("COALESCE(SUM(total_tokens), 0), COUNT(*)").Row().Scan(&tokens, &count)
+	return tokens, count, err
+}
+
+func alertRuleTitle(r *model.AlertRule) string {
+	var title string
+	switch r.Type {
+	case model.AlertRuleDailyCost:
+		title = "Daily cost limit exceeded"
+	case model.AlertRuleMonthlyCost:
+		title = "Monthly cost limit exceeded"
+	case model.AlertRuleErrorRate:
+		title = "High tool call error rate"
+	case model.AlertRuleUsageSpike:
+		title = "Token usage spike"
+	case model.AlertRuleServerUnreachable:
+		title = "MCP server unreachable"
+	}
+	return fmt.Sprintf("%s (%s)", title, r.Name)
+}
+
+func alertRuleMessage(r *model.AlertRule, value float64) string {
+	scope := "all resources"
+	if r.ResourceID != nil {
+		scope = fmt.Sprintf("%s %s", r.ResourceType, *r.ResourceID)
+	}
+	switch r.Type {
+	case model.AlertRuleDailyCost:
+		return fmt.Sprintf("Cost today for %s is $%.2f, limit is $%.2f", scope, value, r.Threshold)
+	case model.AlertRuleMonthlyCost:
+		return fmt.Sprintf("Cost this month for %s is $%.2f, limit is $%.2f", scope, value, r.Threshold)
+	case model.AlertRuleErrorRate:
+		return fmt.Sprintf(
+			"%.1f%% of tool calls to %s failed in the last %d minutes, threshold is %.1f%%",
+			value*100, scope, r.WindowMinutes, r.Threshold*100,
+		)
+	case model.AlertRuleUsageSpike:
+		return fmt.Sprintf(
+			"Token usage of %s in the last %d minutes is %.1fx the baseline, threshold is %.1fx",
+			scope, r.WindowMinutes, value, r.Threshold,
+		)
+	case model.AlertRuleServerUnreachable:
+		return fmt.Sprintf("MCPJungle cannot connect to MCP server %s", *r.ResourceID)
+	}
+	return ""
+}
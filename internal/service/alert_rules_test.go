@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/migrations"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB creates a migrated in-memory SQLite database that lives as long as the test.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// every connection to an in-memory database gets its own database, so only use one
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := migrations.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestCheckThresholdsDeduplicatesAndResolves(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+
+	rule := &model.AlertRule{Name: "daily-budget", Type: model.AlertRuleDailyCost, Threshold: 10, Enabled: true}
+	if err := s.CreateAlertRule(rule); err != nil {
+		t.Fatalf("CreateAlertRule() error = %v", err)
+	}
+
+	recordCost := func(cost float64) {
+		t.Helper()
+		err := s.RecordUsage(&model.UsageMetric{Model: "m", ClientType: "c", Cost: &cost, Timestamp: time.Now()})
+		if err != nil {
+			t.Fatalf("RecordUsage() error = %v", err)
+		}
+	}
+	openAlerts := func() []model.Alert {
+		t.Helper()
+		alerts, err := s.GetActiveAlerts()
+		if err != nil {
+			t.Fatalf("GetActiveAlerts() error = %v", err)
+		}
+		return alerts
+	}
+	check := func() {
+		t.Helper()
+		if err := s.CheckThresholds(context.Background()); err != nil {
+			t.Fatalf("CheckThresholds() error = %v", err)
+		}
+	}
+
+	recordCost(4)
+	check()
+	if got := len(openAlerts()); got != 0 {
+		t.Fatalf("got %d open alerts below the threshold, want 0", got)
+	}
+
+	recordCost(7)
+	check()
+	check()
+	alerts := openAlerts()
+	if len(alerts) != 1 {
+		t.Fatalf("got %d open alerts while the condition persists, want 1", len(alerts))
+	}
+	if alerts[0].RuleID == nil || *alerts[0].RuleID != rule.ID {
+		t.Errorf("alert is not linked to the rule that raised it")
+	}
+
+	// lowering the cost below the threshold clears the condition
+	if err := db.Model(&model.UsageMetric{}).Where("1 = 1").Update("cost", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	check()
+	if got := len(openAlerts()); got != 0 {
+		t.Fatalf("got %d open alerts after the condition cleared, want 0", got)
+	}
+	var resolved model.Alert
+	if err := db.First(&resolved, "id = ?", alerts[0].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !resolved.Resolved || resolved.ResolvedAt == nil {
+		t.Errorf("alert was not marked as resolved: %+v", resolved)
+	}
+}
+
+func TestDeleteAlertRuleNotFound(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	if err := s.DeleteAlertRule("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteAlertRule() error = %v, want a not found error", err)
+	}
+}
+
+func TestUnknownAlertNotFound(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	id := "00000000-0000-0000-0000-000000000000"
+	if err := s.AcknowledgeAlert(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("AcknowledgeAlert() error = %v, want a not found error", err)
+	}
+	if err := s.ResolveAlert(id); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("ResolveAlert() error = %v, want a not found error", err)
+	}
+	n := NewNotificationService(s.db)
+	if err := n.TestNotificationChannel(context.Background(), "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("TestNotificationChannel() error = %v, want a not found error", err)
+	}
+}
+
+func TestServerUnreachableRuleUsesHealthChecks(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	ctx := context.Background()
+	serverName := "github"
+	rule := &model.AlertRule{Name: "github-down", Type: model.AlertRuleServerUnreachable, ResourceType: "server", ResourceID: &serverName}
+
+	// a server that hasn't been checked yet isn't reported
+	if _, triggered, err := s.evaluateAlertRule(ctx, rule, time.Now()); err != nil || triggered {
+		t.Fatalf("evaluateAlertRule() = %v, %v before any health check, want not triggered", triggered, err)
+	}
+
+	now := time.Now()
+	checks := []model.ServerHealthCheck{
+		{ServerName: serverName, CheckedAt: now.Add(-time.Minute), Healthy: true},
+		{ServerName: serverName, CheckedAt: now, Healthy: false, Error: "connection refused"},
+	}
+	if err := s.db.Create(&checks).Error; err != nil {
+		t.Fatalf("failed to create health checks: %v", err)
+	}
+	if _, triggered, err := s.evaluateAlertRule(ctx, rule, now); err != nil || !triggered {
+		t.Fatalf("evaluateAlertRule() = %v, %v after a failed health check, want triggered", triggered, err)
+	}
+
+	if err := s.db.Create(&model.ServerHealthCheck{ServerName: serverName, CheckedAt: now.Add(time.Minute), Healthy: true}).Error; err != nil {
+		t.Fatalf("failed to create health check: %v", err)
+	}
+	if _, triggered, err := s.evaluateAlertRule(ctx, rule, now); err != nil || triggered {
+		t.Errorf("evaluateAlertRule() = %v, %v once the server recovered, want not triggered", triggered, err)
+	}
+}
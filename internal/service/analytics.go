@@ -2,30 +2,115 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/duaraghav8/mcpjungle/internal/model"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// maxUsageClockSkew is how far in the future the timestamp of a reported usage may be,
+// to tolerate clients whose clocks are slightly ahead of the registry's.
+const maxUsageClockSkew = 5 * time.Minute
+
+// ErrInvalidUsage is wrapped by the errors of RecordUsage for malformed usage,
+// as opposed to the errors of storing well-formed usage.
+var ErrInvalidUsage = errors.New("invalid usage")
+
 type AnalyticsService struct {
 	db *gorm.DB
+
+	// notificationService delivers alerts to notification channels, it is optional
+	notificationService *NotificationService
+
+	// reservations holds the units of call quotas taken by the calls in flight
+	reservations *quotaReservations
 }
 
-func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
-	return &AnalyticsService{db: db}
+func NewAnalyticsService(db *gorm.DB, notificationService *NotificationService) *AnalyticsService {
+	return &AnalyticsService{db: db, notificationService: notificationService, reservations: newQuotaReservations()}
 }
 
-// RecordUsage records token usage and cost metrics
-func (s *AnalyticsService) RecordUsage(usage model.UsageMetric) error {
-	usage.TotalTokens = usage.InputTokens + usage.OutputTokens
-	return s.db.Create(&usage).Error
+// RecordUsage records token usage and cost metrics.
+// If a metric has no cost, its cost is computed from the pricing catalog.
+// All the metrics are validated before any of them is stored, and they are stored atomically.
+// Tool calls made in the same MCP session that aren't linked to any usage yet are linked
+// to the metric that reports the LLM usage of the session up to the metric's timestamp.
+func (s *AnalyticsService) RecordUsage(usages ...*model.UsageMetric) error {
+	now := time.Now()
+	for i, u := range usages {
+		if err := validateUsageMetric(u); err != nil {
+			if len(usages) > 1 {
+				return fmt.Errorf("%w at index %d: %w", ErrInvalidUsage, i, err)
+			}
+			return fmt.Errorf("%w: %w", ErrInvalidUsage, err)
+		}
+		if u.Timestamp.IsZero() {
+			u.Timestamp = now
+		}
+		u.TotalTokens = u.InputTokens + u.OutputTokens
+		if err := s.applyCatalogCost(u); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, u := range usages {
+			if err := tx.Create(u).Error; err != nil {
+				return fmt.Errorf("failed to record usage: %w", err)
+			}
+			if u.SessionID == "" {
+				continue
+			}
+			err := tx.Model(&model.ToolCall{}).
+				Where("session_id = ? AND usage_metric_id IS NULL AND timestamp <= ?", u.SessionID, u.Timestamp).
+				Updates(map[string]any{"usage_metric_id": u.ID, "model": u.Model}).Error
+			if err != nil {
+				return fmt.Errorf("failed to link tool calls of session %s to usage: %w", u.SessionID, err)
+			}
+		}
+		return nil
+	})
 }
 
-// RecordToolCall records individual tool invocation metrics
+// validateUsageMetric checks that a usage metric reported by a client is well-formed.
+func validateUsageMetric(u *model.UsageMetric) error {
+	if u.Model == "" {
+		return errors.New("model is required")
+	}
+	if u.ClientType == "" {
+		return errors.New("client_type is required")
+	}
+	if u.InputTokens < 0 || u.OutputTokens < 0 || u.CacheReadTokens < 0 || u.CacheWriteTokens < 0 {
+		return errors.New("token counts cannot be negative")
+	}
+	if u.Cost != nil && *u.Cost < 0 {
+		return errors.New("cost cannot be negative")
+	}
+	if u.Timestamp.After(time.Now().Add(maxUsageClockSkew)) {
+		return errors.New("timestamp cannot be in the future")
+	}
+	return nil
+}
+
+// RecordToolCall records individual tool invocation metrics.
+// If the call was made in an MCP session for which usage has already been reported,
+// the call is linked to the session's latest usage, ie, the LLM turn that most likely requested it.
 func (s *AnalyticsService) RecordToolCall(toolCall model.ToolCall) error {
+	if toolCall.SessionID != "" && toolCall.UsageMetricID == nil {
+		var usage model.UsageMetric
+		err := s.db.Where("session_id = ? AND timestamp <= ?", toolCall.SessionID, toolCall.Timestamp).
+			Order("timestamp DESC").
+			First(&usage).Error
+		switch {
+		case err == nil:
+			toolCall.UsageMetricID = &usage.ID
+			toolCall.Model = usage.Model
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return fmt.Errorf("failed to look up usage of session %s: %w", toolCall.SessionID, err)
+		}
+	}
 	return s.db.Create(&toolCall).Error
 }
 
@@ -65,14 +150,14 @@ func (s *AnalyticsService) GetUsageByTimeframe(timeframe string, clientType *str
 
 	// Get top models
 	var topModels []struct {
-		Model      string  `json:"model"`
-		CallCount  int     `json:"call_count"`
-		TotalCost  float64 `json:"total_cost"`
-		TotalTokens int    `json:"total_tokens"`
+		Model       string  `json:"model"`
+		CallCount   int     `json:"call_count"`
+		TotalCost   float64 `json:"total_cost"`
+		TotalTokens int     `json:"total_tokens"`
 	}
 
 	err = query.Select("model, COUNT(*) as call_count, SUM(cost) as total_cost, SUM(total_tokens) as total_tokens").
-		Group("model").Order("call_count DESC").Limit(10).Scan(&topModels)
+		Group("model").Order("call_count DESC").Limit(10).Scan(&topModels).Error
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +171,7 @@ func (s *AnalyticsService) GetUsageByTimeframe(timeframe string, clientType *str
 	}
 
 	err = query.Select("DATE(timestamp) as date, SUM(total_tokens) as total_tokens, SUM(cost) as total_cost, COUNT(*) as call_count").
-		Group("DATE(timestamp)").Order("date").Scan(&trends)
+		Group("DATE(timestamp)").Order("date").Scan(&trends).Error
 	if err != nil {
 		return nil, err
 	}
@@ -122,38 +207,41 @@ func (s *AnalyticsService) GetToolUsageStats(timeframe string) (map[string]inter
 
 	// Get top tools
 	var topTools []struct {
-		ToolName     string  `json:"tool_name"`
-		ServerName   string  `json:"server_name"`
-		CallCount    int     `json:"call_count"`
-		SuccessRate  float64 `json:"success_rate"`
+		ToolName        string  `json:"tool_name"`
+		ServerName      string  `json:"server_name"`
+		CallCount       int     `json:"call_count"`
+		SuccessRate     float64 `json:"success_rate"`
 		AvgResponseTime float64 `json:"avg_response_time"`
+		// RetriedCalls is the number of calls that needed more than one attempt
+		RetriedCalls int `json:"retried_calls"`
 	}
 
 	err := s.db.Model(&model.ToolCall{}).
-		Select("tool_name, server_name, COUNT(*) as call_count, AVG(CASE WHEN success THEN 1.0 ELSE 0.0 END) as success_rate, AVG(response_time) as avg_response_time").
+		Select("tool_name, server_name, COUNT(*) as call_count, AVG(CASE WHEN success THEN 1.0 ELSE 0.0 END) as success_rate, AVG(response_time) as avg_response_time, SUM(CASE WHEN attempts > 1 THEN 1 ELSE 0 END) as retried_calls").
 		Where("timestamp >= ?", startDate).
 		Group("tool_name, server_name").
 		Order("call_count DESC").
 		Limit(20).
-		Scan(&topTools)
+		Scan(&topTools).Error
 	if err != nil {
 		return nil, err
 	}
 
 	// Get server performance
 	var serverStats []struct {
-		ServerName   string  `json:"server_name"`
-		CallCount    int     `json:"call_count"`
-		SuccessRate  float64 `json:"success_rate"`
+		ServerName      string  `json:"server_name"`
+		CallCount       int     `json:"call_count"`
+		SuccessRate     float64 `json:"success_rate"`
 		AvgResponseTime float64 `json:"avg_response_time"`
+		RetriedCalls    int     `json:"retried_calls"`
 	}
 
 	err = s.db.Model(&model.ToolCall{}).
-		Select("server_name, COUNT(*) as call_count, AVG(CASE WHEN success THEN 1.0 ELSE 0.0 END) as success_rate, AVG(response_time) as avg_response_time").
+		Select("server_name, COUNT(*) as call_count, AVG(CASE WHEN success THEN 1.0 ELSE 0.0 END) as success_rate, AVG(response_time) as avg_response_time, SUM(CASE WHEN attempts > 1 THEN 1 ELSE 0 END) as retried_calls").
 		Where("timestamp >= ?", startDate).
 		Group("server_name").
 		Order("call_count DESC").
-		Scan(&serverStats)
+		Scan(&serverStats).Error
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +282,7 @@ func (s *AnalyticsService) GetClientUsageBreakdown(timeframe string) ([]map[stri
 		Where("timestamp >= ?", startDate).
 		Group("client_type").
 		Order("total_cost DESC").
-		Scan(&clientBreakdown)
+		Scan(&clientBreakdown).Error
 	if err != nil {
 		return nil, err
 	}
@@ -238,7 +326,7 @@ func (s *AnalyticsService) GenerateCostSummary(period string, startDate, endDate
 	err = s.db.Model(&model.UsageMetric{}).
 		Select("model, SUM(cost) as cost, SUM(total_tokens) as tokens, COUNT(*) as call_count").
 		Where("timestamp BETWEEN ? AND ?", startDate, endDate).
-		Group("model").Scan(&modelBreakdown)
+		Group("model").Scan(&modelBreakdown).Error
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +349,7 @@ func (s *AnalyticsService) GenerateCostSummary(period string, startDate, endDate
 	err = s.db.Model(&model.ToolCall{}).
 		Select("server_name, COUNT(*) as call_count").
 		Where("timestamp BETWEEN ? AND ?", startDate, endDate).
-		Group("server_name").Scan(&serverBreakdown)
+		Group("server_name").Scan(&serverBreakdown).Error
 	if err != nil {
 		return nil, err
 	}
@@ -284,19 +372,14 @@ func (s *AnalyticsService) GenerateCostSummary(period string, startDate, endDate
 }
 
 // CreateAlert creates usage alerts and notifications
-func (s *AnalyticsService) CreateAlert(alertType, title, message, severity string, threshold, currentValue *float64, resourceType, resourceID *string) error {
-	alert := model.Alert{
-		Type:         alertType,
-		Title:        title,
-		Message:      message,
-		Severity:     severity,
-		Threshold:    threshold,
-		CurrentValue: currentValue,
-		ResourceType: resourceType,
-		ResourceID:   resourceID,
-	}
-
-	return s.db.Create(&alert).Error
+func (s *AnalyticsService) CreateAlert(alert *model.Alert) error {
+	if err := s.db.Create(alert).Error; err != nil {
+		return err
+	}
+	if s.notificationService != nil {
+		s.notificationService.Notify(AlertEventRaised, alert)
+	}
+	return nil
 }
 
 // GetActiveAlerts returns unresolved alerts
@@ -306,21 +389,26 @@ func (s *AnalyticsService) GetActiveAlerts() ([]model.Alert, error) {
 	return alerts, err
 }
 
-// CheckThresholds monitors usage and creates alerts when thresholds are exceeded
-func (s *AnalyticsService) CheckThresholds() error {
-	// TODO: Implement threshold monitoring
-	// Check daily/monthly cost limits
-	// Check token usage spikes
-	// Check error rates
-	// Create alerts when thresholds exceeded
-	return nil
+// ListAlerts returns alerts, most recent first.
+// Resolved alerts are only included if includeResolved is true.
+func (s *AnalyticsService) ListAlerts(includeResolved bool) ([]model.Alert, error) {
+	if !includeResolved {
+		return s.GetActiveAlerts()
+	}
+	var alerts []model.Alert
+	err := s.db.Order("created_at DESC").Find(&alerts).Error
+	return alerts, err
 }
 
-// ExportUsageReport generates usage reports for external systems
-func (s *AnalyticsService) ExportUsageReport(startDate, endDate time.Time, format string) ([]byte, error) {
-	// TODO: Implement report export
-	// Support CSV, JSON, PDF formats
-	// Include usage breakdowns, costs, trends
-	// Return formatted report data
-	return nil, fmt.Errorf("export functionality not implemented yet")
-}
\ No newline at end of file
+// AcknowledgeAlert marks an alert as acknowledged.
+// An acknowledged alert stays open until it is resolved.
+func (s *AnalyticsService) AcknowledgeAlert(id string) error {
+	res := s.db.Model(&model.Alert{}).Where("id = ?", id).Update("acknowledged", true)
+	if res.Error != nil {
+		return fmt.Errorf("failed to acknowledge alert %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("alert %s: %w", id, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
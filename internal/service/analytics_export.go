@@ -0,0 +1,455 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+)
+
+// ReportFormat is the output format of an exported usage report.
+type ReportFormat string
+
+const (
+	ReportFormatCSV    ReportFormat = "csv"
+	ReportFormatJSON   ReportFormat = "json"
+	ReportFormatNDJSON ReportFormat = "ndjson"
+)
+
+// ParseReportFormat validates the user-supplied report format.
+func ParseReportFormat(format string) (ReportFormat, error) {
+	switch f := ReportFormat(format); f {
+	case ReportFormatCSV, ReportFormatJSON, ReportFormatNDJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported report format '%s', must be one of csv, json, ndjson", format)
+	}
+}
+
+// ContentType returns the MIME type to use when serving a report in this format.
+func (f ReportFormat) ContentType() string {
+	switch f {
+	case ReportFormatCSV:
+		return "text/csv"
+	case ReportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// UsageReportSummary contains the totals of a usage report.
+type UsageReportSummary struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	CallCount    int64     `json:"call_count"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+	Cost         float64   `json:"cost"`
+}
+
+// UsageBreakdown aggregates usage for a single model, client or server in a usage report.
+type UsageBreakdown struct {
+	Name         string  `json:"name"`
+	CallCount    int64   `json:"call_count"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// usageReportCSVHeader is the header row of CSV reports.
+// Every row carries a record_type (summary, model, client, server or usage) so that
+// breakdowns and individual usage records can live in a single flat file.
+var usageReportCSVHeader = []string{
+	"record_type",
+	"key",
+	"timestamp",
+	"session_id",
+	"model",
+	"client_type",
+	"call_count",
+	"input_tokens",
+	"output_tokens",
+	"total_tokens",
+	"cost",
+}
+
+// usageReportWriter writes the sections of a usage report in a particular format.
+type usageReportWriter interface {
+	writeSummary(s *UsageReportSummary) error
+	writeBreakdown(recordType string, b *UsageBreakdown) error
+	writeUsage(u *model.UsageMetric) error
+	close() error
+}
+
+// UsageReport is a usage report whose totals and breakdowns have been computed.
+// Its individual usage records are only read from the database when the report is written.
+type UsageReport struct {
+	s          *AnalyticsService
+	summary    *UsageReportSummary
+	breakdowns []usageReportBreakdown
+}
+
+// usageReportBreakdown is one breakdown section of a usage report.
+type usageReportBreakdown struct {
+	recordType string
+	rows       []UsageBreakdown
+}
+
+// NewUsageReport computes the totals and the per-model, per-client and per-server breakdowns
+// of a usage report for the [startDate, endDate) window.
+// Computing them before writing anything lets callers report a failure before a response is committed.
+func (s *AnalyticsService) NewUsageReport(startDate, endDate time.Time) (*UsageReport, error) {
+	if !endDate.After(startDate) {
+		return nil, fmt.Errorf("invalid report window: end date %s must be after start date %s", endDate, startDate)
+	}
+
+	summary, err := s.usageReportSummary(startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute usage summary: %w", err)
+	}
+	r := &UsageReport{s: s, summary: summary}
+
+	queries := []struct {
+		recordType string
+		query      func(startDate, endDate time.Time) ([]UsageBreakdown, error)
+	}{
+		{"model", s.usageBreakdownByModel},
+		{"client", s.usageBreakdownByClient},
+		{"server", s.usageBreakdownByServer},
+	}
+	for _, q := range queries {
+		rows, err := q.query(startDate, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute usage breakdown by %s: %w", q.recordType, err)
+		}
+		r.breakdowns = append(r.breakdowns, usageReportBreakdown{recordType: q.recordType, rows: rows})
+	}
+	return r, nil
+}
+
+// ExportUsageReport writes a usage report for the [startDate, endDate) window to w.
+// It is a shorthand for NewUsageReport followed by UsageReport.Write.
+func (s *AnalyticsService) ExportUsageReport(w io.Writer, startDate, endDate time.Time, format ReportFormat) error {
+	r, err := s.NewUsageReport(startDate, endDate)
+	if err != nil {
+		return err
+	}
+	return r.Write(w, format)
+}
+
+// Write writes the report to w: the totals and breakdowns followed by every individual usage record.
+// Usage records are streamed from the database row by row,
+// so the report is never held in memory in its entirety.
+func (r *UsageReport) Write(w io.Writer, format ReportFormat) error {
+	var rw usageReportWriter
+	switch format {
+	case ReportFormatCSV:
+		rw = newCSVReportWriter(w)
+	case ReportFormatJSON:
+		rw = &jsonReportWriter{w: w}
+	case ReportFormatNDJSON:
+		rw = &ndjsonReportWriter{enc: json.NewEncoder(w)}
+	default:
+		return fmt.Errorf("unsupported report format '%s'", format)
+	}
+
+	if err := rw.writeSummary(r.summary); err != nil {
+		return err
+	}
+	for _, b := range r.breakdowns {
+		for i := range b.rows {
+			if err := rw.writeBreakdown(b.recordType, &b.rows[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	rows, err := r.s.db.Model(&model.UsageMetric{}).
+		Where("timestamp >= ? AND timestamp < ?", r.summary.From, r.summary.To).
+		Order("timestamp").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query usage records: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u model.UsageMetric
+		if err := r.s.db.ScanRows(rows, &u); err != nil {
+			return fmt.Errorf("failed to read usage record: %w", err)
+		}
+		if err := rw.writeUsage(&u); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read usage records: %w", err)
+	}
+
+	return rw.close()
+}
+
+// usageReportSummary computes the usage totals for the given window.
+func (s *AnalyticsService) usageReportSummary(startDate, endDate time.Time) (*UsageReportSummary, error) {
+	summary := &UsageReportSummary{From: startDate, To: endDate}
+	err := s.db.Model(&model.UsageMetric{}).
+		Select(
+			"COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), "+
+				"COALESCE(SUM(total_tokens), 0), COALESCE(SUM(cost), 0)",
+		).
+		Where("timestamp >= ? AND timestamp < ?", startDate, endDate).
+		Row().
+		Scan(&summary.CallCount, &summary.InputTokens, &summary.OutputTokens, &summary.TotalTokens, &summary.Cost)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (s *AnalyticsService) usageBreakdownByModel(startDate, endDate time.Time) ([]UsageBreakdown, error) {
+	return s.usageMetricBreakdown("model", startDate, endDate)
+}
+
+func (s *AnalyticsService) usageBreakdownByClient(startDate, endDate time.Time) ([]UsageBreakdown, error) {
+	return s.usageMetricBreakdown("client_type", startDate, endDate)
+}
+
+// usageMetricBreakdown aggregates usage metrics grouped by the given column.
+func (s *AnalyticsService) usageMetricBreakdown(column string, startDate, endDate time.Time) ([]UsageBreakdown, error) {
+	var breakdown []UsageBreakdown
+	err := s.db.Model(&model.UsageMetric{}).
+		Select(
+			column+" as name, COUNT(*) as call_count, COALESCE(SUM(input_tokens), 0) as input_tokens, "+
+				"COALESCE(SUM(output_tokens), 0) as output_tokens, COALESCE(SUM(total_tokens), 0) as total_tokens, "+
+				"COALESCE(SUM(cost), 0) as cost",
+		).
+		Where("timestamp >= ? AND timestamp < ?", startDate, endDate).
+		Group(column).
+		Order("cost DESC").
+		Scan(&breakdown).Error
+	return breakdown, err
+}
+
+// usageBreakdownByServer aggregates tool calls grouped by the upstream MCP server.
+// Each call is charged a share of the cost of the usage it is linked to, ie, the LLM turn that requested it.
+// The cost of a turn that requested several tool calls is split evenly between them,
+// so that a turn is never counted twice across servers.
+func (s *AnalyticsService) usageBreakdownByServer(startDate, endDate time.Time) ([]UsageBreakdown, error) {
+	var breakdown []UsageBreakdown
+	err := s.db.Model(&model.ToolCall{}).
+		Select(
+			"tool_calls.server_name as name, COUNT(*) as call_count, "+
+				"COALESCE(SUM(tool_calls.input_tokens), 0) as input_tokens, "+
+				"COALESCE(SUM(tool_calls.output_tokens), 0) as output_tokens, "+
+				"COALESCE(SUM(tool_calls.input_tokens), 0) + COALESCE(SUM(tool_calls.output_tokens), 0) as total_tokens, "+
+				"COALESCE(SUM(usage_metrics.cost / linked.calls), 0) as cost",
+		).
+		Joins("LEFT JOIN usage_metrics ON usage_metrics.id = tool_calls.usage_metric_id").
+		Joins(
+			"LEFT JOIN (SELECT usage_metric_id, COUNT(*) as calls FROM tool_calls "+
+				"WHERE usage_metric_id IS NOT NULL GROUP BY usage_metric_id) linked "+
+				"ON linked.usage_metric_id = tool_calls.usage_metric_id",
+		).
+		Where("tool_calls.timestamp >= ? AND tool_calls.timestamp < ?", startDate, endDate).
+		Group("tool_calls.server_name").
+		Order("call_count DESC").
+		Scan(&breakdown).Error
+	return breakdown, err
+}
+
+// csvReportWriter writes a usage report as a single flat CSV table.
+type csvReportWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVReportWriter(w io.Writer) *csvReportWriter {
+	return &csvReportWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvReportWriter) write(record []string) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(usageReportCSVHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvReportWriter) writeSummary(s *UsageReportSummary) error {
+	return c.write([]string{
+		"summary",
+		s.From.Format(time.RFC3339) + "/" + s.To.Format(time.RFC3339),
+		"",
+		"",
+		"",
+		"",
+		strconv.FormatInt(s.CallCount, 10),
+		strconv.FormatInt(s.InputTokens, 10),
+		strconv.FormatInt(s.OutputTokens, 10),
+		strconv.FormatInt(s.TotalTokens, 10),
+		formatCost(s.Cost),
+	})
+}
+
+func (c *csvReportWriter) writeBreakdown(recordType string, b *UsageBreakdown) error {
+	return c.write([]string{
+		recordType,
+		b.Name,
+		"",
+		"",
+		"",
+		"",
+		strconv.FormatInt(b.CallCount, 10),
+		strconv.FormatInt(b.InputTokens, 10),
+		strconv.FormatInt(b.OutputTokens, 10),
+		strconv.FormatInt(b.TotalTokens, 10),
+		formatCost(b.Cost),
+	})
+}
+
+func (c *csvReportWriter) writeUsage(u *model.UsageMetric) error {
+	return c.write([]string{
+		"usage",
+		u.ID.String(),
+		u.Timestamp.UTC().Format(time.RFC3339),
+		u.SessionID,
+		u.Model,
+		u.ClientType,
+		"1",
+		strconv.Itoa(u.InputTokens),
+		strconv.Itoa(u.OutputTokens),
+		strconv.Itoa(u.TotalTokens),
+		formatCost(derefCost(u.Cost)),
+	})
+}
+
+func (c *csvReportWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonReportSections are the keys of the arrays in a JSON report, in the order they are written.
+var jsonReportSections = []string{"by_model", "by_client", "by_server", "records"}
+
+// jsonReportWriter writes a usage report as a single JSON document.
+// The document is written incrementally, the summary is followed by the arrays in jsonReportSections.
+// A section is always present in the document, even if it has no elements.
+type jsonReportWriter struct {
+	w io.Writer
+
+	// opened is the number of sections from jsonReportSections that have been opened so far
+	opened int
+	// empty is true if no element has been written to the current section yet
+	empty bool
+}
+
+// enterSection makes the given section the current one, opening (and closing) all sections before it.
+func (j *jsonReportWriter) enterSection(section string) error {
+	for j.opened == 0 || jsonReportSections[j.opened-1] != section {
+		if j.opened == len(jsonReportSections) {
+			return fmt.Errorf("cannot write to section %s of JSON report after it has been closed", section)
+		}
+		prefix := ","
+		if j.opened > 0 {
+			prefix = "],"
+		}
+		if _, err := io.WriteString(j.w, prefix+strconv.Quote(jsonReportSections[j.opened])+":["); err != nil {
+			return err
+		}
+		j.opened++
+		j.empty = true
+	}
+	return nil
+}
+
+func (j *jsonReportWriter) writeElement(section string, v any) error {
+	if err := j.enterSection(section); err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !j.empty {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.empty = false
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonReportWriter) writeSummary(s *UsageReportSummary) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, `{"summary":%s`, b)
+	return err
+}
+
+func (j *jsonReportWriter) writeBreakdown(recordType string, b *UsageBreakdown) error {
+	return j.writeElement("by_"+recordType, b)
+}
+
+func (j *jsonReportWriter) writeUsage(u *model.UsageMetric) error {
+	return j.writeElement("records", u)
+}
+
+func (j *jsonReportWriter) close() error {
+	if err := j.enterSection(jsonReportSections[len(jsonReportSections)-1]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+// ndjsonReportWriter writes a usage report as newline-delimited JSON, one record per line.
+// Each line has the shape {"type": "<record type>", "data": {...}}.
+type ndjsonReportWriter struct {
+	enc *json.Encoder
+}
+
+type ndjsonRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+func (n *ndjsonReportWriter) writeSummary(s *UsageReportSummary) error {
+	return n.enc.Encode(ndjsonRecord{Type: "summary", Data: s})
+}
+
+func (n *ndjsonReportWriter) writeBreakdown(recordType string, b *UsageBreakdown) error {
+	return n.enc.Encode(ndjsonRecord{Type: recordType, Data: b})
+}
+
+func (n *ndjsonReportWriter) writeUsage(u *model.UsageMetric) error {
+	return n.enc.Encode(ndjsonRecord{Type: "usage", Data: u})
+}
+
+func (n *ndjsonReportWriter) close() error {
+	return nil
+}
+
+// derefCost returns the value of a cost, 0 if it isn't set.
+func derefCost(cost *float64) float64 {
+	if cost == nil {
+		return 0
+	}
+	return *cost
+}
+
+// formatCost formats a cost value with the same precision as it is stored in the DB.
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 6, 64)
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+)
+
+func TestJSONReportWriter(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name       string
+		breakdowns map[string][]UsageBreakdown
+		records    int
+	}{
+		{"empty report", nil, 0},
+		{"only records", nil, 3},
+		{
+			"breakdowns and records",
+			map[string][]UsageBreakdown{
+				"model":  {{Name: "claude-sonnet-4", CallCount: 2}, {Name: "gpt-4o", CallCount: 1}},
+				"server": {{Name: "github", CallCount: 5}},
+			},
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &jsonReportWriter{w: &buf}
+			if err := w.writeSummary(&UsageReportSummary{From: from, To: to}); err != nil {
+				t.Fatal(err)
+			}
+			for _, recordType := range []string{"model", "client", "server"} {
+				for i := range tt.breakdowns[recordType] {
+					if err := w.writeBreakdown(recordType, &tt.breakdowns[recordType][i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			for i := 0; i < tt.records; i++ {
+				if err := w.writeUsage(&model.UsageMetric{Model: "claude-sonnet-4", Timestamp: from}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got struct {
+				Summary  UsageReportSummary  `json:"summary"`
+				ByModel  []UsageBreakdown    `json:"by_model"`
+				ByClient []UsageBreakdown    `json:"by_client"`
+				ByServer []UsageBreakdown    `json:"by_server"`
+				Records  []model.UsageMetric `json:"records"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("report is not valid JSON: %v\n%s", err, buf.String())
+			}
+			if !got.Summary.From.Equal(from) || !got.Summary.To.Equal(to) {
+				t.Errorf("summary window = [%s, %s), want [%s, %s)", got.Summary.From, got.Summary.To, from, to)
+			}
+			if len(got.ByModel) != len(tt.breakdowns["model"]) {
+				t.Errorf("got %d model rows, want %d", len(got.ByModel), len(tt.breakdowns["model"]))
+			}
+			if len(got.ByServer) != len(tt.breakdowns["server"]) {
+				t.Errorf("got %d server rows, want %d", len(got.ByServer), len(tt.breakdowns["server"]))
+			}
+			if got.ByClient == nil || got.Records == nil {
+				t.Errorf("empty sections must be present as empty arrays: %s", buf.String())
+			}
+			if len(got.Records) != tt.records {
+				t.Errorf("got %d records, want %d", len(got.Records), tt.records)
+			}
+		})
+	}
+}
+
+func TestCSVReportWriter(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name  string
+		write func(w *csvReportWriter) error
+		want  []string
+	}{
+		{
+			"summary",
+			func(w *csvReportWriter) error {
+				return w.writeSummary(&UsageReportSummary{From: from, To: to, CallCount: 3, TotalTokens: 30, Cost: 0.5})
+			},
+			[]string{"summary", "2025-06-01T00:00:00Z/2025-07-01T00:00:00Z", "", "", "", "", "3", "0", "0", "30", "0.500000"},
+		},
+		{
+			"breakdown with a comma and quotes in its name",
+			func(w *csvReportWriter) error {
+				return w.writeBreakdown("client", &UsageBreakdown{Name: `acme, "the" agent`, CallCount: 2})
+			},
+			[]string{"client", `acme, "the" agent`, "", "", "", "", "2", "0", "0", "0", "0.000000"},
+		},
+		{
+			"usage record",
+			func(w *csvReportWriter) error {
+				return w.writeUsage(&model.UsageMetric{
+					Timestamp: from, SessionID: "s,1", Model: "gpt-4o", ClientType: "cursor",
+					InputTokens: 10, OutputTokens: 5, TotalTokens: 15, Cost: floatPtr(0.000123),
+				})
+			},
+			[]string{
+				"usage", "00000000-0000-0000-0000-000000000000", "2025-06-01T00:00:00Z", "s,1", "gpt-4o", "cursor",
+				"1", "10", "5", "15", "0.000123",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newCSVReportWriter(&buf)
+			if err := tt.write(w); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("report is not valid CSV: %v", err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("got %d rows, want the header and one record", len(rows))
+			}
+			if strings.Join(rows[0], ",") != strings.Join(usageReportCSVHeader, ",") {
+				t.Errorf("header = %v, want %v", rows[0], usageReportCSVHeader)
+			}
+			if strings.Join(rows[1], "|") != strings.Join(tt.want, "|") {
+				t.Errorf("record = %q, want %q", rows[1], tt.want)
+			}
+		})
+	}
+
+	// fields with commas and quotes are quoted, with their quotes doubled
+	var buf bytes.Buffer
+	w := newCSVReportWriter(&buf)
+	_ = w.writeBreakdown("model", &UsageBreakdown{Name: `a,"b"`})
+	_ = w.close()
+	if !strings.Contains(buf.String(), `model,"a,""b""",`) {
+		t.Errorf("expected the name to be escaped, got %s", buf.String())
+	}
+}
+
+func TestExportUsageReportDateRange(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	usages := []struct {
+		session   string
+		timestamp time.Time
+	}{
+		{"before", from.Add(-time.Second)},
+		{"at-start", from},
+		{"inside", from.AddDate(0, 0, 3)},
+		{"at-end", to},
+		{"after", to.Add(time.Hour)},
+	}
+	for _, u := range usages {
+		metric := &model.UsageMetric{Model: "gpt-4o", ClientType: "cursor", SessionID: u.session, Timestamp: u.timestamp}
+		if err := db.Create(metric).Error; err != nil {
+			t.Fatalf("failed to create usage metric: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []string
+		wantErr  bool
+	}{
+		{"start is inclusive, end is exclusive", from, to, []string{"at-start", "inside"}, false},
+		{"window without usage", to.AddDate(1, 0, 0), to.AddDate(1, 0, 1), nil, false},
+		{"whole history", from.AddDate(-1, 0, 0), to.AddDate(1, 0, 0), []string{"before", "at-start", "inside", "at-end", "after"}, false},
+		{"empty window", from, from, nil, true},
+		{"inverted window", to, from, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := s.ExportUsageReport(&buf, tt.from, tt.to, ReportFormatNDJSON)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected the window to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sessions []string
+			var summary UsageReportSummary
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var rec struct {
+					Type string          `json:"type"`
+					Data json.RawMessage `json:"data"`
+				}
+				if err := dec.Decode(&rec); err != nil {
+					t.Fatalf("invalid NDJSON line: %v", err)
+				}
+				switch rec.Type {
+				case "summary":
+					_ = json.Unmarshal(rec.Data, &summary)
+				case "usage":
+					var u model.UsageMetric
+					_ = json.Unmarshal(rec.Data, &u)
+					sessions = append(sessions, u.SessionID)
+				}
+			}
+			if strings.Join(sessions, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got records %v, want %v", sessions, tt.want)
+			}
+			if summary.CallCount != int64(len(tt.want)) {
+				t.Errorf("summary counts %d calls, want %d", summary.CallCount, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestNewUsageReportFailsBeforeWriting(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := s.NewUsageReport(from, from.AddDate(0, 1, 0)); err == nil {
+		t.Fatal("expected a database failure to be reported before the report is written")
+	}
+}
+
+func TestUsageBreakdownByServerCost(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cost := 3.0
+	usage := &model.UsageMetric{Model: "gpt-4o", ClientType: "cursor", SessionID: "s", Cost: &cost, Timestamp: from}
+	if err := db.Create(usage).Error; err != nil {
+		t.Fatalf("failed to create usage metric: %v", err)
+	}
+	// the turn requested two calls to fs and one to git, and an unlinked call to git was made too
+	calls := []struct {
+		server string
+		linked bool
+	}{{"fs", true}, {"fs", true}, {"git", true}, {"git", false}}
+	for _, c := range calls {
+		call := model.ToolCall{ToolName: "t", ServerName: c.server, Model: "gpt-4o", ClientType: "cursor", Timestamp: from.Add(time.Minute)}
+		if c.linked {
+			call.UsageMetricID = &usage.ID
+		}
+		if err := s.RecordToolCall(call); err != nil {
+			t.Fatalf("failed to record tool call: %v", err)
+		}
+	}
+
+	breakdown, err := s.usageBreakdownByServer(from, from.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	costs := make(map[string]float64)
+	for _, b := range breakdown {
+		costs[b.Name] = b.Cost
+	}
+	if costs["fs"] != 2 || costs["git"] != 1 {
+		t.Errorf("got server costs %v, want fs=2 and git=1", costs)
+	}
+}
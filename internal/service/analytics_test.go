@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+)
+
+func TestRecordUsageLinksSessionToolCalls(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+	start := time.Now().Add(-time.Minute)
+
+	recordCall := func(session string, ts time.Time) {
+		t.Helper()
+		tc := model.ToolCall{
+			ToolName:   "add",
+			ServerName: "calculator",
+			Model:      toolCallModelUnknown,
+			ClientType: toolCallClientTypeMCP,
+			Success:    true,
+			SessionID:  session,
+			Timestamp:  ts,
+		}
+		if err := s.RecordToolCall(tc); err != nil {
+			t.Fatalf("RecordToolCall() error = %v", err)
+		}
+	}
+	callsOf := func(session string) []model.ToolCall {
+		t.Helper()
+		var calls []model.ToolCall
+		if err := db.Where("session_id = ?", session).Order("timestamp").Find(&calls).Error; err != nil {
+			t.Fatal(err)
+		}
+		return calls
+	}
+
+	// a call made before any usage is reported is linked once the usage arrives
+	recordCall("s1", start)
+	recordCall("s2", start)
+	usage := &model.UsageMetric{Model: "claude-sonnet-4", ClientType: "claude", InputTokens: 100, OutputTokens: 20, SessionID: "s1"}
+	if err := s.RecordUsage(usage); err != nil {
+		t.Fatalf("RecordUsage() error = %v", err)
+	}
+	if usage.TotalTokens != 120 {
+		t.Errorf("TotalTokens = %d, want 120", usage.TotalTokens)
+	}
+
+	// a call made after usage was reported is linked to the session's latest usage
+	recordCall("s1", time.Now())
+
+	for _, c := range callsOf("s1") {
+		if c.UsageMetricID == nil || *c.UsageMetricID != usage.ID {
+			t.Errorf("call at %v is not linked to the session's usage", c.Timestamp)
+		}
+		if c.Model != "claude-sonnet-4" {
+			t.Errorf("call model = %q, want the model of the linked usage", c.Model)
+		}
+	}
+	if c := callsOf("s2")[0]; c.UsageMetricID != nil {
+		t.Errorf("call of another session was linked to the usage")
+	}
+}
+
+func TestRecordUsageBatchIsAtomic(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+
+	err := s.RecordUsage(
+		&model.UsageMetric{Model: "m", ClientType: "c", InputTokens: 1},
+		&model.UsageMetric{Model: "m", ClientType: "c", InputTokens: -1},
+	)
+	if err == nil {
+		t.Fatal("RecordUsage() accepted a batch with an invalid record")
+	}
+
+	var count int64
+	if err := db.Model(&model.UsageMetric{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("got %d usage records stored from a rejected batch, want 0", count)
+	}
+}
+
+func TestRecordUsageErrors(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAnalyticsService(db, nil)
+
+	err := s.RecordUsage(&model.UsageMetric{Model: "m", ClientType: "c"}, &model.UsageMetric{Model: "m"})
+	if !errors.Is(err, ErrInvalidUsage) {
+		t.Errorf("RecordUsage() error = %v, want an invalid usage error", err)
+	}
+
+	// failing to store well-formed usage is not the reporter's fault
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+	err = s.RecordUsage(&model.UsageMetric{Model: "m", ClientType: "c"})
+	if err == nil || errors.Is(err, ErrInvalidUsage) {
+		t.Errorf("RecordUsage() error = %v, want a storage error", err)
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultCapturePayloadBytes is the size cap of captured payloads for servers that don't configure one
+	defaultCapturePayloadBytes = 64 * 1024
+	// maxCapturePayloadBytes is the largest size cap that a server can configure
+	maxCapturePayloadBytes = 1024 * 1024
+
+	// redactedValue replaces the values of redacted fields
+	redactedValue = "[REDACTED]"
+
+	defaultCapturedCallsLimit = 50
+	maxCapturedCallsLimit     = 500
+)
+
+// defaultRedactFields are always redacted from captured payloads.
+// Field names are matched case-insensitively, ignoring dashes and underscores (eg- apiKey matches api_key).
+var defaultRedactFields = []string{
+	"password", "passwd", "secret", "token", "apikey", "accesstoken", "refreshtoken", "clientsecret", "authorization",
+}
+
+// CapturedCallFilter selects the captured calls to list, zero fields don't filter.
+type CapturedCallFilter struct {
+	ServerName string
+	ToolName   string
+	SessionID  string
+	RequestID  string
+	// OnlyErrors only selects the calls that failed
+	OnlyErrors bool
+	// Since and Until bound the time of the calls, Since is exclusive so that new calls can be polled for
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of calls returned, the most recent first
+	Limit int
+}
+
+func validateCallCaptureConfig(cfg *model.CallCaptureConfig) error {
+	if cfg.ServerName == "" {
+		return errors.New("server name is required")
+	}
+	if cfg.MaxPayloadBytes < 0 || cfg.MaxPayloadBytes > maxCapturePayloadBytes {
+		return fmt.Errorf("max payload bytes must be between 0 (the default of %d) and %d", defaultCapturePayloadBytes, maxCapturePayloadBytes)
+	}
+	return nil
+}
+
+// SetCallCaptureConfig enables capturing the payloads of the tool calls made to a server,
+// replacing its existing capture config if any.
+func (m *MCPService) SetCallCaptureConfig(cfg *model.CallCaptureConfig) error {
+	if err := validateCallCaptureConfig(cfg); err != nil {
+		return err
+	}
+	if _, err := m.GetMcpServer(cfg.ServerName); err != nil {
+		return fmt.Errorf("failed to get MCP server %s: %w", cfg.ServerName, err)
+	}
+
+	var existing model.CallCaptureConfig
+	err := m.db.Where("server_name = ?", cfg.ServerName).First(&existing).Error
+	switch {
+	case err == nil:
+		cfg.ID = existing.ID
+		cfg.CreatedAt = existing.CreatedAt
+		if err := m.db.Save(cfg).Error; err != nil {
+			return fmt.Errorf("failed to update capture config of server %s: %w", cfg.ServerName, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := m.db.Create(cfg).Error; err != nil {
+			return fmt.Errorf("failed to create capture config of server %s: %w", cfg.ServerName, err)
+		}
+	default:
+		return fmt.Errorf("failed to look up capture config of server %s: %w", cfg.ServerName, err)
+	}
+	return nil
+}
+
+// ListCallCaptureConfigs returns the capture configs of all servers that have capture enabled.
+func (m *MCPService) ListCallCaptureConfigs() ([]model.CallCaptureConfig, error) {
+	var configs []model.CallCaptureConfig
+	if err := m.db.Order("server_name").Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// DeleteCallCaptureConfig disables capturing the payloads of the tool calls made to a server.
+// The calls captured so far are kept.
+func (m *MCPService) DeleteCallCaptureConfig(serverName string) error {
+	res := m.db.Where("server_name = ?", serverName).Delete(&model.CallCaptureConfig{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete capture config of server %s: %w", serverName, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("capture is not enabled for server %s: %w", serverName, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// ListCapturedCalls returns the captured calls selected by the filter, the most recent first.
+func (m *MCPService) ListCapturedCalls(filter CapturedCallFilter) ([]model.CapturedCall, error) {
+	q := m.db.Model(&model.CapturedCall{})
+	if filter.ServerName != "" {
+		q = q.Where("server_name = ?", filter.ServerName)
+	}
+	if filter.ToolName != "" {
+		q = q.Where("tool_name = ?", filter.ToolName)
+	}
+	if filter.SessionID != "" {
+		q = q.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.RequestID != "" {
+		q = q.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.OnlyErrors {
+		q = q.Where("is_error = ?", true)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("timestamp > ?", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		q = q.Where("timestamp <= ?", filter.Until.UTC())
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultCapturedCallsLimit
+	}
+	limit = min(limit, maxCapturedCallsLimit)
+
+	var calls []model.CapturedCall
+	if err := q.Order("timestamp DESC").Limit(limit).Find(&calls).Error; err != nil {
+		return nil, fmt.Errorf("failed to list captured calls: %w", err)
+	}
+	return calls, nil
+}
+
+// GetCapturedCall returns a captured call by ID.
+func (m *MCPService) GetCapturedCall(id string) (*model.CapturedCall, error) {
+	var call model.CapturedCall
+	if err := m.db.Where("id = ?", id).First(&call).Error; err != nil {
+		return nil, fmt.Errorf("failed to get captured call %s: %w", id, err)
+	}
+	return &call, nil
+}
+
+// captureToolCall stores the full payloads of a tool call if its server has capture enabled.
+// Like analytics, capture is best-effort: a failure is logged and never fails the call itself.
+func (m *MCPService) captureToolCall(
+	ctx context.Context,
+	serverName string,
+	request mcp.CallToolRequest,
+	elapsed time.Duration,
+	attempts int,
+	result *mcp.CallToolResult,
+	callErr error,
+	errorType string,
+) {
+	// the call is captured even if its caller has gone away in the meantime
+	dbCtx := context.WithoutCancel(ctx)
+	var cfg model.CallCaptureConfig
+	if err := m.db.WithContext(dbCtx).Where("server_name = ?", serverName).First(&cfg).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.ErrorContext(ctx, "failed to look up the capture config", logging.Err(err))
+		}
+		return
+	}
+
+	redact := redactFieldSet(cfg.RedactFields)
+	maxBytes := cfg.MaxPayloadBytes
+	if maxBytes == 0 {
+		maxBytes = defaultCapturePayloadBytes
+	}
+
+	call := model.CapturedCall{
+		ServerName: serverName,
+		ToolName:   request.Params.Name,
+		RequestID:  logging.RequestID(ctx),
+		ClientType: toolCallClientType(ctx),
+		IsError:    errorType != "",
+		ErrorType:  errorType,
+		DurationMs: int(elapsed.Milliseconds()),
+		Attempts:   attempts,
+		// UTC, so that the timestamps compare correctly with the bounds of ListCapturedCalls
+		Timestamp: time.Now().UTC(),
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		call.SessionID = session.SessionID()
+	}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	}
+	call.Arguments, call.ArgumentsTruncated = capturePayload(request.Params.Arguments, redact, maxBytes)
+	if result != nil {
+		call.Result, call.ResultTruncated = capturePayload(result, redact, maxBytes)
+	}
+
+	if err := m.db.WithContext(dbCtx).Create(&call).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to capture tool call", logging.Err(err))
+	}
+}
+
+// normalizeFieldName makes field names comparable regardless of their case and word separators.
+func normalizeFieldName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// redactFieldSet returns the normalized names of the fields to redact: the defaults and the configured ones.
+func redactFieldSet(configured string) map[string]bool {
+	fields := make(map[string]bool)
+	for _, f := range defaultRedactFields {
+		fields[f] = true
+	}
+	for _, f := range strings.Split(configured, ",") {
+		if f = normalizeFieldName(f); f != "" {
+			fields[f] = true
+		}
+	}
+	return fields
+}
+
+// capturePayload encodes a payload as JSON with the values of the fields to redact replaced, at any depth.
+// Payloads larger than maxBytes are truncated, in which case it also returns true.
+func capturePayload(payload any, redact map[string]bool, maxBytes int) (string, bool) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf("failed to encode payload: %v", err), false
+	}
+	// round-trip through a generic value so that struct payloads (eg- results) are redacted too
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err == nil {
+		if redacted, err := json.Marshal(redactValue(generic, redact)); err == nil {
+			raw = redacted
+		}
+	}
+	if len(raw) > maxBytes {
+		// cut on a rune boundary, so that the stored payload stays valid UTF-8
+		n := maxBytes
+		for n > 0 && !utf8.RuneStart(raw[n]) {
+			n--
+		}
+		return string(raw[:n]), true
+	}
+	return string(raw), false
+}
+
+func redactValue(v any, redact map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if redact[normalizeFieldName(k)] {
+				v[k] = redactedValue
+			} else {
+				v[k] = redactValue(child, redact)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redactValue(child, redact)
+		}
+	}
+	return v
+}
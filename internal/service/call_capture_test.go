@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+func TestCapturePayloadRedactsAndTruncates(t *testing.T) {
+	redact := redactFieldSet("ssn, Home-Address")
+	args := map[string]any{
+		"query":        "weather",
+		"API_KEY":      "k",
+		"accessToken":  "t",
+		"home_address": "1 main st",
+		"nested":       []any{map[string]any{"ssn": "123", "keep": 1}},
+	}
+	payload, truncated := capturePayload(args, redact, defaultCapturePayloadBytes)
+	if truncated {
+		t.Fatalf("expected a small payload not to be truncated")
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(payload), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %s", payload)
+	}
+	for _, k := range []string{"API_KEY", "accessToken", "home_address"} {
+		if got[k] != redactedValue {
+			t.Errorf("expected %s to be redacted, got %v", k, got[k])
+		}
+	}
+	nested := got["nested"].([]any)[0].(map[string]any)
+	if nested["ssn"] != redactedValue || nested["keep"] != float64(1) || got["query"] != "weather" {
+		t.Errorf("expected only the redacted fields to change, got %s", payload)
+	}
+
+	// results are structs, they are redacted too
+	result := mcp.NewToolResultText("the secret is out")
+	result.Meta = map[string]any{"token": "t"}
+	payload, _ = capturePayload(result, redact, defaultCapturePayloadBytes)
+	if strings.Contains(payload, `"t"`) || !strings.Contains(payload, "the secret is out") {
+		t.Errorf("expected the token of the result to be redacted, got %s", payload)
+	}
+
+	payload, truncated = capturePayload(map[string]any{"text": strings.Repeat("a", 100)}, redact, 50)
+	if !truncated || len(payload) != 50 {
+		t.Errorf("expected the payload to be truncated to 50 bytes, got %d bytes (truncated: %v)", len(payload), truncated)
+	}
+
+	// multi-byte characters aren't cut in half
+	payload, truncated = capturePayload(map[string]any{"text": strings.Repeat("é", 100)}, redact, 50)
+	if !truncated || len(payload) != 49 || !utf8.ValidString(payload) {
+		t.Errorf("expected the payload to be truncated to 49 bytes of valid UTF-8, got %d bytes: %q", len(payload), payload)
+	}
+}
+
+func TestCaptureToolCall(t *testing.T) {
+	m := newTestMCPService(t)
+	ctx := context.Background()
+	if err := m.db.Create(&model.McpServer{Name: "github", URL: "http://localhost/mcp"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "create_issue"
+	request.Params.Arguments = map[string]any{"title": "bug", "password": "hunter2"}
+
+	// calls are not captured until capture is enabled
+	m.captureToolCall(ctx, "github", request, time.Millisecond, 1, mcp.NewToolResultText("ok"), nil, "")
+	if calls, _ := m.ListCapturedCalls(CapturedCallFilter{}); len(calls) != 0 {
+		t.Fatalf("expected no captured calls without a capture config, got %d", len(calls))
+	}
+
+	if err := m.SetCallCaptureConfig(&model.CallCaptureConfig{ServerName: "unknown"}); err == nil {
+		t.Errorf("expected enabling capture for an unknown server to fail")
+	}
+	if err := m.SetCallCaptureConfig(&model.CallCaptureConfig{ServerName: "github", MaxPayloadBytes: -1}); err == nil {
+		t.Errorf("expected a negative size cap to be rejected")
+	}
+	if err := m.SetCallCaptureConfig(&model.CallCaptureConfig{ServerName: "github", RedactFields: "title"}); err != nil {
+		t.Fatalf("failed to enable capture: %v", err)
+	}
+
+	start := time.Now()
+	m.captureToolCall(ctx, "github", request, 30*time.Millisecond, 2, mcp.NewToolResultText("created"), nil, "")
+	// the caller of the failed call has gone away by the time it is captured
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	m.captureToolCall(cancelled, "github", request, time.Millisecond, 1, nil, context.DeadlineExceeded, model.ToolCallErrorTimeout)
+
+	calls, err := m.ListCapturedCalls(CapturedCallFilter{ServerName: "github"})
+	if err != nil {
+		t.Fatalf("failed to list captured calls: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 captured calls, got %d", len(calls))
+	}
+	failed, succeeded := calls[0], calls[1]
+	if !failed.IsError || failed.ErrorType != model.ToolCallErrorTimeout || failed.Result != "" || failed.Error == "" {
+		t.Errorf("expected the most recent call to be the failed one, got %+v", failed)
+	}
+	if succeeded.IsError || succeeded.Attempts != 2 || succeeded.DurationMs != 30 || !strings.Contains(succeeded.Result, "created") {
+		t.Errorf("unexpected captured call %+v", succeeded)
+	}
+	if strings.Contains(succeeded.Arguments, "hunter2") || strings.Contains(succeeded.Arguments, "bug") {
+		t.Errorf("expected the password and the configured field to be redacted, got %s", succeeded.Arguments)
+	}
+
+	got, err := m.GetCapturedCall(succeeded.ID.String())
+	if err != nil || got.ToolName != "create_issue" {
+		t.Errorf("failed to get the captured call by ID: %v", err)
+	}
+
+	filters := map[string]CapturedCallFilter{
+		"errors":      {OnlyErrors: true},
+		"limit":       {Limit: 1},
+		"tool":        {ServerName: "github", ToolName: "create_issue", OnlyErrors: true},
+		"time window": {Since: start.Add(-time.Minute), Until: time.Now(), OnlyErrors: true},
+	}
+	for name, filter := range filters {
+		calls, err := m.ListCapturedCalls(filter)
+		if err != nil || len(calls) != 1 {
+			t.Errorf("%s: expected 1 captured call, got %d (%v)", name, len(calls), err)
+		}
+	}
+	if calls, _ := m.ListCapturedCalls(CapturedCallFilter{Since: time.Now()}); len(calls) != 0 {
+		t.Errorf("expected no calls made after now, got %d", len(calls))
+	}
+
+	if err := m.DeleteCallCaptureConfig("github"); err != nil {
+		t.Fatalf("failed to disable capture: %v", err)
+	}
+	m.captureToolCall(ctx, "github", request, time.Millisecond, 1, mcp.NewToolResultText("ok"), nil, "")
+	if calls, _ := m.ListCapturedCalls(CapturedCallFilter{}); len(calls) != 2 {
+		t.Errorf("expected the captured calls to be kept and no new call captured, got %d", len(calls))
+	}
+}
+
+func TestDeleteCallCaptureConfigNotFound(t *testing.T) {
+	m := newTestMCPService(t)
+	if err := m.DeleteCallCaptureConfig("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteCallCaptureConfig() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestDeregisterServerDisablesCapture(t *testing.T) {
+	m := newTestMCPService(t)
+	if err := m.db.Create(&model.McpServer{Name: "github", URL: "http://localhost/mcp"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := m.SetCallCaptureConfig(&model.CallCaptureConfig{ServerName: "github"}); err != nil {
+		t.Fatalf("SetCallCaptureConfig() error = %v", err)
+	}
+	if err := m.DeregisterMcpServer("github"); err != nil {
+		t.Fatalf("DeregisterMcpServer() error = %v", err)
+	}
+	if configs, _ := m.ListCallCaptureConfigs(); len(configs) != 0 {
+		t.Errorf("expected the capture config of the deregistered server to be deleted, got %+v", configs)
+	}
+}
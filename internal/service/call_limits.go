@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// CallThrottledMetaKey is the key of the _meta entry that describes the limit
+// in the result of a tool call rejected by the MCP proxy.
+const CallThrottledMetaKey = "mcpjungle/throttled"
+
+// defaultQueueTimeout is how long a call waits for a limit in queue mode if the limit doesn't specify it.
+const defaultQueueTimeout = 30 * time.Second
+
+const (
+	throttleReasonRate        = "rate_limit"
+	throttleReasonConcurrency = "concurrency_limit"
+)
+
+// CallThrottledError is returned when a tool call is rejected by a rate or concurrency limit.
+type CallThrottledError struct {
+	ScopeType  model.CallScope `json:"scope_type"`
+	ScopeValue string          `json:"scope_value"`
+	// Reason is either rate_limit or concurrency_limit
+	Reason string `json:"reason"`
+	// RetryAfter is a hint of how long to wait before retrying, it is only set for rate limits
+	RetryAfter   time.Duration `json:"-"`
+	RetryAfterMs int64         `json:"retry_after_ms,omitempty"`
+}
+
+func (e *CallThrottledError) Error() string {
+	msg := fmt.Sprintf("call throttled by the %s of %s %s", e.Reason, e.ScopeType, e.ScopeValue)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter.Round(time.Millisecond))
+	}
+	return msg
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+func (e *CallThrottledError) ToolResult() *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Result:  mcp.Result{Meta: map[string]any{CallThrottledMetaKey: e}},
+		Content: []mcp.Content{mcp.NewTextContent(e.Error())},
+		IsError: true,
+	}
+}
+
+// CallLimitStats counts how the calls within a limit's scope were treated since the registry started.
+type CallLimitStats struct {
+	Allowed             int64 `json:"allowed"`
+	Queued              int64 `json:"queued"`
+	RejectedRate        int64 `json:"rejected_rate"`
+	RejectedConcurrency int64 `json:"rejected_concurrency"`
+	InFlight            int64 `json:"in_flight"`
+}
+
+// CallLimitStatus is a call limit along with its statistics.
+type CallLimitStatus struct {
+	model.CallLimit
+	Stats CallLimitStats `json:"stats"`
+}
+
+func validateCallLimit(l *model.CallLimit) error {
+	if err := validateCallScope(l.ScopeType, l.ScopeValue); err != nil {
+		return err
+	}
+	if l.MaxConcurrent < 0 || l.RatePerSecond < 0 || l.Burst < 0 || l.QueueTimeoutMs < 0 {
+		return errors.New("limits cannot be negative")
+	}
+	if l.MaxConcurrent == 0 && l.RatePerSecond == 0 {
+		return errors.New("at least one of max_concurrent and rate_per_second must be set")
+	}
+	if l.RatePerSecond > 0 && l.Burst == 0 {
+		l.Burst = int(math.Max(1, math.Ceil(l.RatePerSecond)))
+	}
+
+	if l.Mode == "" {
+		l.Mode = model.CallLimitModeReject
+	}
+	switch l.Mode {
+	case model.CallLimitModeReject:
+	case model.CallLimitModeQueue:
+		if l.QueueTimeoutMs == 0 {
+			l.QueueTimeoutMs = int(defaultQueueTimeout.Milliseconds())
+		}
+	default:
+		return fmt.Errorf("invalid limit mode '%s', must be one of reject, queue", l.Mode)
+	}
+	return nil
+}
+
+// SetCallLimit creates or replaces the call limit of a server or tool.
+// The new limit applies to calls made after it is set.
+func (m *MCPService) SetCallLimit(l *model.CallLimit) error {
+	if err := validateCallLimit(l); err != nil {
+		return err
+	}
+
+	var existing model.CallLimit
+	err := m.db.Where("scope_type = ? AND scope_value = ?", l.ScopeType, l.ScopeValue).First(&existing).Error
+	switch {
+	case err == nil:
+		l.ID = existing.ID
+		l.CreatedAt = existing.CreatedAt
+		err = m.db.Save(l).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = m.db.Create(l).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the limit of %s %s: %w", l.ScopeType, l.ScopeValue, err)
+	}
+
+	m.limiter.set(*l)
+	return nil
+}
+
+// ListCallLimits returns all call limits along with their statistics.
+func (m *MCPService) ListCallLimits() ([]CallLimitStatus, error) {
+	var limits []model.CallLimit
+	if err := m.db.Order("scope_type").Order("scope_value").Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	statuses := make([]CallLimitStatus, len(limits))
+	for i, l := range limits {
+		statuses[i] = CallLimitStatus{CallLimit: l, Stats: m.limiter.stats(l.ScopeType, l.ScopeValue)}
+	}
+	return statuses, nil
+}
+
+// DeleteCallLimit removes the call limit of a server or tool.
+func (m *MCPService) DeleteCallLimit(scopeType model.CallScope, scopeValue string) error {
+	res := m.db.Where("scope_type = ? AND scope_value = ?", scopeType, scopeValue).Delete(&model.CallLimit{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete the limit of %s %s: %w", scopeType, scopeValue, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s %s has no limit: %w", scopeType, scopeValue, gorm.ErrRecordNotFound)
+	}
+	m.limiter.remove(scopeType, scopeValue)
+	return nil
+}
+
+// loadCallLimits loads all call limits from the database into the limiter.
+func (m *MCPService) loadCallLimits() error {
+	var limits []model.CallLimit
+	if err := m.db.Find(&limits).Error; err != nil {
+		return fmt.Errorf("failed to load call limits: %w", err)
+	}
+	for _, l := range limits {
+		m.limiter.set(l)
+	}
+	return nil
+}
+
+// callLimiter enforces the call limits of servers and tools in memory.
+type callLimiter struct {
+	mu       sync.RWMutex
+	states   map[string]*limitState
+	counters map[string]*limitCounters
+}
+
+func newCallLimiter() *callLimiter {
+	return &callLimiter{
+		states:   make(map[string]*limitState),
+		counters: make(map[string]*limitCounters),
+	}
+}
+
+func limitKey(scopeType model.CallScope, scopeValue string) string {
+	return string(scopeType) + ":" + scopeValue
+}
+
+// set installs a limit, replacing the previous limit of the same scope.
+// Calls already in flight keep holding their slot of the previous limit until they complete.
+func (l *callLimiter) set(limit model.CallLimit) {
+	st := &limitState{limit: limit, timeout: time.Duration(limit.QueueTimeoutMs) * time.Millisecond}
+	if limit.MaxConcurrent > 0 {
+		st.sem = make(chan struct{}, limit.MaxConcurrent)
+	}
+	if limit.RatePerSecond > 0 {
+		st.bucket = newTokenBucket(limit.RatePerSecond, limit.Burst)
+	}
+
+	key := limitKey(limit.ScopeType, limit.ScopeValue)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.states[key] = st
+	if _, ok := l.counters[key]; !ok {
+		l.counters[key] = &limitCounters{}
+	}
+}
+
+func (l *callLimiter) remove(scopeType model.CallScope, scopeValue string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.states, limitKey(scopeType, scopeValue))
+}
+
+func (l *callLimiter) stats(scopeType model.CallScope, scopeValue string) CallLimitStats {
+	l.mu.RLock()
+	c, ok := l.counters[limitKey(scopeType, scopeValue)]
+	l.mu.RUnlock()
+	if !ok {
+		return CallLimitStats{}
+	}
+	return c.snapshot()
+}
+
+// limitScope identifies the server or tool that a limit applies to.
+type limitScope struct {
+	scopeType  model.CallScope
+	scopeValue string
+}
+
+// allStats returns the statistics of every limit set since the registry started, deleted limits included.
+func (l *callLimiter) allStats() map[limitScope]CallLimitStats {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	stats := make(map[limitScope]CallLimitStats, len(l.counters))
+	for key, c := range l.counters {
+		scopeType, scopeValue, _ := strings.Cut(key, ":")
+		stats[limitScope{scopeType: model.CallScope(scopeType), scopeValue: scopeValue}] = c.snapshot()
+	}
+	return stats
+}
+
+// acquire waits until a call to the tool fits within the limits of both the tool and its server.
+// It returns a function that must be called once the call completes.
+func (l *callLimiter) acquire(ctx context.Context, serverName, toolName string) (func(), error) {
+	keys := []string{
+		limitKey(model.CallScopeTool, mergeServerToolNames(serverName, toolName)),
+		limitKey(model.CallScopeServer, serverName),
+	}
+
+	var releases, aborts []func()
+	releaseAll := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, key := range keys {
+		l.mu.RLock()
+		st, c := l.states[key], l.counters[key]
+		l.mu.RUnlock()
+		if st == nil {
+			continue
+		}
+		if err := st.acquire(ctx, c); err != nil {
+			// the call isn't made, so the limits it was already admitted by get back their rate tokens too
+			for _, a := range aborts {
+				a()
+			}
+			return nil, err
+		}
+		releases = append(releases, func() { st.release(c) })
+		aborts = append(aborts, func() {
+			st.release(c)
+			st.unreserve()
+		})
+	}
+	return releaseAll, nil
+}
+
+// limitState is the runtime state of a single call limit.
+type limitState struct {
+	limit   model.CallLimit
+	timeout time.Duration
+	// sem holds a token for every in-flight call, it is nil if concurrency is unlimited
+	sem chan struct{}
+	// bucket is nil if the rate is unlimited
+	bucket *tokenBucket
+}
+
+func (st *limitState) throttled(reason string, retryAfter time.Duration) *CallThrottledError {
+	return &CallThrottledError{
+		ScopeType:    st.limit.ScopeType,
+		ScopeValue:   st.limit.ScopeValue,
+		Reason:       reason,
+		RetryAfter:   retryAfter,
+		RetryAfterMs: retryAfter.Milliseconds(),
+	}
+}
+
+func (st *limitState) acquire(ctx context.Context, c *limitCounters) error {
+	queue := st.limit.Mode == model.CallLimitModeQueue
+	deadline := time.Now().Add(st.timeout)
+	queued := false
+
+	if st.bucket != nil {
+		var maxWait time.Duration
+		if queue {
+			maxWait = st.timeout
+		}
+		wait, ok := st.bucket.reserve(time.Now(), maxWait)
+		if !ok {
+			c.rejectedRate.Add(1)
+			return st.throttled(throttleReasonRate, wait)
+		}
+		if wait > 0 {
+			queued = true
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				st.bucket.cancel()
+				return ctx.Err()
+			}
+		}
+	}
+
+	if st.sem != nil {
+		select {
+		case st.sem <- struct{}{}:
+		default:
+			if !queue {
+				st.unreserve()
+				c.rejectedConcurrency.Add(1)
+				return st.throttled(throttleReasonConcurrency, 0)
+			}
+			queued = true
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			select {
+			case st.sem <- struct{}{}:
+			case <-timer.C:
+				st.unreserve()
+				c.rejectedConcurrency.Add(1)
+				return st.throttled(throttleReasonConcurrency, 0)
+			case <-ctx.Done():
+				st.unreserve()
+				return ctx.Err()
+			}
+		}
+	}
+
+	if queued {
+		c.queued.Add(1)
+	}
+	c.allowed.Add(1)
+	c.inFlight.Add(1)
+	return nil
+}
+
+func (st *limitState) release(c *limitCounters) {
+	if st.sem != nil {
+		<-st.sem
+	}
+	c.inFlight.Add(-1)
+}
+
+// unreserve returns the rate token reserved by a call that won't be made.
+func (st *limitState) unreserve() {
+	if st.bucket != nil {
+		st.bucket.cancel()
+	}
+}
+
+// limitCounters are the statistics of a limit, they survive changes to the limit's settings.
+type limitCounters struct {
+	allowed             atomic.Int64
+	queued              atomic.Int64
+	rejectedRate        atomic.Int64
+	rejectedConcurrency atomic.Int64
+	inFlight            atomic.Int64
+}
+
+func (c *limitCounters) snapshot() CallLimitStats {
+	return CallLimitStats{
+		Allowed:             c.allowed.Load(),
+		Queued:              c.queued.Load(),
+		RejectedRate:        c.rejectedRate.Load(),
+		RejectedConcurrency: c.rejectedConcurrency.Load(),
+		InFlight:            c.inFlight.Load(),
+	}
+}
+
+// tokenBucket is a token bucket rate limiter that supports reservations,
+// so that callers can queue for a token instead of polling.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token and returns how long the caller must wait before the token is available.
+// If the wait would be longer than maxWait, no token is taken and ok is false.
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (wait time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	// the bucket goes into debt, so that queued callers are served in order
+	wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait > maxWait {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel returns a reserved token that won't be used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
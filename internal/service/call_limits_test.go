@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := newTokenBucket(10, 2)
+	now := b.last
+
+	for i := 0; i < 2; i++ {
+		if wait, ok := b.reserve(now, 0); !ok || wait != 0 {
+			t.Fatalf("call %d within burst: got wait %v, ok %v", i, wait, ok)
+		}
+	}
+	if wait, ok := b.reserve(now, 0); ok || wait != 100*time.Millisecond {
+		t.Fatalf("expected the call exceeding the burst to be rejected with a 100ms wait, got %v, %v", wait, ok)
+	}
+	if wait, ok := b.reserve(now, time.Second); !ok || wait != 100*time.Millisecond {
+		t.Fatalf("expected a queued call to wait 100ms, got %v, %v", wait, ok)
+	}
+	// the queued call took the next token, so the call after it waits for the one after that
+	if wait, ok := b.reserve(now, time.Second); !ok || wait != 200*time.Millisecond {
+		t.Fatalf("expected the second queued call to wait 200ms, got %v, %v", wait, ok)
+	}
+	if wait, ok := b.reserve(now.Add(time.Second), 0); !ok || wait != 0 {
+		t.Fatalf("expected the bucket to refill after a second, got %v, %v", wait, ok)
+	}
+}
+
+func TestCallLimiterConcurrencyReject(t *testing.T) {
+	l := newCallLimiter()
+	l.set(model.CallLimit{
+		ScopeType:     model.CallScopeServer,
+		ScopeValue:    "github",
+		MaxConcurrent: 1,
+		Mode:          model.CallLimitModeReject,
+	})
+
+	release, err := l.acquire(context.Background(), "github", "create_issue")
+	if err != nil {
+		t.Fatalf("first call should be allowed: %v", err)
+	}
+	// limits of other servers are unaffected
+	if _, err := l.acquire(context.Background(), "slack", "post"); err != nil {
+		t.Fatalf("call to an unlimited server should be allowed: %v", err)
+	}
+
+	_, err = l.acquire(context.Background(), "github", "list_issues")
+	var throttled *CallThrottledError
+	if !errors.As(err, &throttled) || throttled.Reason != throttleReasonConcurrency {
+		t.Fatalf("expected the second concurrent call to be rejected, got %v", err)
+	}
+
+	release()
+	release2, err := l.acquire(context.Background(), "github", "list_issues")
+	if err != nil {
+		t.Fatalf("call after release should be allowed: %v", err)
+	}
+	release2()
+
+	stats := l.stats(model.CallScopeServer, "github")
+	want := CallLimitStats{Allowed: 2, RejectedConcurrency: 1}
+	if stats != want {
+		t.Errorf("unexpected stats: got %+v, want %+v", stats, want)
+	}
+}
+
+func TestCallLimiterQueue(t *testing.T) {
+	l := newCallLimiter()
+	l.set(model.CallLimit{
+		ScopeType:      model.CallScopeTool,
+		ScopeValue:     "github/create_issue",
+		MaxConcurrent:  1,
+		Mode:           model.CallLimitModeQueue,
+		QueueTimeoutMs: 50,
+	})
+
+	release, err := l.acquire(context.Background(), "github", "create_issue")
+	if err != nil {
+		t.Fatalf("first call should be allowed: %v", err)
+	}
+
+	// the queued call gets the slot as soon as the first call completes
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	release2, err := l.acquire(context.Background(), "github", "create_issue")
+	if err != nil {
+		t.Fatalf("queued call should be allowed once the first call completes: %v", err)
+	}
+
+	// the slot is never freed, so the queued call times out
+	_, err = l.acquire(context.Background(), "github", "create_issue")
+	var throttled *CallThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("expected the queued call to time out, got %v", err)
+	}
+	release2()
+
+	stats := l.stats(model.CallScopeTool, "github/create_issue")
+	want := CallLimitStats{Allowed: 2, Queued: 1, RejectedConcurrency: 1}
+	if stats != want {
+		t.Errorf("unexpected stats: got %+v, want %+v", stats, want)
+	}
+}
+
+func TestCallLimiterReturnsRateTokensOfRejectedCalls(t *testing.T) {
+	l := newCallLimiter()
+	// the bucket doesn't refill during the test, only the burst of 2 tokens is available
+	l.set(model.CallLimit{
+		ScopeType:     model.CallScopeServer,
+		ScopeValue:    "github",
+		MaxConcurrent: 1,
+		RatePerSecond: 0.001,
+		Burst:         2,
+		Mode:          model.CallLimitModeReject,
+	})
+
+	release, err := l.acquire(context.Background(), "github", "create_issue")
+	if err != nil {
+		t.Fatalf("first call should be allowed: %v", err)
+	}
+	// the call rejected by the concurrency limit doesn't use up the second token
+	_, err = l.acquire(context.Background(), "github", "create_issue")
+	var throttled *CallThrottledError
+	if !errors.As(err, &throttled) || throttled.Reason != throttleReasonConcurrency {
+		t.Fatalf("expected the concurrent call to be rejected, got %v", err)
+	}
+	release()
+	release, err = l.acquire(context.Background(), "github", "create_issue")
+	if err != nil {
+		t.Fatalf("expected the call to get the token returned by the rejected call, got %v", err)
+	}
+	release()
+
+	// the same holds for a queued call whose caller gives up
+	l.set(model.CallLimit{
+		ScopeType:      model.CallScopeTool,
+		ScopeValue:     "slack/post",
+		MaxConcurrent:  1,
+		RatePerSecond:  0.001,
+		Burst:          2,
+		Mode:           model.CallLimitModeQueue,
+		QueueTimeoutMs: 1000,
+	})
+	release, err = l.acquire(context.Background(), "slack", "post")
+	if err != nil {
+		t.Fatalf("first call should be allowed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, "slack", "post"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the queued call to be cancelled, got %v", err)
+	}
+	release()
+	release, err = l.acquire(context.Background(), "slack", "post")
+	if err != nil {
+		t.Fatalf("expected the call to get the token returned by the cancelled call, got %v", err)
+	}
+	release()
+}
+
+func TestDeleteCallLimitNotFound(t *testing.T) {
+	m := newTestMCPService(t)
+	if err := m.DeleteCallLimit(model.CallScopeServer, "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteCallLimit() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
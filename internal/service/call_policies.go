@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// ToolCallTimeoutMetaKey is the key of the _meta entry that describes the timeout
+// in the result of a tool call that timed out.
+const ToolCallTimeoutMetaKey = "mcpjungle/timeout"
+
+const (
+	// defaultConnectTimeout applies to servers and tools whose policies don't set a connect timeout
+	defaultConnectTimeout = 10 * time.Second
+	// defaultCallTimeout applies to servers and tools whose policies don't set a call timeout
+	defaultCallTimeout = 60 * time.Second
+	// defaultBreakerFailureThreshold applies to servers whose policies don't configure the circuit breaker
+	defaultBreakerFailureThreshold = 5
+	// defaultBreakerCooldown applies to servers whose policies don't configure the circuit breaker
+	defaultBreakerCooldown = 30 * time.Second
+
+	// retries apply to tools declaring the readOnlyHint or idempotentHint annotations, unless configured otherwise
+	defaultRetryMaxAttempts = 3
+	defaultRetryBackoff     = 200 * time.Millisecond
+	defaultRetryMaxBackoff  = 5 * time.Second
+	defaultRetryOn          = model.ToolCallErrorConnection + "," + model.ToolCallErrorUpstream
+)
+
+const (
+	timeoutPhaseConnect = "connect"
+	timeoutPhaseCall    = "call"
+)
+
+// ToolCallTimeoutError is returned when connecting to the upstream server or
+// the tool call itself takes longer than the call policy allows.
+type ToolCallTimeoutError struct {
+	ServerName string `json:"server_name"`
+	ToolName   string `json:"tool_name"`
+	// Phase is either connect or call
+	Phase     string        `json:"phase"`
+	Timeout   time.Duration `json:"-"`
+	TimeoutMs int64         `json:"timeout_ms"`
+}
+
+func newToolCallTimeoutError(serverName, toolName, phase string, timeout time.Duration) *ToolCallTimeoutError {
+	return &ToolCallTimeoutError{
+		ServerName: serverName,
+		ToolName:   toolName,
+		Phase:      phase,
+		Timeout:    timeout,
+		TimeoutMs:  timeout.Milliseconds(),
+	}
+}
+
+func (e *ToolCallTimeoutError) Error() string {
+	if e.Phase == timeoutPhaseConnect {
+		return fmt.Sprintf("connecting to MCP server %s timed out after %s", e.ServerName, e.Timeout)
+	}
+	return fmt.Sprintf("call to tool %s timed out after %s", mergeServerToolNames(e.ServerName, e.ToolName), e.Timeout)
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+func (e *ToolCallTimeoutError) ToolResult() *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Result:  mcp.Result{Meta: map[string]any{ToolCallTimeoutMetaKey: e}},
+		Content: []mcp.Content{mcp.NewTextContent(e.Error())},
+		IsError: true,
+	}
+}
+
+// resolvedCallPolicy is the policy that applies to a call after merging the tool's and server's policies with the defaults.
+type resolvedCallPolicy struct {
+	connectTimeout time.Duration
+	callTimeout    time.Duration
+	breaker        breakerConfig
+	retry          retryConfig
+	// validateArguments is whether the arguments of calls are validated against the tool's input schema
+	validateArguments bool
+}
+
+func (r *resolvedCallPolicy) apply(p model.CallPolicy) {
+	if p.ConnectTimeoutMs != nil {
+		r.connectTimeout = time.Duration(*p.ConnectTimeoutMs) * time.Millisecond
+	}
+	if p.CallTimeoutMs != nil {
+		r.callTimeout = time.Duration(*p.CallTimeoutMs) * time.Millisecond
+	}
+	if p.BreakerFailureThreshold != nil {
+		r.breaker.failureThreshold = *p.BreakerFailureThreshold
+	}
+	if p.BreakerCooldownMs != nil {
+		r.breaker.cooldown = time.Duration(*p.BreakerCooldownMs) * time.Millisecond
+	}
+	if p.RetryMaxAttempts != nil {
+		r.retry.maxAttempts = *p.RetryMaxAttempts
+	}
+	if p.RetryBackoffMs != nil {
+		r.retry.backoff = time.Duration(*p.RetryBackoffMs) * time.Millisecond
+	}
+	if p.RetryMaxBackoffMs != nil {
+		r.retry.maxBackoff = time.Duration(*p.RetryMaxBackoffMs) * time.Millisecond
+	}
+	if p.RetryOn != nil {
+		r.retry.retryOn = parseRetryOn(*p.RetryOn)
+	}
+	if p.RetryNonIdempotent != nil {
+		r.retry.nonIdempotent = *p.RetryNonIdempotent
+	}
+	if p.ValidateArguments != nil {
+		r.validateArguments = *p.ValidateArguments
+	}
+}
+
+// validateCallScope checks that a limit or policy applies to a server or a canonical tool name.
+func validateCallScope(scopeType model.CallScope, scopeValue string) error {
+	switch scopeType {
+	case model.CallScopeServer:
+		if scopeValue == "" {
+			return errors.New("server scope requires a server name")
+		}
+	case model.CallScopeTool:
+		if _, _, ok := splitServerToolName(scopeValue); !ok {
+			return fmt.Errorf("tool scope requires a canonical tool name (<server>%s<tool>)", serverToolNameSep)
+		}
+	default:
+		return fmt.Errorf("invalid scope '%s', must be one of server, tool", scopeType)
+	}
+	return nil
+}
+
+func validateCallPolicy(p *model.CallPolicy) error {
+	if err := validateCallScope(p.ScopeType, p.ScopeValue); err != nil {
+		return err
+	}
+	if (p.ConnectTimeoutMs != nil && *p.ConnectTimeoutMs < 0) || (p.CallTimeoutMs != nil && *p.CallTimeoutMs < 0) {
+		return errors.New("timeouts cannot be negative")
+	}
+	if p.BreakerFailureThreshold != nil || p.BreakerCooldownMs != nil {
+		if p.ScopeType != model.CallScopeServer {
+			return errors.New("the circuit breaker can only be configured by server policies")
+		}
+		if (p.BreakerFailureThreshold != nil && *p.BreakerFailureThreshold < 0) ||
+			(p.BreakerCooldownMs != nil && *p.BreakerCooldownMs <= 0) {
+			return errors.New("breaker failure threshold cannot be negative and breaker cooldown must be positive")
+		}
+	}
+	if p.RetryMaxAttempts != nil && *p.RetryMaxAttempts < 1 {
+		return errors.New("retry max attempts must be at least 1")
+	}
+	if (p.RetryBackoffMs != nil && *p.RetryBackoffMs < 0) || (p.RetryMaxBackoffMs != nil && *p.RetryMaxBackoffMs < 0) {
+		return errors.New("retry backoffs cannot be negative")
+	}
+	if p.RetryOn != nil {
+		for _, class := range strings.Split(*p.RetryOn, ",") {
+			if class = strings.TrimSpace(class); class != "" && !retryableErrorTypes[class] {
+				return fmt.Errorf(
+					"invalid retry_on error class '%s', must be one of %s, %s, %s",
+					class, model.ToolCallErrorConnection, model.ToolCallErrorUpstream, model.ToolCallErrorTimeout,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeCallPolicy copies the settings of src that are set into dst.
+func mergeCallPolicy(dst, src *model.CallPolicy) {
+	if src.ConnectTimeoutMs != nil {
+		dst.ConnectTimeoutMs = src.ConnectTimeoutMs
+	}
+	if src.CallTimeoutMs != nil {
+		dst.CallTimeoutMs = src.CallTimeoutMs
+	}
+	if src.BreakerFailureThreshold != nil {
+		dst.BreakerFailureThreshold = src.BreakerFailureThreshold
+	}
+	if src.BreakerCooldownMs != nil {
+		dst.BreakerCooldownMs = src.BreakerCooldownMs
+	}
+	if src.RetryMaxAttempts != nil {
+		dst.RetryMaxAttempts = src.RetryMaxAttempts
+	}
+	if src.RetryBackoffMs != nil {
+		dst.RetryBackoffMs = src.RetryBackoffMs
+	}
+	if src.RetryMaxBackoffMs != nil {
+		dst.RetryMaxBackoffMs = src.RetryMaxBackoffMs
+	}
+	if src.RetryOn != nil {
+		dst.RetryOn = src.RetryOn
+	}
+	if src.RetryNonIdempotent != nil {
+		dst.RetryNonIdempotent = src.RetryNonIdempotent
+	}
+	if src.ValidateArguments != nil {
+		dst.ValidateArguments = src.ValidateArguments
+	}
+}
+
+// SetCallPolicy creates the call policy of a server or tool, or updates the settings
+// of its existing policy that are set in p. Settings that are not set in p are left untouched.
+// On success, p holds the resulting policy.
+func (m *MCPService) SetCallPolicy(p *model.CallPolicy) error {
+	if err := validateCallPolicy(p); err != nil {
+		return err
+	}
+
+	var existing model.CallPolicy
+	err := m.db.Where("scope_type = ? AND scope_value = ?", p.ScopeType, p.ScopeValue).First(&existing).Error
+	switch {
+	case err == nil:
+		mergeCallPolicy(&existing, p)
+		err = m.db.Save(&existing).Error
+		*p = existing
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = m.db.Create(p).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the policy of %s %s: %w", p.ScopeType, p.ScopeValue, err)
+	}
+	return nil
+}
+
+// ListCallPolicies returns all call policies.
+func (m *MCPService) ListCallPolicies() ([]model.CallPolicy, error) {
+	var policies []model.CallPolicy
+	if err := m.db.Order("scope_type").Order("scope_value").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteCallPolicy removes the call policy of a server or tool, so that the defaults apply again.
+func (m *MCPService) DeleteCallPolicy(scopeType model.CallScope, scopeValue string) error {
+	res := m.db.Where("scope_type = ? AND scope_value = ?", scopeType, scopeValue).Delete(&model.CallPolicy{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete the policy of %s %s: %w", scopeType, scopeValue, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s %s has no policy: %w", scopeType, scopeValue, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// resolveCallPolicy returns the policy that applies to calls to the given tool.
+func (m *MCPService) resolveCallPolicy(serverName, toolName string) (*resolvedCallPolicy, error) {
+	var policies []model.CallPolicy
+	err := m.db.Where(
+		"(scope_type = ? AND scope_value = ?) OR (scope_type = ? AND scope_value = ?)",
+		model.CallScopeServer, serverName,
+		model.CallScopeTool, mergeServerToolNames(serverName, toolName),
+	).Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the call policies of tool %s: %w", mergeServerToolNames(serverName, toolName), err)
+	}
+
+	r := &resolvedCallPolicy{
+		connectTimeout: defaultConnectTimeout,
+		callTimeout:    defaultCallTimeout,
+		breaker:        breakerConfig{failureThreshold: defaultBreakerFailureThreshold, cooldown: defaultBreakerCooldown},
+		retry: retryConfig{
+			maxAttempts: defaultRetryMaxAttempts,
+			backoff:     defaultRetryBackoff,
+			maxBackoff:  defaultRetryMaxBackoff,
+			retryOn:     parseRetryOn(defaultRetryOn),
+		},
+		validateArguments: true,
+	}
+	// the tool's policy is applied last so that it overrides the server's policy
+	for _, scope := range []model.CallScope{model.CallScopeServer, model.CallScopeTool} {
+		for _, p := range policies {
+			if p.ScopeType == scope {
+				r.apply(p)
+			}
+		}
+	}
+	return r, nil
+}
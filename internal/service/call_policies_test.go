@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestResolveCallPolicy(t *testing.T) {
+	m := newTestMCPService(t)
+
+	r, err := m.resolveCallPolicy("github", "create_issue")
+	if err != nil {
+		t.Fatalf("failed to resolve policy: %v", err)
+	}
+	if r.connectTimeout != defaultConnectTimeout || r.callTimeout != defaultCallTimeout {
+		t.Fatalf("expected the defaults without policies, got %+v", r)
+	}
+
+	policies := []*model.CallPolicy{
+		{ScopeType: model.CallScopeServer, ScopeValue: "github", ConnectTimeoutMs: intPtr(2000), CallTimeoutMs: intPtr(5000)},
+		{ScopeType: model.CallScopeTool, ScopeValue: "github/create_issue", CallTimeoutMs: intPtr(0)},
+	}
+	for _, p := range policies {
+		if err := m.SetCallPolicy(p); err != nil {
+			t.Fatalf("failed to set policy: %v", err)
+		}
+	}
+
+	r, _ = m.resolveCallPolicy("github", "create_issue")
+	if r.connectTimeout != 2*time.Second || r.callTimeout != 0 {
+		t.Errorf("expected the tool policy to override the server's call timeout only, got %+v", r)
+	}
+	r, _ = m.resolveCallPolicy("github", "list_issues")
+	if r.connectTimeout != 2*time.Second || r.callTimeout != 5*time.Second {
+		t.Errorf("expected the server policy to apply to other tools, got %+v", r)
+	}
+
+	// setting a policy again only changes the settings that are set
+	update := &model.CallPolicy{ScopeType: model.CallScopeServer, ScopeValue: "github", CallTimeoutMs: intPtr(9000)}
+	if err := m.SetCallPolicy(update); err != nil {
+		t.Fatalf("failed to update policy: %v", err)
+	}
+	if update.ConnectTimeoutMs == nil || *update.ConnectTimeoutMs != 2000 || *update.CallTimeoutMs != 9000 {
+		t.Errorf("expected the connect timeout to be kept, got %+v", update)
+	}
+
+	invalid := &model.CallPolicy{ScopeType: model.CallScopeTool, ScopeValue: "github", CallTimeoutMs: intPtr(1)}
+	if err := m.SetCallPolicy(invalid); err == nil {
+		t.Error("expected a tool policy without a canonical tool name to be rejected")
+	}
+}
+
+func TestForwardToolCallTimeout(t *testing.T) {
+	cancelled := make(chan mcp.JSONRPCNotification, 1)
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	upstream.AddTool(mcp.NewTool("slow"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	upstream.AddNotificationHandler(methodNotificationCancelled, func(_ context.Context, n mcp.JSONRPCNotification) {
+		cancelled <- n
+	})
+	ts := server.NewTestStreamableHTTPServer(upstream)
+	defer ts.Close()
+
+	m := newTestMCPService(t)
+	if err := m.db.Create(&model.McpServer{Name: "upstream", URL: ts.URL + "/mcp"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	policy := &model.CallPolicy{ScopeType: model.CallScopeServer, ScopeValue: "upstream", CallTimeoutMs: intPtr(100)}
+	if err := m.SetCallPolicy(policy); err != nil {
+		t.Fatalf("failed to set policy: %v", err)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "slow"
+	start := time.Now()
+	_, err := m.callUpstreamTool(context.Background(), "upstream", req)
+
+	var timeoutErr *ToolCallTimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Phase != timeoutPhaseCall {
+		t.Fatalf("expected a call timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the call to be abandoned after its timeout, took %s", elapsed)
+	}
+	if class := classifyToolCallError(context.Background(), nil, err); class != model.ToolCallErrorTimeout {
+		t.Errorf("expected the error to be classified as timeout, got %s", class)
+	}
+
+	select {
+	case n := <-cancelled:
+		if _, ok := n.Params.AdditionalFields["requestId"]; !ok {
+			t.Errorf("expected the cancellation to identify the request, got %+v", n.Params.AdditionalFields)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the upstream server to be notified of the cancellation")
+	}
+}
+
+// testSession is a minimal MCP client session.
+type testSession struct {
+	id string
+}
+
+func (s *testSession) Initialize()                                         {}
+func (s *testSession) Initialized() bool                                   { return true }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *testSession) SessionID() string                                   { return s.id }
+
+func TestClientCancellation(t *testing.T) {
+	proxy := server.NewMCPServer("proxy", "0.0.1")
+	hooks := &server.Hooks{}
+	m := &MCPService{}
+	m.RegisterProxyHooks(hooks)
+	ctx := proxy.WithContext(context.Background(), &testSession{id: "session-1"})
+
+	// the hook tags the request with its JSON-RPC ID, which the handler strips before forwarding the request
+	req := &mcp.CallToolRequest{}
+	for _, hook := range hooks.OnBeforeCallTool {
+		hook(ctx, float64(7), req)
+	}
+	callCtx, done := m.trackClientCancellation(ctx, req)
+	defer done()
+	if req.Params.Meta != nil {
+		t.Errorf("expected the request ID to be removed from the request, got %+v", req.Params.Meta)
+	}
+
+	// cancelling another request leaves the call alone
+	n := mcp.JSONRPCNotification{}
+	n.Params.AdditionalFields = map[string]any{"requestId": float64(8)}
+	m.handleClientCancellation(ctx, n)
+	if callCtx.Err() != nil {
+		t.Fatal("expected the call not to be cancelled by the cancellation of another request")
+	}
+
+	n.Params.AdditionalFields = map[string]any{"requestId": float64(7), "reason": "user aborted"}
+	m.handleClientCancellation(ctx, n)
+	if !errors.Is(context.Cause(callCtx), errCancelledByClient) {
+		t.Errorf("expected the call to be cancelled by the client, got %v", context.Cause(callCtx))
+	}
+}
+
+func TestDeleteCallPolicyNotFound(t *testing.T) {
+	m := newTestMCPService(t)
+	if err := m.DeleteCallPolicy(model.CallScopeServer, "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteCallPolicy() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
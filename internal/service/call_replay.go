@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// CallReplay is the outcome of replaying a captured tool call, compared to the outcome that was recorded.
+type CallReplay struct {
+	// CallID is the ID of the captured call that was replayed
+	CallID string `json:"call_id"`
+	// ServerName is the server that the call was replayed against, which may differ from the recorded one
+	ServerName string `json:"server_name"`
+	ToolName   string `json:"tool_name"`
+
+	// Result is the JSON encoding of the new result, redacted and capped like the recorded one
+	Result          string `json:"result,omitempty"`
+	ResultTruncated bool   `json:"result_truncated,omitempty"`
+	IsError         bool   `json:"is_error"`
+	Error           string `json:"error,omitempty"`
+	ErrorType       string `json:"error_type,omitempty"`
+	DurationMs      int    `json:"duration_ms"`
+
+	Recorded *model.CapturedCall `json:"recorded"`
+	// Changed is set if the outcome of the replay differs from the recorded one
+	Changed bool `json:"changed"`
+	// Diff is a line diff from the recorded result to the new one, empty if they are the same
+	Diff string `json:"diff,omitempty"`
+}
+
+// ReplayCapturedCall calls the tool of a captured call again with the same arguments and compares the new result
+// to the recorded one. The call is replayed against targetServer if set, otherwise against the recorded server.
+// Calls whose arguments were truncated or redacted can't be replayed faithfully and are rejected.
+// A replay that fails (eg- the upstream server is unreachable) is not an error, its failure is part of the outcome.
+func (m *MCPService) ReplayCapturedCall(ctx context.Context, id, targetServer string) (*CallReplay, error) {
+	recorded, err := m.GetCapturedCall(id)
+	if err != nil {
+		return nil, err
+	}
+	if recorded.ArgumentsTruncated {
+		return nil, fmt.Errorf("cannot replay call %s: its arguments were truncated when captured", id)
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(recorded.Arguments), &args); err != nil {
+		return nil, fmt.Errorf("cannot replay call %s: failed to decode its arguments: %w", id, err)
+	}
+	if redacted := redactedFields(args, ""); len(redacted) > 0 {
+		return nil, fmt.Errorf(
+			"cannot replay call %s: its arguments were redacted when captured (%s)", id, strings.Join(redacted, ", "),
+		)
+	}
+
+	if targetServer == "" {
+		targetServer = recorded.ServerName
+	}
+	if _, err := m.GetTool(mergeServerToolNames(targetServer, recorded.ToolName)); err != nil {
+		return nil, fmt.Errorf("cannot replay call %s against server %s: %w", id, targetServer, err)
+	}
+
+	// capture the new result the same way as the recorded one, so that they compare
+	redact, maxBytes := redactFieldSet(""), defaultCapturePayloadBytes
+	var cfg model.CallCaptureConfig
+	err = m.db.WithContext(ctx).Where("server_name = ?", recorded.ServerName).First(&cfg).Error
+	switch {
+	case err == nil:
+		redact = redactFieldSet(cfg.RedactFields)
+		if cfg.MaxPayloadBytes > 0 {
+			maxBytes = cfg.MaxPayloadBytes
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to look up capture config of server %s: %w", recorded.ServerName, err)
+	}
+	if recorded.ResultTruncated {
+		// only the beginning of the recorded result is known
+		maxBytes = len(recorded.Result)
+	}
+
+	if caller := CallerFromContext(ctx); caller.ClientType == "" {
+		caller.ClientType = toolCallClientTypeReplay
+		ctx = ContextWithCaller(ctx, caller)
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Name = recorded.ToolName
+	request.Params.Arguments = args
+
+	start := time.Now()
+	result, callErr := m.callUpstreamTool(ctx, targetServer, request)
+	replay := &CallReplay{
+		CallID:     id,
+		ServerName: targetServer,
+		ToolName:   recorded.ToolName,
+		ErrorType:  classifyToolCallError(ctx, result, callErr),
+		DurationMs: int(time.Since(start).Milliseconds()),
+		Recorded:   recorded,
+	}
+	replay.IsError = replay.ErrorType != "" || callErr != nil
+	if callErr != nil {
+		replay.Error = callErr.Error()
+	}
+	if result != nil {
+		replay.Result, replay.ResultTruncated = capturePayload(result, redact, maxBytes)
+	}
+
+	replay.Diff = lineDiff(indentPayload(recorded.Result), indentPayload(replay.Result))
+	replay.Changed = replay.Diff != "" || replay.IsError != recorded.IsError || replay.ErrorType != recorded.ErrorType
+	return replay, nil
+}
+
+// redactedFields returns the paths of the fields whose values were redacted from a captured payload.
+func redactedFields(v any, path string) []string {
+	var fields []string
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if child == redactedValue {
+				fields = append(fields, p)
+			} else {
+				fields = append(fields, redactedFields(child, p)...)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			fields = append(fields, redactedFields(child, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// indentPayload indents a captured JSON payload so that it diffs line by line.
+// Payloads that are not valid JSON (eg- truncated ones) are returned as they are.
+func indentPayload(payload string) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(payload), "", "  "); err != nil {
+		return payload
+	}
+	return out.String()
+}
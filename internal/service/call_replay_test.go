@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+func TestLineDiff(t *testing.T) {
+	if d := lineDiff("a\nb", "a\nb"); d != "" {
+		t.Errorf("expected no diff between equal texts, got %q", d)
+	}
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9"
+	b := "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10"
+	want := "  ...\n  3\n  4\n  5\n- 6\n+ six\n  7\n  8\n  9\n+ 10\n"
+	if d := lineDiff(a, b); d != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", d, want)
+	}
+}
+
+func TestReplayCapturedCall(t *testing.T) {
+	var version atomic.Int32
+	version.Store(1)
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	upstream.AddTool(mcp.NewTool("lookup"), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if version.Load() == 1 {
+			return mcp.NewToolResultError("lookup failed"), nil
+		}
+		return mcp.NewToolResultText("found " + req.GetString("id", "")), nil
+	})
+	ts := server.NewTestStreamableHTTPServer(upstream)
+	defer ts.Close()
+
+	m := newTestMCPService(t)
+	s := &model.McpServer{Name: "upstream", URL: ts.URL + "/mcp"}
+	if err := m.db.Create(s).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := m.db.Create(&model.Tool{Name: "lookup", ServerID: s.ID}).Error; err != nil {
+		t.Fatalf("failed to create tool: %v", err)
+	}
+	if err := m.SetCallCaptureConfig(&model.CallCaptureConfig{ServerName: "upstream"}); err != nil {
+		t.Fatalf("failed to enable capture: %v", err)
+	}
+
+	ctx := context.Background()
+	call := func(args map[string]any) *model.CapturedCall {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "lookup"
+		req.Params.Arguments = args
+		_, _ = m.callUpstreamTool(ctx, "upstream", req)
+		calls, err := m.ListCapturedCalls(CapturedCallFilter{Limit: 1})
+		if err != nil || len(calls) != 1 {
+			t.Fatalf("expected the call to be captured, got %d calls (%v)", len(calls), err)
+		}
+		return &calls[0]
+	}
+
+	failed := call(map[string]any{"id": "42"})
+	if !failed.IsError || failed.ErrorType != model.ToolCallErrorTool {
+		t.Fatalf("expected the recorded call to have failed, got %+v", failed)
+	}
+
+	// the upstream server is fixed
+	version.Store(2)
+	replay, err := m.ReplayCapturedCall(ctx, failed.ID.String(), "")
+	if err != nil {
+		t.Fatalf("failed to replay call: %v", err)
+	}
+	if replay.IsError || !replay.Changed || !strings.Contains(replay.Result, "found 42") {
+		t.Errorf("expected the replay to succeed where the recorded call failed, got %+v", replay)
+	}
+	if !strings.Contains(replay.Diff, "- ") || !strings.Contains(replay.Diff, `+       "text": "found 42"`) {
+		t.Errorf("expected the diff to show the new result, got:\n%s", replay.Diff)
+	}
+
+	// replaying a call whose result is the same reports no change
+	time.Sleep(time.Millisecond)
+	unchanged := call(map[string]any{"id": "7"})
+	again, err := m.ReplayCapturedCall(ctx, unchanged.ID.String(), "")
+	if err != nil || again.Changed || again.Diff != "" {
+		t.Errorf("expected replaying an unchanged call to report no change, got %+v (%v)", again, err)
+	}
+
+	secret := call(map[string]any{"id": "1", "token": "t"})
+	if _, err := m.ReplayCapturedCall(ctx, secret.ID.String(), ""); err == nil || !strings.Contains(err.Error(), "token") {
+		t.Errorf("expected a call with redacted arguments to be rejected, got %v", err)
+	}
+	if _, err := m.ReplayCapturedCall(ctx, failed.ID.String(), "unknown"); err == nil {
+		t.Errorf("expected a replay against an unknown server to be rejected")
+	}
+	if _, err := m.ReplayCapturedCall(ctx, uuid.NewString(), ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected the replay of an unknown call to fail with gorm.ErrRecordNotFound, got %v", err)
+	}
+}
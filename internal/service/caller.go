@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Caller identifies who is making a request to the registry, as far as the registry can tell.
+// MCPJungle doesn't authenticate its callers, so this is only used to attribute usage and enforce quotas.
+type Caller struct {
+	// APIKeyHash is the hash of the API key presented by the caller, if any (see HashAPIKey).
+	APIKeyHash string
+	// ClientType is the type of client declared by the caller, if any (eg- cursor, claude).
+	ClientType string
+}
+
+type callerContextKey struct{}
+
+// ContextWithCaller returns a copy of ctx that carries the caller.
+func ContextWithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, c)
+}
+
+// CallerFromContext returns the caller carried by ctx, or an empty Caller if there is none.
+func CallerFromContext(ctx context.Context) Caller {
+	c, _ := ctx.Value(callerContextKey{}).(Caller)
+	return c
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of an API key.
+// Only hashes of API keys are ever stored by the registry.
+func HashAPIKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// toolCallClientType returns the client type that a tool call made with ctx is attributed to.
+// The type declared by the caller takes precedence, otherwise it is derived from how the call was made.
+func toolCallClientType(ctx context.Context) string {
+	if c := CallerFromContext(ctx); c.ClientType != "" {
+		return c.ClientType
+	}
+	if server.ClientSessionFromContext(ctx) != nil {
+		return toolCallClientTypeMCP
+	}
+	return toolCallClientTypeAPI
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/assertion"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+const (
+	// AlertTypeCanary is the type of the alerts raised by canary checks that fail repeatedly
+	AlertTypeCanary = "canary_failed"
+
+	defaultCanaryIntervalSeconds  = 300
+	minCanaryIntervalSeconds      = 10
+	defaultCanaryFailureThreshold = 3
+
+	// canaryTick is how often the registry looks for canaries that are due
+	canaryTick = 5 * time.Second
+	// canaryTimeout bounds a canary's tool call, a call that takes longer fails the run
+	canaryTimeout = time.Minute
+	// canaryRunRetention is how long the outcome of every canary run is kept
+	canaryRunRetention = 7 * 24 * time.Hour
+	// DefaultCanaryRunsLimit is the number of runs returned by default in a canary's history
+	DefaultCanaryRunsLimit = 50
+)
+
+// ErrCanaryRunning is returned when a canary is run while a run of it is in progress.
+var ErrCanaryRunning = errors.New("canary is already running")
+
+// validateCanaryCheck checks that the canary is well-formed and fills in defaults.
+// It returns the canary's input and assertions, decoded.
+func validateCanaryCheck(c *model.CanaryCheck) (map[string]any, *assertion.Assertions, error) {
+	if c.Name == "" {
+		return nil, nil, errors.New("canary name is required")
+	}
+	serverName, _, ok := splitServerToolName(c.ToolName)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid tool name '%s': must be <server>/<tool>", c.ToolName)
+	}
+	c.ServerName = serverName
+
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = defaultCanaryIntervalSeconds
+	}
+	if c.IntervalSeconds < minCanaryIntervalSeconds {
+		return nil, nil, fmt.Errorf("invalid interval_seconds %d: must be at least %d", c.IntervalSeconds, minCanaryIntervalSeconds)
+	}
+	if c.FailureThreshold == 0 {
+		c.FailureThreshold = defaultCanaryFailureThreshold
+	}
+	if c.FailureThreshold < 0 {
+		return nil, nil, fmt.Errorf("invalid failure_threshold %d: must be positive", c.FailureThreshold)
+	}
+
+	var input map[string]any
+	if len(c.Input) > 0 && string(c.Input) != "null" {
+		if err := json.Unmarshal(c.Input, &input); err != nil {
+			return nil, nil, fmt.Errorf("invalid input: must be a JSON object: %w", err)
+		}
+	}
+	var expect assertion.Assertions
+	if len(c.Expect) > 0 && string(c.Expect) != "null" {
+		if err := json.Unmarshal(c.Expect, &expect); err != nil {
+			return nil, nil, fmt.Errorf("invalid expect: %w", err)
+		}
+	}
+	if err := expect.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid expect: %w", err)
+	}
+	return input, &expect, nil
+}
+
+// SetCanaryCheck creates a canary check, or replaces the definition of the existing canary with the same name.
+// The outcome of the latest run of an existing canary is kept.
+func (m *MCPService) SetCanaryCheck(c *model.CanaryCheck) error {
+	if _, _, err := validateCanaryCheck(c); err != nil {
+		return err
+	}
+	if _, err := m.GetTool(c.ToolName); err != nil {
+		return fmt.Errorf("failed to get tool %s: %w", c.ToolName, err)
+	}
+
+	var existing model.CanaryCheck
+	err := m.db.Where("name = ?", c.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		c.ID = existing.ID
+		c.CreatedAt = existing.CreatedAt
+		c.LastRunAt = existing.LastRunAt
+		c.LastPassed = existing.LastPassed
+		c.LastLatencyMs = existing.LastLatencyMs
+		c.LastError = existing.LastError
+		c.ConsecutiveFailures = existing.ConsecutiveFailures
+		if err := m.db.Save(c).Error; err != nil {
+			return fmt.Errorf("failed to update canary %s: %w", c.Name, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := m.db.Create(c).Error; err != nil {
+			return fmt.Errorf("failed to create canary %s: %w", c.Name, err)
+		}
+	default:
+		return fmt.Errorf("failed to look up canary %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// ListCanaryChecks returns all canary checks along with the outcome of their latest run.
+func (m *MCPService) ListCanaryChecks() ([]model.CanaryCheck, error) {
+	var canaries []model.CanaryCheck
+	if err := m.db.Order("name").Find(&canaries).Error; err != nil {
+		return nil, err
+	}
+	return canaries, nil
+}
+
+// DeleteCanaryCheck deletes a canary check and its runs.
+// Its open alert, if any, is resolved because nothing would resolve it anymore.
+func (m *MCPService) DeleteCanaryCheck(name string) error {
+	var c model.CanaryCheck
+	if err := m.db.Where("name = ?", name).First(&c).Error; err != nil {
+		return fmt.Errorf("failed to get canary %s: %w", name, err)
+	}
+	if err := m.resolveCanaryAlert(name); err != nil {
+		return err
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("canary_name = ?", name).Delete(&model.CanaryRun{}).Error; err != nil {
+			return fmt.Errorf("failed to delete runs of canary %s: %w", name, err)
+		}
+		if err := tx.Delete(&c).Error; err != nil {
+			return fmt.Errorf("failed to delete canary %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// deleteServerCanaries deletes the canary checks of a server and their runs, and resolves their open alerts.
+// It is called when the server is deregistered, since the tools that its canaries call are gone.
+func (m *MCPService) deleteServerCanaries(serverName string) error {
+	var names []string
+	if err := m.db.Model(&model.CanaryCheck{}).Where("server_name = ?", serverName).Pluck("name", &names).Error; err != nil {
+		return fmt.Errorf("failed to list canaries of server %s: %w", serverName, err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if err := m.resolveCanaryAlert(name); err != nil {
+			return err
+		}
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("canary_name IN ?", names).Delete(&model.CanaryRun{}).Error; err != nil {
+			return fmt.Errorf("failed to delete runs of the canaries of server %s: %w", serverName, err)
+		}
+		if err := tx.Where("server_name = ?", serverName).Delete(&model.CanaryCheck{}).Error; err != nil {
+			return fmt.Errorf("failed to delete canaries of server %s: %w", serverName, err)
+		}
+		return nil
+	})
+}
+
+// ListCanaryRuns returns the most recent runs of a canary, newest first.
+func (m *MCPService) ListCanaryRuns(name string, limit int) ([]model.CanaryRun, error) {
+	if err := m.db.Where("name = ?", name).First(&model.CanaryCheck{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to get canary %s: %w", name, err)
+	}
+	var runs []model.CanaryRun
+	err := m.db.Where("canary_name = ?", name).Order("ran_at DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the runs of canary %s: %w", name, err)
+	}
+	return runs, nil
+}
+
+// RunCanaryCheck runs a canary right away, regardless of its schedule, and returns the outcome.
+func (m *MCPService) RunCanaryCheck(ctx context.Context, name string) (*model.CanaryRun, error) {
+	var c model.CanaryCheck
+	if err := m.db.Where("name = ?", name).First(&c).Error; err != nil {
+		return nil, fmt.Errorf("failed to get canary %s: %w", name, err)
+	}
+	if _, running := m.runningCanaries.LoadOrStore(name, true); running {
+		return nil, fmt.Errorf("canary %s: %w", name, ErrCanaryRunning)
+	}
+	defer m.runningCanaries.Delete(name)
+	return m.runCanary(ctx, &c)
+}
+
+// StartCanaries runs the enabled canaries on their schedule in the background until ctx is cancelled.
+// Runs that are older than the retention period are deleted.
+func (m *MCPService) StartCanaries(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(canaryTick)
+		defer ticker.Stop()
+		for {
+			if err := m.runDueCanaries(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "failed to run canaries", logging.Err(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runDueCanaries starts the enabled canaries whose interval has elapsed since their latest run.
+// A canary that is still running is not started again.
+func (m *MCPService) runDueCanaries(ctx context.Context, now time.Time) error {
+	var canaries []model.CanaryCheck
+	if err := m.db.Where("enabled = ?", true).Find(&canaries).Error; err != nil {
+		return fmt.Errorf("failed to list canaries: %w", err)
+	}
+	for i := range canaries {
+		c := &canaries[i]
+		if c.LastRunAt != nil && now.Sub(*c.LastRunAt) < time.Duration(c.IntervalSeconds)*time.Second {
+			continue
+		}
+		if _, running := m.runningCanaries.LoadOrStore(c.Name, true); running {
+			continue
+		}
+		go func() {
+			defer m.runningCanaries.Delete(c.Name)
+			if _, err := m.runCanary(ctx, c); err != nil {
+				slog.ErrorContext(ctx, "failed to run canary", slog.String("canary", c.Name), logging.Err(err))
+			}
+		}()
+	}
+
+	cutoff := now.Add(-canaryRunRetention)
+	if err := m.db.Where("ran_at < ?", cutoff).Delete(&model.CanaryRun{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old canary runs: %w", err)
+	}
+	return nil
+}
+
+// runCanary calls the canary's tool through the registry, like a client would,
+// checks the result against the canary's assertions and records the outcome.
+// A run interrupted by the cancellation of ctx is not recorded, since it says nothing about the tool.
+func (m *MCPService) runCanary(ctx context.Context, c *model.CanaryCheck) (*model.CanaryRun, error) {
+	input, expect, err := validateCanaryCheck(c)
+	if err != nil {
+		return nil, err
+	}
+	serverName, toolName, _ := splitServerToolName(c.ToolName)
+
+	callCtx, cancel := context.WithTimeout(ContextWithCaller(ctx, Caller{ClientType: toolCallClientTypeCanary}), canaryTimeout)
+	defer cancel()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = toolName
+	request.Params.Arguments = input
+
+	start := time.Now()
+	result, callErr := m.callUpstreamTool(callCtx, serverName, request)
+	latency := time.Since(start)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	run := &model.CanaryRun{
+		CanaryName: c.Name,
+		ServerName: serverName,
+		RanAt:      start.UTC(),
+		LatencyMs:  latency.Milliseconds(),
+	}
+	if callErr != nil {
+		run.Error = callErr.Error()
+	} else {
+		outcome := assertion.Outcome{IsError: result.IsError, Latency: latency}
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				outcome.Texts = append(outcome.Texts, text.Text)
+			}
+		}
+		failures := expect.Check(outcome)
+		run.Passed = len(failures) == 0
+		run.Error = strings.Join(failures, "; ")
+	}
+	if err := m.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record run of canary %s: %w", c.Name, err)
+	}
+
+	failures := 0
+	if !run.Passed {
+		failures = c.ConsecutiveFailures + 1
+	}
+	err = m.db.Model(c).Updates(map[string]any{
+		"last_run_at":          run.RanAt,
+		"last_passed":          run.Passed,
+		"last_latency_ms":      run.LatencyMs,
+		"last_error":           run.Error,
+		"consecutive_failures": failures,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update canary %s: %w", c.Name, err)
+	}
+
+	if run.Passed {
+		err = m.resolveCanaryAlert(c.Name)
+	} else if failures >= c.FailureThreshold {
+		err = m.raiseCanaryAlert(c, failures, run.Error)
+	}
+	if err != nil {
+		slog.Error("failed to update the canary alert", slog.String("canary", c.Name), logging.Err(err))
+	}
+	return run, nil
+}
+
+// raiseCanaryAlert raises an alert for a canary that failed repeatedly, unless it already has an open one.
+func (m *MCPService) raiseCanaryAlert(c *model.CanaryCheck, failures int, lastError string) error {
+	if m.analyticsService == nil {
+		return nil
+	}
+	open, err := m.openCanaryAlert(c.Name)
+	if err != nil || open != nil {
+		return err
+	}
+	value := float64(failures)
+	threshold := float64(c.FailureThreshold)
+	name := c.Name
+	return m.analyticsService.CreateAlert(&model.Alert{
+		Type:         AlertTypeCanary,
+		Title:        fmt.Sprintf("Canary %s of tool %s is failing", c.Name, c.ToolName),
+		Message:      fmt.Sprintf("Canary %s of tool %s failed %d times in a row: %s", c.Name, c.ToolName, failures, lastError),
+		Severity:     "high",
+		Threshold:    &threshold,
+		CurrentValue: &value,
+		ResourceType: "canary",
+		ResourceID:   &name,
+	})
+}
+
+// resolveCanaryAlert resolves the open alert of a canary, if it has one.
+func (m *MCPService) resolveCanaryAlert(name string) error {
+	if m.analyticsService == nil {
+		return nil
+	}
+	open, err := m.openCanaryAlert(name)
+	if err != nil || open == nil {
+		return err
+	}
+	return m.analyticsService.ResolveAlert(open.ID.String())
+}
+
+// openCanaryAlert returns the open alert of a canary, or nil if it has none.
+func (m *MCPService) openCanaryAlert(name string) (*model.Alert, error) {
+	var open model.Alert
+	err := m.db.Where(
+		"type = ? AND resource_type = ? AND resource_id = ? AND resolved = ?",
+		AlertTypeCanary, "canary", name, false,
+	).First(&open).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the alert of canary %s: %w", name, err)
+	}
+	return &open, nil
+}
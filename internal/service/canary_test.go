@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestCanaryChecks(t *testing.T) {
+	m := newTestMCPService(t)
+	db := m.db
+	ctx := context.Background()
+	config := `
+tools:
+  - name: status
+    responses:
+      - when: {ok: true}
+        text: all good
+      - error: broken
+`
+	if err := m.RegisterMcpServer(ctx, &model.McpServer{Name: "canaried", MockConfig: config}); err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+	t.Cleanup(func() { mockServers.remove(mockServerURL("canaried")) })
+	if err := db.Create(&model.ServerHealthCheck{ServerName: "canaried", CheckedAt: time.Now(), Healthy: true}).Error; err != nil {
+		t.Fatalf("failed to record health check: %v", err)
+	}
+
+	invalid := []*model.CanaryCheck{
+		{Name: "c", ToolName: "status"},
+		{Name: "c", ToolName: "canaried/unknown"},
+		{Name: "c", ToolName: "canaried/status", IntervalSeconds: 1},
+		{Name: "c", ToolName: "canaried/status", Input: []byte(`[1]`)},
+		{Name: "c", ToolName: "canaried/status", Expect: []byte(`{"text_matches": ["("]}`)},
+	}
+	for _, c := range invalid {
+		if err := m.SetCanaryCheck(c); err == nil {
+			t.Errorf("expected canary %+v to be rejected", c)
+		}
+	}
+
+	canary := &model.CanaryCheck{
+		Name:             "status-ok",
+		ToolName:         "canaried/status",
+		Input:            []byte(`{"ok": false}`),
+		Expect:           []byte(`{"is_error": false, "text_contains": ["good"]}`),
+		FailureThreshold: 2,
+		Enabled:          true,
+	}
+	if err := m.SetCanaryCheck(canary); err != nil {
+		t.Fatalf("failed to set canary: %v", err)
+	}
+	if canary.IntervalSeconds != defaultCanaryIntervalSeconds || canary.ServerName != "canaried" {
+		t.Errorf("expected defaults to be set, got %+v", canary)
+	}
+
+	health := NewHealthService(db)
+	status := func() *ServerHealth {
+		h, err := health.serverHealth("canaried", time.Now())
+		if err != nil {
+			t.Fatalf("failed to get server health: %v", err)
+		}
+		return h
+	}
+	openAlerts := func() int {
+		alerts, err := m.analyticsService.GetActiveAlerts()
+		if err != nil {
+			t.Fatalf("failed to list alerts: %v", err)
+		}
+		return len(alerts)
+	}
+
+	// the tool is broken, the canary fails
+	run, err := m.RunCanaryCheck(ctx, "status-ok")
+	if err != nil || run.Passed || !strings.Contains(run.Error, "tool error") {
+		t.Fatalf("expected the run to fail, got %+v (%v)", run, err)
+	}
+	if openAlerts() != 0 || status().Status != HealthStatusHealthy {
+		t.Errorf("expected a single failure to stay below the threshold")
+	}
+
+	// a due canary is run by the scheduler
+	if err := m.runDueCanaries(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to run due canaries: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, running := m.runningCanaries.Load("status-ok"); !running {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if openAlerts() != 1 {
+		t.Fatalf("expected repeated failures to raise an alert")
+	}
+	if h := status(); h.Status != HealthStatusDegraded || len(h.FailingCanaries) != 1 {
+		t.Errorf("expected the server to be degraded by the failing canary, got %+v", h)
+	}
+
+	// the canary isn't due again until its interval elapses
+	canaries, _ := m.ListCanaryChecks()
+	if err := m.runDueCanaries(ctx, *canaries[0].LastRunAt); err != nil {
+		t.Fatalf("failed to run due canaries: %v", err)
+	}
+	runs, err := m.ListCanaryRuns("status-ok", 10)
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d (%v)", len(runs), err)
+	}
+
+	// the tool is fixed
+	canary.Input = []byte(`{"ok": true}`)
+	if err := m.SetCanaryCheck(canary); err != nil {
+		t.Fatalf("failed to update canary: %v", err)
+	}
+	if canary.ConsecutiveFailures != 2 {
+		t.Errorf("expected the outcome of the latest run to be kept, got %d consecutive failures", canary.ConsecutiveFailures)
+	}
+	if run, err := m.RunCanaryCheck(ctx, "status-ok"); err != nil || !run.Passed {
+		t.Fatalf("expected the run to pass, got %+v (%v)", run, err)
+	}
+	if openAlerts() != 0 || status().Status != HealthStatusHealthy {
+		t.Errorf("expected a passing run to resolve the alert and the server's health")
+	}
+
+	if err := m.DeleteCanaryCheck("status-ok"); err != nil {
+		t.Fatalf("failed to delete canary: %v", err)
+	}
+	if _, err := m.ListCanaryRuns("status-ok", 10); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected the runs of a deleted canary to be gone, got %v", err)
+	}
+	if _, err := m.RunCanaryCheck(ctx, "status-ok"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected running a deleted canary to fail with gorm.ErrRecordNotFound, got %v", err)
+	}
+	if err := m.DeleteCanaryCheck("status-ok"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected deleting a deleted canary to fail with gorm.ErrRecordNotFound, got %v", err)
+	}
+
+	// a canary can't be run twice at once
+	canary = &model.CanaryCheck{
+		Name: "status-broken", ToolName: "canaried/status", Expect: []byte(`{"is_error": false}`), FailureThreshold: 1, Enabled: true,
+	}
+	if err := m.SetCanaryCheck(canary); err != nil {
+		t.Fatalf("failed to set canary: %v", err)
+	}
+	m.runningCanaries.Store("status-broken", true)
+	if _, err := m.RunCanaryCheck(ctx, "status-broken"); !errors.Is(err, ErrCanaryRunning) {
+		t.Errorf("expected running a canary that is running to fail with ErrCanaryRunning, got %v", err)
+	}
+	m.runningCanaries.Delete("status-broken")
+
+	// the canaries of a deregistered server go away with it, along with their alerts
+	if _, err := m.RunCanaryCheck(ctx, "status-broken"); err != nil || openAlerts() != 1 {
+		t.Fatalf("expected the failing canary to raise an alert (%v)", err)
+	}
+	if err := m.DeregisterMcpServer("canaried"); err != nil {
+		t.Fatalf("failed to deregister server: %v", err)
+	}
+	if canaries, _ := m.ListCanaryChecks(); len(canaries) != 0 {
+		t.Errorf("expected the canaries of the deregistered server to be deleted, got %+v", canaries)
+	}
+	var runCount int64
+	db.Model(&model.CanaryRun{}).Count(&runCount)
+	if runCount != 0 || openAlerts() != 0 {
+		t.Errorf("expected the runs and alerts of the deleted canaries to be gone, got %d runs and %d open alerts", runCount, openAlerts())
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	methodNotificationCancelled = "notifications/cancelled"
+
+	// requestIDMetaKey is the _meta entry in which the proxy hands the JSON-RPC ID of a tool call
+	// from the BeforeCallTool hook over to the tool handler, which needs it to make the call cancellable.
+	// It is removed before the call is forwarded upstream.
+	requestIDMetaKey = "mcpjungle/request_id"
+
+	// cancelNotificationTimeout bounds sending notifications/cancelled to an upstream server
+	cancelNotificationTimeout = 5 * time.Second
+)
+
+// errCancelledByClient is the cause of the cancellation of a tool call that the MCP client cancelled.
+var errCancelledByClient = errors.New("cancelled by the client")
+
+// inflightCalls holds the cancel functions of the tool calls made by MCP clients that are in progress,
+// keyed by the client's session ID and the JSON-RPC ID of the call.
+type inflightCalls struct {
+	calls sync.Map
+}
+
+func inflightKey(sessionID string, requestID any) string {
+	return sessionID + "/" + mcp.NewRequestId(requestID).String()
+}
+
+// RegisterProxyHooks installs the hooks that the MCP service needs on the MCP proxy server.
+// The hooks must be passed to the proxy server when it is created (server.WithHooks).
+func (m *MCPService) RegisterProxyHooks(hooks *server.Hooks) {
+	registerSessionMetricsHooks(hooks)
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, request *mcp.CallToolRequest) {
+		if request.Params.Meta == nil {
+			request.Params.Meta = &mcp.Meta{}
+		}
+		if request.Params.Meta.AdditionalFields == nil {
+			request.Params.Meta.AdditionalFields = make(map[string]any)
+		}
+		request.Params.Meta.AdditionalFields[requestIDMetaKey] = id
+	})
+}
+
+// trackClientCancellation makes a tool call made by an MCP client cancellable with notifications/cancelled.
+// It returns the context to make the call with and a function that must be called once the call completes.
+func (m *MCPService) trackClientCancellation(ctx context.Context, request *mcp.CallToolRequest) (context.Context, func()) {
+	var (
+		requestID any
+		ok        bool
+	)
+	if meta := request.Params.Meta; meta != nil {
+		requestID, ok = meta.AdditionalFields[requestIDMetaKey]
+		delete(meta.AdditionalFields, requestIDMetaKey)
+		if meta.ProgressToken == nil && len(meta.AdditionalFields) == 0 {
+			request.Params.Meta = nil
+		}
+	}
+	session := server.ClientSessionFromContext(ctx)
+	if !ok || session == nil {
+		return ctx, func() {}
+	}
+
+	key := inflightKey(session.SessionID(), requestID)
+	ctx, cancel := context.WithCancelCause(ctx)
+	m.inflight.calls.Store(key, cancel)
+	return ctx, func() {
+		m.inflight.calls.Delete(key)
+		cancel(nil)
+	}
+}
+
+// handleClientCancellation cancels the in-flight tool call that an MCP client cancelled.
+// Cancelling the call in turn notifies the upstream server (see cancellableTransport).
+func (m *MCPService) handleClientCancellation(ctx context.Context, notification mcp.JSONRPCNotification) {
+	session := server.ClientSessionFromContext(ctx)
+	requestID, ok := notification.Params.AdditionalFields["requestId"]
+	if session == nil || !ok {
+		return
+	}
+	cancel, ok := m.inflight.calls.Load(inflightKey(session.SessionID(), requestID))
+	if !ok {
+		// the call already completed
+		return
+	}
+	cause := errCancelledByClient
+	if reason, _ := notification.Params.AdditionalFields["reason"].(string); reason != "" {
+		cause = fmt.Errorf("%w: %s", errCancelledByClient, reason)
+	}
+	cancel.(context.CancelCauseFunc)(cause)
+}
+
+// cancellableTransport sends notifications/cancelled to the upstream server when a request is abandoned
+// because its context ended (the caller cancelled the call or a timeout fired), so that the server can stop working on it.
+type cancellableTransport struct {
+	transport.Interface
+}
+
+func (t *cancellableTransport) SendRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*transport.JSONRPCResponse, error) {
+	resp, err := t.Interface.SendRequest(ctx, request)
+	// clients must never cancel their initialize request
+	if err != nil && ctx.Err() != nil && request.Method != string(mcp.MethodInitialize) {
+		t.notifyCancelled(request.ID, context.Cause(ctx))
+	}
+	return resp, err
+}
+
+func (t *cancellableTransport) notifyCancelled(id mcp.RequestId, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotificationTimeout)
+	defer cancel()
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: methodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{"requestId": id.Value(), "reason": cause.Error()},
+			},
+		},
+	}
+	if err := t.SendNotification(ctx, notification); err != nil {
+		slog.Error("failed to notify upstream MCP server of cancelled request",
+			slog.String("upstream_request_id", id.String()), logging.Err(err))
+	}
+}
@@ -0,0 +1,648 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+const (
+	// cassetteVersion is the version of the format of cassette files.
+	// A cassette file is made of JSON lines: the header with the server and its tools first, then a line per recorded call,
+	// so that calls are appended to it as they are recorded.
+	cassetteVersion = 2
+
+	// maxUnmatchedCallsReported bounds the unmatched calls kept for the report of a cassette, the rest are only counted
+	maxUnmatchedCallsReported = 100
+)
+
+// cassette is the content of a cassette file: the tools of an MCP server and the tool calls recorded from it.
+type cassette struct {
+	cassetteHeader
+	Calls []cassetteCall
+}
+
+// cassetteHeader is the first line of a cassette file.
+type cassetteHeader struct {
+	Version int            `json:"version"`
+	Server  cassetteServer `json:"server"`
+	Tools   []mcp.Tool     `json:"tools"`
+}
+
+type cassetteServer struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// cassetteCall is a recorded tool call, with either the result or the error of the upstream server.
+type cassetteCall struct {
+	Tool       string          `json:"tool"`
+	Arguments  any             `json:"arguments,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// UnmatchedCall is a tool call made to a server in replay mode that its cassette has no recording of.
+type UnmatchedCall struct {
+	Tool string `json:"tool"`
+	// Arguments is the normalized JSON encoding of the call's arguments
+	Arguments string    `json:"arguments"`
+	Time      time.Time `json:"time"`
+	// Fallback is set if lenient matching served the call with a call recorded with other arguments
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// CassetteStatus is the cassette of a server, along with how it was used since it was loaded.
+type CassetteStatus struct {
+	model.CassetteConfig
+	// RecordedCalls is the number of tool calls in the cassette
+	RecordedCalls int `json:"recorded_calls"`
+	// ReplayedCalls is the number of tool calls served from the cassette
+	ReplayedCalls int `json:"replayed_calls"`
+	// UnusedCalls is the number of recorded calls that haven't been replayed yet
+	UnusedCalls int `json:"unused_calls"`
+	// UnmatchedCount is the number of calls that the cassette had no recording of,
+	// Unmatched lists the first of them.
+	UnmatchedCount int             `json:"unmatched_count"`
+	Unmatched      []UnmatchedCall `json:"unmatched,omitempty"`
+	// Error is the reason why the cassette file couldn't be loaded, if it couldn't
+	Error string `json:"error,omitempty"`
+}
+
+func validateCassetteConfig(cfg *model.CassetteConfig) error {
+	if cfg.ServerName == "" {
+		return errors.New("server name is required")
+	}
+	path, err := cleanCassettePath(cfg.Path)
+	if err != nil {
+		return err
+	}
+	cfg.Path = path
+	switch cfg.Mode {
+	case model.CassetteModeRecord, model.CassetteModeReplay:
+	default:
+		return fmt.Errorf("invalid cassette mode '%s', must be %s or %s", cfg.Mode, model.CassetteModeRecord, model.CassetteModeReplay)
+	}
+	switch cfg.Match {
+	case "":
+		cfg.Match = model.CassetteMatchStrict
+	case model.CassetteMatchStrict, model.CassetteMatchLenient:
+	default:
+		return fmt.Errorf(
+			"invalid cassette matching '%s', must be %s or %s", cfg.Match, model.CassetteMatchStrict, model.CassetteMatchLenient,
+		)
+	}
+	return nil
+}
+
+// cleanCassettePath checks that the path of a cassette file is within the cassette directory of the registry
+// and returns it cleaned. Paths are relative to the cassette directory, so that API callers can't reach
+// other files on the registry's host.
+func cleanCassettePath(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("cassette path is required")
+	}
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("invalid cassette path %s: it must be relative to the cassette directory", path)
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("invalid cassette path %s: it must not contain '..'", path)
+		}
+	}
+	cleaned := filepath.Clean(path)
+	if cleaned == "." || !filepath.IsLocal(cleaned) {
+		return "", fmt.Errorf("invalid cassette path %s: it must name a file within the cassette directory", path)
+	}
+	return cleaned, nil
+}
+
+// SetCassetteConfig puts a server in record or replay mode, replacing its existing cassette config if any.
+// The cassette path is relative to the registry's cassette directory.
+//
+// Recording starts a new cassette with the tools that the server currently provides, overwriting the cassette file.
+// The tool calls made to the server are then added to the cassette as they complete.
+//
+// Replaying loads the cassette and replaces the server's tools with the recorded ones, registering the server
+// if it isn't registered yet, so that the registry never needs to reach the server (eg- in CI).
+// Tool calls are then served from the cassette.
+func (m *MCPService) SetCassetteConfig(ctx context.Context, cfg *model.CassetteConfig) error {
+	if err := validateCassetteConfig(cfg); err != nil {
+		return err
+	}
+
+	var c *cassette
+	switch cfg.Mode {
+	case model.CassetteModeRecord:
+		s, err := m.getMcpServer(ctx, cfg.ServerName)
+		if err != nil {
+			return fmt.Errorf("failed to get MCP server %s: %w", cfg.ServerName, err)
+		}
+		conn, err := createMcpServerConn(ctx, s)
+		if err != nil {
+			return fmt.Errorf("failed to connect to MCP server %s: %w", s.Name, err)
+		}
+		defer conn.Close()
+		resp, err := conn.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to fetch tools from MCP server %s: %w", s.Name, err)
+		}
+		c = &cassette{cassetteHeader: cassetteHeader{
+			Version: cassetteVersion,
+			Server:  cassetteServer{Name: s.Name, Description: s.Description, URL: s.URL},
+			Tools:   resp.Tools,
+		}}
+		if err := m.cassettes.start(cfg.Path, c); err != nil {
+			return err
+		}
+
+	case model.CassetteModeReplay:
+		var err error
+		if c, err = m.cassettes.read(cfg.Path); err != nil {
+			return err
+		}
+		if err := m.installCassetteTools(ctx, cfg.ServerName, c); err != nil {
+			return err
+		}
+	}
+
+	var existing model.CassetteConfig
+	err := m.db.Where("server_name = ?", cfg.ServerName).First(&existing).Error
+	switch {
+	case err == nil:
+		cfg.ID = existing.ID
+		cfg.CreatedAt = existing.CreatedAt
+		if err := m.db.Save(cfg).Error; err != nil {
+			return fmt.Errorf("failed to update cassette config of server %s: %w", cfg.ServerName, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := m.db.Create(cfg).Error; err != nil {
+			return fmt.Errorf("failed to create cassette config of server %s: %w", cfg.ServerName, err)
+		}
+	default:
+		return fmt.Errorf("failed to look up cassette config of server %s: %w", cfg.ServerName, err)
+	}
+
+	m.cassettes.set(newCassettePlayer(*cfg, c, m.cassettes.dir))
+	return nil
+}
+
+// installCassetteTools replaces the tools of a server with the ones recorded in its cassette.
+// A server that isn't registered is registered from the cassette, without connecting to it.
+func (m *MCPService) installCassetteTools(ctx context.Context, serverName string, c *cassette) error {
+	s, err := m.GetMcpServer(serverName)
+	switch {
+	case err == nil:
+		if err := m.deregisterServerTools(s); err != nil {
+			return fmt.Errorf("failed to replace the tools of server %s: %w", serverName, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := validateServerName(serverName); err != nil {
+			return err
+		}
+		s = &model.McpServer{Name: serverName, Description: c.Server.Description, URL: c.Server.URL}
+		if err := m.db.Create(s).Error; err != nil {
+			return fmt.Errorf("failed to register mcp server: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to get MCP server %s: %w", serverName, err)
+	}
+	m.addServerTools(ctx, s, c.Tools)
+	return nil
+}
+
+// DeleteCassetteConfig takes a server out of record or replay mode, its tool calls are forwarded to it again.
+// The cassette file is kept.
+// A server leaving replay mode gets its current tools back in place of the recorded ones.
+// If it can't be reached, the recorded tools are removed anyway, so that the registry doesn't advertise tools
+// that the server may not have.
+func (m *MCPService) DeleteCassetteConfig(ctx context.Context, serverName string) error {
+	res := m.db.Where("server_name = ?", serverName).Delete(&model.CassetteConfig{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete cassette config of server %s: %w", serverName, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("server %s is not in record or replay mode: %w", serverName, gorm.ErrRecordNotFound)
+	}
+	replaying := m.cassettes.get(serverName).replaying()
+	m.cassettes.remove(serverName)
+	if replaying {
+		return m.syncServerTools(ctx, serverName)
+	}
+	return nil
+}
+
+// deleteServerCassette takes a server out of record or replay mode without touching its tools.
+// It is called when the server is deregistered, so that a server registered again under the same name
+// isn't served from the old cassette.
+func (m *MCPService) deleteServerCassette(serverName string) error {
+	if err := m.db.Where("server_name = ?", serverName).Delete(&model.CassetteConfig{}).Error; err != nil {
+		return fmt.Errorf("failed to delete cassette config of server %s: %w", serverName, err)
+	}
+	m.cassettes.remove(serverName)
+	return nil
+}
+
+// syncServerTools replaces the tools of a server with the ones that it currently provides.
+func (m *MCPService) syncServerTools(ctx context.Context, serverName string) error {
+	s, err := m.getMcpServer(ctx, serverName)
+	if err != nil {
+		return fmt.Errorf("failed to get MCP server %s: %w", serverName, err)
+	}
+	if err := m.deregisterServerTools(s); err != nil {
+		return fmt.Errorf("failed to remove the recorded tools of server %s: %w", serverName, err)
+	}
+	conn, err := createMcpServerConn(ctx, s)
+	if err != nil {
+		slog.WarnContext(ctx, "server left replay mode but can't be reached, it has no tools until it is registered again",
+			slog.String(logging.KeyServer, serverName), logging.Err(err))
+		return nil
+	}
+	defer conn.Close()
+	if err := m.registerServerTools(ctx, s, conn); err != nil {
+		slog.WarnContext(ctx, "server left replay mode but its tools can't be fetched, it has no tools until it is registered again",
+			slog.String(logging.KeyServer, serverName), logging.Err(err))
+	}
+	return nil
+}
+
+// ListCassettes reports the cassette of every server in record or replay mode.
+func (m *MCPService) ListCassettes() ([]CassetteStatus, error) {
+	var configs []model.CassetteConfig
+	if err := m.db.Order("server_name").Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list cassette configs: %w", err)
+	}
+	statuses := make([]CassetteStatus, len(configs))
+	for i, cfg := range configs {
+		if p := m.cassettes.get(cfg.ServerName); p != nil {
+			statuses[i] = p.status()
+		} else {
+			statuses[i] = CassetteStatus{CassetteConfig: cfg}
+		}
+	}
+	return statuses, nil
+}
+
+// loadCassettes loads the cassettes of the servers in record or replay mode.
+// A cassette that can't be loaded is reported instead of failing the registry's startup:
+// its server records a new cassette, or has no recorded calls to replay.
+func (m *MCPService) loadCassettes() error {
+	var configs []model.CassetteConfig
+	if err := m.db.Find(&configs).Error; err != nil {
+		return fmt.Errorf("failed to load cassette configs: %w", err)
+	}
+	for _, cfg := range configs {
+		c, err := m.cassettes.read(cfg.Path)
+		if err != nil {
+			slog.Error("failed to load cassette",
+				slog.String(logging.KeyServer, cfg.ServerName), slog.String("mode", string(cfg.Mode)), logging.Err(err))
+			c = &cassette{cassetteHeader: cassetteHeader{Version: cassetteVersion, Server: cassetteServer{Name: cfg.ServerName}}}
+		}
+		p := newCassettePlayer(cfg, c, m.cassettes.dir)
+		if err != nil {
+			p.loadErr = err.Error()
+		}
+		m.cassettes.set(p)
+	}
+	return nil
+}
+
+// openCassetteDir opens the cassette directory, creating it if needed.
+// Cassette files are only accessed through the returned root, which doesn't let paths or symbolic links escape it.
+func openCassetteDir(dir string) (*os.Root, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory %s: %w", dir, err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette directory %s: %w", dir, err)
+	}
+	return root, nil
+}
+
+// readCassette reads the cassette file at path, relative to the cassette directory.
+func readCassette(dir, path string) (*cassette, error) {
+	root, err := openCassetteDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	f, err := root.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	defer f.Close()
+
+	var c cassette
+	dec := json.NewDecoder(bufio.NewReader(f))
+	if err := dec.Decode(&c.cassetteHeader); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported version %d of cassette %s, expected %d", c.Version, path, cassetteVersion)
+	}
+	for {
+		var call cassetteCall
+		err := dec.Decode(&call)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode call %d of cassette %s: %w", len(c.Calls)+1, path, err)
+		}
+		c.Calls = append(c.Calls, call)
+	}
+	return &c, nil
+}
+
+// startCassette writes a new cassette file at path, relative to the cassette directory, with the header of the cassette.
+// An existing file is overwritten.
+func startCassette(dir, path string, c *cassette) error {
+	return writeCassetteLine(dir, path, os.O_TRUNC, c.cassetteHeader)
+}
+
+// appendCassetteCall adds a recorded call to the cassette file at path, relative to the cassette directory.
+func appendCassetteCall(dir, path string, call cassetteCall) error {
+	return writeCassetteLine(dir, path, os.O_APPEND, call)
+}
+
+func writeCassetteLine(dir, path string, flag int, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	root, err := openCassetteDir(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	f, err := root.OpenFile(path, os.O_WRONLY|os.O_CREATE|flag, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// normalizeArguments encodes the arguments of a tool call so that equivalent arguments compare equal:
+// keys are sorted, numbers are compared by value and null values are ignored.
+func normalizeArguments(args any) string {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args)
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	normalized, _ := json.Marshal(dropNulls(v))
+	if string(normalized) == "null" {
+		return "{}"
+	}
+	return string(normalized)
+}
+
+func dropNulls(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if child == nil {
+				delete(v, k)
+			} else {
+				v[k] = dropNulls(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = dropNulls(child)
+		}
+	}
+	return v
+}
+
+// cassettePlayer records tool calls to the cassette of a server, or replays them from it.
+// Its methods are safe to call on a nil player, which neither records nor replays.
+type cassettePlayer struct {
+	mu       sync.Mutex
+	cfg      model.CassetteConfig
+	cassette *cassette
+	loadErr  string
+	// dir is the cassette directory that the path of the cassette is relative to
+	dir string
+
+	// keys are the normalized tool names and arguments of the recorded calls, to match calls against
+	keys     []string
+	replayed []bool
+
+	replayedCount  int
+	unmatched      []UnmatchedCall
+	unmatchedCount int
+}
+
+func newCassettePlayer(cfg model.CassetteConfig, c *cassette, dir string) *cassettePlayer {
+	p := &cassettePlayer{cfg: cfg, cassette: c, dir: dir}
+	for _, call := range c.Calls {
+		p.keys = append(p.keys, cassetteKey(call.Tool, call.Arguments))
+	}
+	p.replayed = make([]bool, len(c.Calls))
+	return p
+}
+
+func cassetteKey(tool string, args any) string {
+	return tool + "\x00" + normalizeArguments(args)
+}
+
+// replaying returns true if the calls to the server must be served from the cassette.
+func (p *cassettePlayer) replaying() bool {
+	return p != nil && p.cfg.Mode == model.CassetteModeReplay
+}
+
+// replay serves a tool call from the cassette.
+// A call that the cassette has no recording of gets a tool error result, which doesn't count against the server.
+func (p *cassettePlayer) replay(request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tool := request.Params.Name
+	key := cassetteKey(tool, request.Params.Arguments)
+	match, fallback := -1, false
+	for i, k := range p.keys {
+		if k == key && !p.replayed[i] {
+			match = i
+			break
+		}
+	}
+	if match < 0 && p.cfg.Match == model.CassetteMatchLenient {
+		// reuse the last call recorded with the same arguments, or else the first call of the same tool
+		for i, k := range p.keys {
+			if k == key {
+				match = i
+			}
+		}
+		if match < 0 {
+			for i, call := range p.cassette.Calls {
+				if call.Tool == tool {
+					match, fallback = i, true
+					break
+				}
+			}
+		}
+	}
+	if match < 0 || fallback {
+		p.unmatchedCount++
+		if len(p.unmatched) < maxUnmatchedCallsReported {
+			p.unmatched = append(p.unmatched, UnmatchedCall{
+				Tool:      tool,
+				Arguments: normalizeArguments(request.Params.Arguments),
+				Time:      time.Now(),
+				Fallback:  fallback,
+			})
+		}
+	}
+	if match < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"MCP server %s is in replay mode and its cassette has no recording of a call to tool %s with these arguments",
+			p.cfg.ServerName, tool,
+		)), nil
+	}
+
+	p.replayed[match] = true
+	p.replayedCount++
+	call := p.cassette.Calls[match]
+	if call.Error != "" {
+		return nil, errors.New(call.Error)
+	}
+	result, err := mcp.ParseCallToolResult(&call.Result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the recorded result of tool %s: %w", tool, err)
+	}
+	return result, nil
+}
+
+// record adds a tool call to the cassette if the server is in record mode.
+// Only the outcomes that the server is responsible for are recorded, not the calls that timed out or failed to connect.
+func (p *cassettePlayer) record(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	result *mcp.CallToolResult,
+	callErr error,
+	errorType string,
+) {
+	if p == nil || p.cfg.Mode != model.CassetteModeRecord {
+		return
+	}
+	switch errorType {
+	case "", model.ToolCallErrorTool, model.ToolCallErrorUpstream:
+	default:
+		return
+	}
+
+	call := cassetteCall{Tool: request.Params.Name, Arguments: request.Params.Arguments, RecordedAt: time.Now().UTC()}
+	if callErr != nil {
+		call.Error = callErr.Error()
+	} else {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to record tool call to cassette", logging.Err(err))
+			return
+		}
+		call.Result = raw
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cassette.Calls = append(p.cassette.Calls, call)
+	p.keys = append(p.keys, cassetteKey(call.Tool, call.Arguments))
+	p.replayed = append(p.replayed, false)
+	// only the new call is written, the lock keeps the calls in the file in the order they are in the cassette
+	if err := appendCassetteCall(p.dir, p.cfg.Path, call); err != nil {
+		slog.ErrorContext(ctx, "failed to record tool call to cassette", logging.Err(err))
+	}
+}
+
+func (p *cassettePlayer) status() CassetteStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := CassetteStatus{
+		CassetteConfig: p.cfg,
+		RecordedCalls:  len(p.cassette.Calls),
+		ReplayedCalls:  p.replayedCount,
+		UnmatchedCount: p.unmatchedCount,
+		Unmatched:      append([]UnmatchedCall(nil), p.unmatched...),
+		Error:          p.loadErr,
+	}
+	for _, replayed := range p.replayed {
+		if !replayed {
+			s.UnusedCalls++
+		}
+	}
+	return s
+}
+
+// cassetteRegistry holds the cassette players of the servers in record or replay mode.
+// A nil registry has no players.
+type cassetteRegistry struct {
+	// dir is the directory that holds the cassette files, cassette paths are relative to it
+	dir string
+
+	mu      sync.RWMutex
+	players map[string]*cassettePlayer
+}
+
+func newCassetteRegistry(dir string) *cassetteRegistry {
+	return &cassetteRegistry{dir: dir, players: make(map[string]*cassettePlayer)}
+}
+
+func (r *cassetteRegistry) read(path string) (*cassette, error) {
+	return readCassette(r.dir, path)
+}
+
+func (r *cassetteRegistry) start(path string, c *cassette) error {
+	return startCassette(r.dir, path, c)
+}
+
+func (r *cassetteRegistry) get(serverName string) *cassettePlayer {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.players[serverName]
+}
+
+func (r *cassetteRegistry) set(p *cassettePlayer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.players[p.cfg.ServerName] = p
+}
+
+func (r *cassetteRegistry) remove(serverName string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.players, serverName)
+}
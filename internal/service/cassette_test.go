@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeArguments(t *testing.T) {
+	a := normalizeArguments(map[string]any{"b": 1, "a": []any{"x", nil}, "c": nil})
+	b := normalizeArguments(map[string]any{"a": []any{"x", nil}, "b": 1.0})
+	if a != b || a != `{"a":["x",null],"b":1}` {
+		t.Errorf("expected equivalent arguments to normalize the same, got %s and %s", a, b)
+	}
+	if normalizeArguments(nil) != "{}" {
+		t.Errorf("expected no arguments to normalize like empty arguments, got %s", normalizeArguments(nil))
+	}
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	upstream.AddTool(mcp.NewTool("lookup"), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("found " + req.GetString("id", "")), nil
+	})
+	ts := server.NewTestStreamableHTTPServer(upstream)
+
+	m := newTestMCPService(t)
+	dir := m.cassettes.dir
+	if err := m.db.Create(&model.McpServer{Name: "upstream", URL: ts.URL + "/mcp"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "lookup"
+		req.Params.Arguments = args
+		result, err := m.callUpstreamTool(ctx, "upstream", req)
+		if err != nil {
+			t.Fatalf("tool call failed: %v", err)
+		}
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		if len(result.Content) == 0 {
+			return ""
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	// cassettes can't be read or written outside of the cassette directory
+	for _, path := range []string{"", "/etc/passwd", "../upstream.jsonl", "cassettes/../../upstream.jsonl", "."} {
+		cfg := &model.CassetteConfig{ServerName: "upstream", Mode: model.CassetteModeReplay, Path: path}
+		if err := m.SetCassetteConfig(ctx, cfg); err == nil {
+			t.Errorf("expected the cassette path %q to be rejected", path)
+		}
+	}
+
+	path := "upstream.jsonl"
+	if err := m.SetCassetteConfig(ctx, &model.CassetteConfig{ServerName: "upstream", Mode: "rewind", Path: path}); err == nil {
+		t.Errorf("expected an invalid mode to be rejected")
+	}
+	if err := m.SetCassetteConfig(ctx, &model.CassetteConfig{ServerName: "upstream", Mode: model.CassetteModeRecord, Path: path}); err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+	call(map[string]any{"id": "1", "verbose": true})
+	call(map[string]any{"id": "2"})
+	// the calls are appended to the cassette as they are recorded, after its header
+	raw, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		t.Fatalf("failed to read the cassette: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(raw)), "\n"); len(lines) != 3 || !strings.Contains(lines[2], `"id":"2"`) {
+		t.Fatalf("expected the header and a line per call in the cassette, got:\n%s", raw)
+	}
+
+	// replay in a new registry without the upstream server, as in CI
+	ts.Close()
+	m.db = newTestDB(t)
+	m.mcpProxyServer = server.NewMCPServer("proxy", "0.0.1")
+	m.cassettes = newCassetteRegistry(dir)
+	replay := &model.CassetteConfig{ServerName: "upstream", Mode: model.CassetteModeReplay, Path: path}
+	if err := m.SetCassetteConfig(ctx, replay); err != nil {
+		t.Fatalf("failed to start replaying: %v", err)
+	}
+	if replay.Match != model.CassetteMatchStrict {
+		t.Errorf("expected strict matching by default, got %s", replay.Match)
+	}
+	if tool, err := m.GetTool("upstream/lookup"); err != nil || tool == nil {
+		t.Fatalf("expected the server and its tools to be registered from the cassette: %v", err)
+	}
+
+	if got := text(call(map[string]any{"verbose": true, "id": "1"})); got != "found 1" {
+		t.Errorf("expected the recorded result regardless of the order of the arguments, got %q", got)
+	}
+	// strict matching replays every recorded call once
+	if result := call(map[string]any{"id": "1", "verbose": true}); !result.IsError {
+		t.Errorf("expected a repeated call to be unmatched with strict matching, got %q", text(result))
+	}
+	if result := call(map[string]any{"id": "3"}); !result.IsError {
+		t.Errorf("expected a call without a recording to fail, got %q", text(result))
+	}
+
+	statuses, err := m.ListCassettes()
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("failed to list cassettes: %v", err)
+	}
+	s := statuses[0]
+	if s.RecordedCalls != 2 || s.ReplayedCalls != 1 || s.UnusedCalls != 1 || s.UnmatchedCount != 2 {
+		t.Errorf("unexpected cassette status %+v", s)
+	}
+	if s.Unmatched[1].Tool != "lookup" || s.Unmatched[1].Arguments != `{"id":"3"}` {
+		t.Errorf("expected the unmatched calls to be reported, got %+v", s.Unmatched)
+	}
+
+	replay.Match = model.CassetteMatchLenient
+	if err := m.SetCassetteConfig(ctx, replay); err != nil {
+		t.Fatalf("failed to switch to lenient matching: %v", err)
+	}
+	for range 2 {
+		if got := text(call(map[string]any{"id": "2"})); got != "found 2" {
+			t.Errorf("expected recorded calls to be reused with lenient matching, got %q", got)
+		}
+	}
+	if result := call(map[string]any{"id": "3"}); result.IsError || text(result) != "found 1" {
+		t.Errorf("expected lenient matching to fall back to a call of the same tool, got %q", text(result))
+	}
+	statuses, _ = m.ListCassettes()
+	if s := statuses[0]; s.UnmatchedCount != 1 || !s.Unmatched[0].Fallback {
+		t.Errorf("expected the fallback to be reported, got %+v", s)
+	}
+
+	// leaving replay mode brings back the tools that the server provides now
+	upstream.AddTool(mcp.NewTool("search"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("nothing"), nil
+	})
+	ts = server.NewTestStreamableHTTPServer(upstream)
+	defer ts.Close()
+	if err := m.db.Model(&model.McpServer{}).Where("name = ?", "upstream").Update("url", ts.URL+"/mcp").Error; err != nil {
+		t.Fatalf("failed to update server: %v", err)
+	}
+	if err := m.DeleteCassetteConfig(ctx, "upstream"); err != nil {
+		t.Fatalf("failed to stop replaying: %v", err)
+	}
+	if m.cassettes.get("upstream") != nil {
+		t.Errorf("expected the cassette to be ejected")
+	}
+	if _, err := m.GetTool("upstream/search"); err != nil {
+		t.Errorf("expected the current tools of the server to be registered again: %v", err)
+	}
+	if err := m.DeleteCassetteConfig(ctx, "upstream"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected stopping a server that isn't in record or replay mode to fail with gorm.ErrRecordNotFound, got %v", err)
+	}
+	// a deregistered server leaves replay mode, so that a new server of the same name isn't replayed
+	if err := m.SetCassetteConfig(ctx, replay); err != nil {
+		t.Fatalf("failed to start replaying: %v", err)
+	}
+	if err := m.DeregisterMcpServer("upstream"); err != nil {
+		t.Fatalf("failed to deregister server: %v", err)
+	}
+	if m.cassettes.get("upstream") != nil {
+		t.Errorf("expected the cassette of the deregistered server to be ejected")
+	}
+	var configs int64
+	m.db.Model(&model.CassetteConfig{}).Where("server_name = ?", "upstream").Count(&configs)
+	if configs != 0 {
+		t.Errorf("expected the cassette config of the deregistered server to be deleted")
+	}
+}
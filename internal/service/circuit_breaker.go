@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// ServerUnavailableMetaKey is the key of the _meta entry that describes the circuit breaker
+// in the result of a tool call that failed fast because the server is unavailable.
+const ServerUnavailableMetaKey = "mcpjungle/server_unavailable"
+
+const (
+	// BreakerStateClosed means calls go through to the server
+	BreakerStateClosed = "closed"
+	// BreakerStateOpen means calls fail fast without contacting the server
+	BreakerStateOpen = "open"
+	// BreakerStateHalfOpen means a single trial call is let through to find out whether the server recovered
+	BreakerStateHalfOpen = "half_open"
+)
+
+// AlertTypeCircuitBreaker is the type of the alerts raised when the circuit breaker of a server opens.
+// The alert is resolved once the breaker closes again.
+const AlertTypeCircuitBreaker = "circuit_breaker"
+
+// ServerUnavailableError is returned when a tool call fails fast because the circuit breaker of its server is open.
+type ServerUnavailableError struct {
+	ServerName string `json:"server_name"`
+	State      string `json:"state"`
+	// RetryAfter is how long until the breaker lets a trial call through
+	RetryAfter   time.Duration `json:"-"`
+	RetryAfterMs int64         `json:"retry_after_ms,omitempty"`
+}
+
+func (e *ServerUnavailableError) Error() string {
+	msg := fmt.Sprintf("MCP server %s is unavailable because its recent calls failed", e.ServerName)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return msg
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+func (e *ServerUnavailableError) ToolResult() *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Result:  mcp.Result{Meta: map[string]any{ServerUnavailableMetaKey: e}},
+		Content: []mcp.Content{mcp.NewTextContent(e.Error())},
+		IsError: true,
+	}
+}
+
+// CircuitBreakerStatus is the current state of the circuit breaker of a server.
+type CircuitBreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+type breakerConfig struct {
+	// failureThreshold is the number of consecutive failures that opens the breaker, 0 disables the breaker
+	failureThreshold int
+	cooldown         time.Duration
+}
+
+// isBreakerFailure tells whether a call failed in a way that indicates that its server is unhealthy.
+// Tool error results and calls cancelled by the caller don't count.
+func isBreakerFailure(errorType string) bool {
+	switch errorType {
+	case model.ToolCallErrorConnection, model.ToolCallErrorTimeout, model.ToolCallErrorUpstream:
+		return true
+	}
+	return false
+}
+
+// circuitBreakers holds the circuit breaker of every upstream server.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+type circuitBreaker struct {
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	// probing is true while the trial call of a half-open breaker is in flight
+	probing bool
+}
+
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{breakers: make(map[string]*circuitBreaker)}
+}
+
+func (b *circuitBreakers) get(serverName string) *circuitBreaker {
+	cb, ok := b.breakers[serverName]
+	if !ok {
+		cb = &circuitBreaker{state: BreakerStateClosed}
+		b.breakers[serverName] = cb
+	}
+	return cb
+}
+
+// allow decides whether a call to the server may go through.
+// If the call is the trial call of a half-open breaker, probe is true and the call's outcome decides the breaker's state.
+// transition is the state the breaker moved to, if it changed.
+func (b *circuitBreakers) allow(serverName string, cfg breakerConfig, now time.Time) (probe bool, transition string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb := b.get(serverName)
+	if cfg.failureThreshold == 0 {
+		// the breaker is disabled
+		if cb.state != BreakerStateClosed {
+			*cb = circuitBreaker{state: BreakerStateClosed}
+			return false, BreakerStateClosed, nil
+		}
+		return false, "", nil
+	}
+
+	switch cb.state {
+	case BreakerStateOpen:
+		if wait := cb.openedAt.Add(cfg.cooldown).Sub(now); wait > 0 {
+			return false, "", &ServerUnavailableError{
+				ServerName:   serverName,
+				State:        cb.state,
+				RetryAfter:   wait,
+				RetryAfterMs: wait.Milliseconds(),
+			}
+		}
+		cb.state = BreakerStateHalfOpen
+		cb.probing = true
+		return true, BreakerStateHalfOpen, nil
+	case BreakerStateHalfOpen:
+		if cb.probing {
+			return false, "", &ServerUnavailableError{ServerName: serverName, State: cb.state}
+		}
+		cb.probing = true
+		return true, "", nil
+	}
+	return false, "", nil
+}
+
+// record updates the breaker with the outcome of a call that went through.
+// transition is the state the breaker moved to, if it changed.
+func (b *circuitBreakers) record(serverName string, cfg breakerConfig, probe, failed bool, now time.Time) (transition string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb := b.get(serverName)
+	if probe {
+		cb.probing = false
+	}
+	if !failed {
+		cb.consecutiveFailures = 0
+		if cb.state != BreakerStateClosed && probe {
+			cb.state = BreakerStateClosed
+			return BreakerStateClosed
+		}
+		return ""
+	}
+
+	cb.consecutiveFailures++
+	switch {
+	case cb.state == BreakerStateHalfOpen && probe:
+		// the server hasn't recovered yet
+		cb.state = BreakerStateOpen
+		cb.openedAt = now
+		return BreakerStateOpen
+	case cb.state == BreakerStateClosed && cfg.failureThreshold > 0 && cb.consecutiveFailures >= cfg.failureThreshold:
+		cb.state = BreakerStateOpen
+		cb.openedAt = now
+		return BreakerStateOpen
+	}
+	return ""
+}
+
+// recordOutcome updates the breaker with the outcome of a call that went through, given the type of its error.
+// A cancelled call says nothing about the server's health, so it is treated like a call that was never made:
+// the trial call of a half-open breaker that is cancelled, eg- by its caller or by the drain, leaves the breaker half-open.
+func (b *circuitBreakers) recordOutcome(serverName string, cfg breakerConfig, probe bool, errorType string, now time.Time) (transition string) {
+	if errorType == model.ToolCallErrorCancelled {
+		b.abandon(serverName, probe)
+		return ""
+	}
+	return b.record(serverName, cfg, probe, isBreakerFailure(errorType), now)
+}
+
+// abandon releases the trial call of a half-open breaker that was not made after all, eg- because it was throttled.
+func (b *circuitBreakers) abandon(serverName string, probe bool) {
+	if !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.get(serverName).probing = false
+}
+
+// forget drops the breaker of a server, eg- because the server was deregistered.
+func (b *circuitBreakers) forget(serverName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.breakers, serverName)
+}
+
+func (b *circuitBreakers) status(serverName string) CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.breakers[serverName]
+	if !ok {
+		return CircuitBreakerStatus{State: BreakerStateClosed}
+	}
+	s := CircuitBreakerStatus{State: cb.state, ConsecutiveFailures: cb.consecutiveFailures}
+	if cb.state != BreakerStateClosed {
+		openedAt := cb.openedAt
+		s.OpenedAt = &openedAt
+	}
+	return s
+}
+
+// states returns the state of the circuit breaker of every server that received calls.
+func (b *circuitBreakers) states() map[string]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make(map[string]string, len(b.breakers))
+	for serverName, cb := range b.breakers {
+		states[serverName] = cb.state
+	}
+	return states
+}
+
+// GetCircuitBreakerStatus returns the state of the circuit breaker of a server.
+func (m *MCPService) GetCircuitBreakerStatus(serverName string) CircuitBreakerStatus {
+	return m.breakers.status(serverName)
+}
+
+// onBreakerTransition raises an alert when the circuit breaker of a server opens and resolves it once the breaker closes.
+func (m *MCPService) onBreakerTransition(serverName, state string) {
+	slog.Warn("circuit breaker of MCP server changed state",
+		slog.String(logging.KeyServer, serverName), slog.String("state", state))
+	if m.analyticsService == nil {
+		return
+	}
+
+	open, err := m.openBreakerAlert(serverName)
+	if err != nil {
+		slog.Error("failed to look up the circuit breaker alert", slog.String(logging.KeyServer, serverName), logging.Err(err))
+		return
+	}
+	hasOpen := open != nil
+
+	switch {
+	case state == BreakerStateOpen && !hasOpen:
+		status := m.breakers.status(serverName)
+		failures := float64(status.ConsecutiveFailures)
+		alert := &model.Alert{
+			Type:         AlertTypeCircuitBreaker,
+			Title:        fmt.Sprintf("MCP server %s is unavailable", serverName),
+			Message:      fmt.Sprintf("Circuit breaker of MCP server %s opened after %d consecutive failed calls", serverName, status.ConsecutiveFailures),
+			Severity:     "high",
+			CurrentValue: &failures,
+			ResourceType: "server",
+			ResourceID:   &serverName,
+		}
+		err = m.analyticsService.CreateAlert(alert)
+	case state == BreakerStateClosed && hasOpen:
+		err = m.analyticsService.ResolveAlert(open.ID.String())
+	}
+	if err != nil {
+		slog.Error("failed to update the circuit breaker alert", slog.String(logging.KeyServer, serverName), logging.Err(err))
+	}
+}
+
+// openBreakerAlert returns the unresolved circuit breaker alert of a server, or nil if there is none.
+func (m *MCPService) openBreakerAlert(serverName string) (*model.Alert, error) {
+	var open model.Alert
+	err := m.db.Where(
+		"type = ? AND resource_type = ? AND resource_id = ? AND resolved = ?",
+		AlertTypeCircuitBreaker, "server", serverName, false,
+	).First(&open).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &open, nil
+}
+
+// forgetServerBreaker drops the circuit breaker of a deregistered server and resolves its open alert,
+// so that a server registered again under the same name starts with a closed breaker.
+func (m *MCPService) forgetServerBreaker(serverName string) error {
+	if m.analyticsService != nil {
+		open, err := m.openBreakerAlert(serverName)
+		if err != nil {
+			return fmt.Errorf("failed to look up the circuit breaker alert of server %s: %w", serverName, err)
+		}
+		if open != nil {
+			if err := m.analyticsService.ResolveAlert(open.ID.String()); err != nil {
+				return fmt.Errorf("failed to resolve the circuit breaker alert of server %s: %w", serverName, err)
+			}
+		}
+	}
+	m.breakers.forget(serverName)
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	b := newCircuitBreakers()
+	cfg := breakerConfig{failureThreshold: 2, cooldown: time.Minute}
+	now := time.Now()
+
+	if transition := b.record("github", cfg, false, true, now); transition != "" {
+		t.Fatalf("a single failure should not open the breaker, got transition to %s", transition)
+	}
+	if transition := b.record("github", cfg, false, true, now); transition != BreakerStateOpen {
+		t.Fatalf("expected the breaker to open after 2 failures, got %q", transition)
+	}
+
+	_, _, err := b.allow("github", cfg, now.Add(time.Second))
+	var unavailable *ServerUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.RetryAfter != 59*time.Second {
+		t.Fatalf("expected calls to fail fast while the breaker is open, got %v", err)
+	}
+	if _, _, err := b.allow("gitlab", cfg, now); err != nil {
+		t.Fatalf("breakers of other servers should be unaffected: %v", err)
+	}
+
+	// after the cooldown, a single trial call goes through
+	probe, transition, err := b.allow("github", cfg, now.Add(time.Minute))
+	if err != nil || !probe || transition != BreakerStateHalfOpen {
+		t.Fatalf("expected a trial call after the cooldown, got probe %v, transition %q, err %v", probe, transition, err)
+	}
+	if _, _, err := b.allow("github", cfg, now.Add(time.Minute)); err == nil {
+		t.Fatal("expected calls to fail fast while the trial call is in flight")
+	}
+
+	// a failed trial call re-opens the breaker for another cooldown
+	if transition := b.record("github", cfg, true, true, now.Add(time.Minute)); transition != BreakerStateOpen {
+		t.Fatalf("expected the failed trial call to re-open the breaker, got %q", transition)
+	}
+	if _, _, err := b.allow("github", cfg, now.Add(90*time.Second)); err == nil {
+		t.Fatal("expected the breaker to stay open for another cooldown")
+	}
+
+	probe, _, _ = b.allow("github", cfg, now.Add(2*time.Minute))
+	if transition := b.record("github", cfg, probe, false, now.Add(2*time.Minute)); transition != BreakerStateClosed {
+		t.Fatalf("expected a successful trial call to close the breaker, got %q", transition)
+	}
+	if s := b.status("github"); s.State != BreakerStateClosed || s.ConsecutiveFailures != 0 {
+		t.Errorf("unexpected status after recovery: %+v", s)
+	}
+}
+
+func TestCircuitBreakerCancelledTrialCall(t *testing.T) {
+	b := newCircuitBreakers()
+	cfg := breakerConfig{failureThreshold: 1, cooldown: time.Minute}
+	now := time.Now()
+	b.record("github", cfg, false, true, now)
+
+	// a cancelled trial call neither closes nor re-opens the breaker, the next call is the trial call instead
+	probe, _, _ := b.allow("github", cfg, now.Add(time.Minute))
+	if transition := b.recordOutcome("github", cfg, probe, model.ToolCallErrorCancelled, now.Add(time.Minute)); transition != "" {
+		t.Fatalf("expected the cancelled trial call to leave the breaker half-open, got transition to %s", transition)
+	}
+	if s := b.status("github"); s.State != BreakerStateHalfOpen || s.ConsecutiveFailures != 1 {
+		t.Errorf("unexpected status after the cancelled trial call: %+v", s)
+	}
+	probe, _, err := b.allow("github", cfg, now.Add(time.Minute))
+	if err != nil || !probe {
+		t.Fatalf("expected another trial call, got probe %v, err %v", probe, err)
+	}
+	if transition := b.recordOutcome("github", cfg, probe, "", now.Add(time.Minute)); transition != BreakerStateClosed {
+		t.Errorf("expected the successful trial call to close the breaker, got %q", transition)
+	}
+}
+
+func TestCircuitBreakerAlerts(t *testing.T) {
+	m := newTestMCPService(t)
+	db := m.db
+	cfg := breakerConfig{failureThreshold: 1, cooldown: time.Minute}
+
+	now := time.Now()
+	m.onBreakerTransition("github", m.breakers.record("github", cfg, false, true, now))
+
+	var alerts []model.Alert
+	db.Where("type = ?", AlertTypeCircuitBreaker).Find(&alerts)
+	if len(alerts) != 1 || alerts[0].Resolved || *alerts[0].ResourceID != "github" {
+		t.Fatalf("expected an open circuit breaker alert for github, got %+v", alerts)
+	}
+
+	probe, transition, _ := m.breakers.allow("github", cfg, now.Add(time.Minute))
+	m.onBreakerTransition("github", transition)
+	m.onBreakerTransition("github", m.breakers.record("github", cfg, probe, false, now.Add(time.Minute)))
+
+	db.Where("type = ?", AlertTypeCircuitBreaker).Find(&alerts)
+	if len(alerts) != 1 || !alerts[0].Resolved {
+		t.Errorf("expected the alert to be resolved once the breaker closed, got %+v", alerts)
+	}
+}
+
+func TestDeregisterServerForgetsCircuitBreaker(t *testing.T) {
+	m := newTestMCPService(t)
+	if err := m.db.Create(&model.McpServer{Name: "github", URL: "http://localhost/mcp"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	cfg := breakerConfig{failureThreshold: 1, cooldown: time.Minute}
+	m.onBreakerTransition("github", m.breakers.record("github", cfg, false, true, time.Now()))
+
+	if err := m.DeregisterMcpServer("github"); err != nil {
+		t.Fatalf("DeregisterMcpServer() error = %v", err)
+	}
+	if _, ok := m.breakers.states()["github"]; ok {
+		t.Errorf("expected the breaker of the deregistered server to be dropped")
+	}
+	var alerts []model.Alert
+	m.db.Where("type = ?", AlertTypeCircuitBreaker).Find(&alerts)
+	if len(alerts) != 1 || !alerts[0].Resolved {
+		t.Errorf("expected the breaker alert of the deregistered server to be resolved, got %+v", alerts)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"strings"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around every change
+	diffContext = 3
+	// maxDiffCells bounds the work of computing a diff. Beyond it, the differing lines are shown
+	// as removed and added wholesale instead of finding the lines that they have in common.
+	maxDiffCells = 4 * 1024 * 1024
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// lineDiff returns a line-based diff from a to b: removed lines are prefixed with "- ", added lines with "+ "
+// and unchanged lines with "  ". Unchanged lines away from the changes are elided.
+// It returns an empty string if a and b are the same.
+func lineDiff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(strings.Split(a, "\n"), strings.Split(b, "\n"))
+
+	// only keep the unchanged lines that are close to a change
+	keep := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(ops)-1, i+diffContext); j++ {
+			keep[j] = true
+		}
+	}
+
+	var out strings.Builder
+	elided := false
+	for i, op := range ops {
+		if !keep[i] {
+			if !elided {
+				out.WriteString("  ...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		out.WriteByte(op.kind)
+		out.WriteByte(' ')
+		out.WriteString(op.line)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// diffLines computes the edit script from a to b, based on their longest common subsequence of lines.
+func diffLines(a, b []string) []diffOp {
+	var prefix, suffix []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffOp{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	ops := prefix
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, diffOp{'+', l})
+		}
+		return append(ops, suffix...)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return append(ops, suffix...)
+}
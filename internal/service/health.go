@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// healthHistoryRetention is how long the outcome of every health check is kept
+	healthHistoryRetention = 7 * 24 * time.Hour
+	// uptimeWindow is the window over which the uptime of servers is computed
+	uptimeWindow = 24 * time.Hour
+	// DefaultHealthHistoryLimit is the number of health checks returned by default in a server's history
+	DefaultHealthHistoryLimit = 100
+)
+
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusUnhealthy = "unhealthy"
+	// HealthStatusDegraded means the server is reachable but some of its canary checks are failing
+	HealthStatusDegraded = "degraded"
+	// HealthStatusUnknown means the server has not been checked yet
+	HealthStatusUnknown = "unknown"
+)
+
+// ServerHealth summarizes the recent health checks of an upstream MCP server.
+type ServerHealth struct {
+	ServerName string     `json:"server_name"`
+	Status     string     `json:"status"`
+	LatencyMs  int64      `json:"latency_ms"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+
+	// ConsecutiveFailures is the number of failed checks since the server was last healthy
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+
+	// Uptime is the fraction of the checks of the last 24 hours that succeeded, if there were any
+	Uptime *float64 `json:"uptime_24h,omitempty"`
+
+	// FailingCanaries lists the enabled canary checks of the server that reached their failure threshold
+	FailingCanaries []string `json:"failing_canaries,omitempty"`
+}
+
+// ServerHealthHistory is the health of a server along with its most recent health checks, newest first.
+type ServerHealthHistory struct {
+	ServerHealth
+	History []model.ServerHealthCheck `json:"history"`
+}
+
+// HealthService periodically probes the registered MCP servers and keeps the history of their health.
+type HealthService struct {
+	db *gorm.DB
+
+	// probeTimeout is the maximum time spent connecting to and pinging a server
+	probeTimeout time.Duration
+
+	// startedAt and shuttingDown describe the registry's own lifecycle, for its liveness and readiness probes
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(db *gorm.DB) *HealthService {
+	return &HealthService{db: db, probeTimeout: serverProbeTimeout, startedAt: time.Now()}
+}
+
+// StartProbing checks the health of all servers right away, then every interval in the background
+// until ctx is cancelled.
+func (s *HealthService) StartProbing(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.CheckServers(ctx); err != nil {
+				slog.ErrorContext(ctx, "server health check failed", logging.Err(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckServers probes every registered server concurrently and records the outcomes.
+// Servers in replay mode are not probed, since the registry doesn't need to reach them.
+// Health checks older than the retention period are deleted.
+func (s *HealthService) CheckServers(ctx context.Context) error {
+	replaying := s.db.Model(&model.CassetteConfig{}).Select("server_name").Where("mode = ?", model.CassetteModeReplay)
+	var servers []model.McpServer
+	if err := s.db.Preload("Endpoints").Where("name NOT IN (?)", replaying).Find(&servers).Error; err != nil {
+		return fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+
+	checks := make([]model.ServerHealthCheck, len(servers))
+	var wg sync.WaitGroup
+	for i := range servers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checks[i] = s.probeServer(ctx, &servers[i])
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		// the checks were interrupted, their outcomes say nothing about the servers
+		return nil
+	}
+
+	if len(checks) > 0 {
+		if err := s.db.Create(&checks).Error; err != nil {
+			return fmt.Errorf("failed to record health checks: %w", err)
+		}
+	}
+	for _, check := range checks {
+		if err := s.countUptimeCheck(&check); err != nil {
+			return fmt.Errorf("failed to count health check of server %s: %w", check.ServerName, err)
+		}
+	}
+	cutoff := time.Now().Add(-healthHistoryRetention)
+	if err := s.db.Where("checked_at < ?", cutoff).Delete(&model.ServerHealthCheck{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old health checks: %w", err)
+	}
+	return nil
+}
+
+// countUptimeCheck adds a health check to the daily counts of its server.
+func (s *HealthService) countUptimeCheck(check *model.ServerHealthCheck) error {
+	day := model.ServerUptimeDay{
+		ServerName: check.ServerName,
+		Day:        check.CheckedAt.UTC().Format(time.DateOnly),
+		Checks:     1,
+	}
+	if check.Healthy {
+		day.HealthyChecks = 1
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "server_name"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"checks":         gorm.Expr("server_uptime_days.checks + excluded.checks"),
+			"healthy_checks": gorm.Expr("server_uptime_days.healthy_checks + excluded.healthy_checks"),
+		}),
+	}).Create(&day).Error
+}
+
+// probeServer initializes a connection with the server and pings it.
+// The latency is the time taken by both, since that's the overhead of connecting for every tool call.
+func (s *HealthService) probeServer(ctx context.Context, server *model.McpServer) model.ServerHealthCheck {
+	start := time.Now()
+	probeCtx, cancel := context.WithTimeout(ctx, s.probeTimeout)
+	defer cancel()
+
+	c, err := createMcpServerConn(probeCtx, server)
+	if err == nil {
+		if err = c.Ping(probeCtx); err != nil {
+			err = fmt.Errorf("failed to ping MCP server: %w", err)
+		}
+		_ = c.Close()
+	}
+
+	check := model.ServerHealthCheck{
+		ServerName: server.Name,
+		CheckedAt:  start,
+		Healthy:    err == nil,
+		LatencyMs:  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// ListServerHealth returns the health of every registered server.
+func (s *HealthService) ListServerHealth() ([]ServerHealth, error) {
+	var names []string
+	if err := s.db.Model(&model.McpServer{}).Order("name").Pluck("name", &names).Error; err != nil {
+		return nil, fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+	health := make([]ServerHealth, len(names))
+	for i, name := range names {
+		h, err := s.serverHealth(name, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		health[i] = *h
+	}
+	return health, nil
+}
+
+// GetServerHealthHistory returns the health of a server along with its most recent health checks.
+func (s *HealthService) GetServerHealthHistory(name string, limit int) (*ServerHealthHistory, error) {
+	if err := s.db.Where("name = ?", name).First(&model.McpServer{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to get MCP server %s: %w", name, err)
+	}
+	h, err := s.serverHealth(name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	history := &ServerHealthHistory{ServerHealth: *h}
+	err = s.db.Where("server_name = ?", name).
+		Order("checked_at DESC").
+		Limit(limit).
+		Find(&history.History).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the health checks of server %s: %w", name, err)
+	}
+	return history, nil
+}
+
+// serverHealth summarizes the health checks of a server.
+func (s *HealthService) serverHealth(name string, now time.Time) (*ServerHealth, error) {
+	h := &ServerHealth{ServerName: name, Status: HealthStatusUnknown}
+	// a new session so that the query can be reused as the base of the ones below
+	checks := s.db.Model(&model.ServerHealthCheck{}).Where("server_name = ?", name).Session(&gorm.Session{})
+
+	latest, err := latestHealthCheck(checks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest health check of server %s: %w", name, err)
+	}
+	if latest == nil {
+		return h, nil
+	}
+	h.Status = HealthStatusUnhealthy
+	if latest.Healthy {
+		h.Status = HealthStatusHealthy
+	}
+	h.LatencyMs = latest.LatencyMs
+	h.CheckedAt = &latest.CheckedAt
+
+	err = s.db.Model(&model.CanaryCheck{}).
+		Where("server_name = ? AND enabled = ? AND consecutive_failures >= failure_threshold", name, true).
+		Order("name").
+		Pluck("name", &h.FailingCanaries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the failing canaries of server %s: %w", name, err)
+	}
+	if h.Status == HealthStatusHealthy && len(h.FailingCanaries) > 0 {
+		h.Status = HealthStatusDegraded
+	}
+
+	lastFailure, err := latestHealthCheck(checks.Where("healthy = ?", false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest failed health check of server %s: %w", name, err)
+	}
+	if lastFailure != nil {
+		h.LastError = lastFailure.Error
+		h.LastErrorAt = &lastFailure.CheckedAt
+
+		failures := checks.Where("healthy = ?", false)
+		lastSuccess, err := latestHealthCheck(checks.Where("healthy = ?", true))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest successful health check of server %s: %w", name, err)
+		}
+		if lastSuccess != nil {
+			failures = failures.Where("checked_at > ?", lastSuccess.CheckedAt)
+		}
+		if err := failures.Count(&h.ConsecutiveFailures).Error; err != nil {
+			return nil, fmt.Errorf("failed to count the failed health checks of server %s: %w", name, err)
+		}
+	}
+
+	var total, healthy int64
+	err = checks.
+		Where("checked_at >= ?", now.Add(-uptimeWindow)).
+		Select("COUNT(*), COALESCE(SUM(CASE WHEN healthy THEN 1 ELSE 0 END), 0)").
+		Row().Scan(&total, &healthy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the uptime of server %s: %w", name, err)
+	}
+	if total > 0 {
+		uptime := float64(healthy) / float64(total)
+		h.Uptime = &uptime
+	}
+	return h, nil
+}
+
+// latestHealthCheck returns the most recent health check matched by the query, or nil if there is none.
+func latestHealthCheck(q *gorm.DB) (*model.ServerHealthCheck, error) {
+	var check model.ServerHealthCheck
+	err := q.Order("checked_at DESC").First(&check).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
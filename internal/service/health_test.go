@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/migrations"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+func TestHealthServiceChecksServers(t *testing.T) {
+	live := httptest.NewServer(server.NewStreamableHTTPServer(server.NewMCPServer("live", "0.0.1")))
+	defer live.Close()
+	dead := httptest.NewServer(nil)
+	dead.Close()
+
+	db := newTestDB(t)
+	for _, s := range []*model.McpServer{{Name: "live", URL: live.URL}, {Name: "dead", URL: dead.URL}} {
+		if err := db.Create(s).Error; err != nil {
+			t.Fatalf("failed to create server: %v", err)
+		}
+	}
+	s := NewHealthService(db)
+
+	health, err := s.ListServerHealth()
+	if err != nil {
+		t.Fatalf("failed to list server health: %v", err)
+	}
+	for _, h := range health {
+		if h.Status != HealthStatusUnknown {
+			t.Fatalf("expected servers not checked yet to have an unknown status, got %+v", h)
+		}
+	}
+
+	// a check older than the retention period is deleted by the next round of checks
+	old := model.ServerHealthCheck{ServerName: "live", CheckedAt: time.Now().Add(-8 * 24 * time.Hour), Healthy: true}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatalf("failed to create health check: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.CheckServers(context.Background()); err != nil {
+			t.Fatalf("failed to check servers: %v", err)
+		}
+	}
+
+	health, err = s.ListServerHealth()
+	if err != nil {
+		t.Fatalf("failed to list server health: %v", err)
+	}
+	byName := map[string]ServerHealth{}
+	for _, h := range health {
+		byName[h.ServerName] = h
+	}
+	if h := byName["live"]; h.Status != HealthStatusHealthy || h.Uptime == nil || *h.Uptime != 1 || h.LastError != "" {
+		t.Fatalf("expected the live server to be healthy, got %+v", h)
+	}
+	if h := byName["dead"]; h.Status != HealthStatusUnhealthy || h.ConsecutiveFailures != 2 || h.LastError == "" || *h.Uptime != 0 {
+		t.Fatalf("expected the dead server to be unhealthy for 2 checks, got %+v", h)
+	}
+
+	history, err := s.GetServerHealthHistory("live", 10)
+	if err != nil {
+		t.Fatalf("failed to get server health history: %v", err)
+	}
+	if len(history.History) != 2 || !history.History[0].CheckedAt.After(history.History[1].CheckedAt) {
+		t.Fatalf("expected the 2 recent checks newest first, got %+v", history.History)
+	}
+	if _, err := s.GetServerHealthHistory("unknown", 10); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected getting the history of an unregistered server to fail with gorm.ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	db := newTestDB(t)
+	s := NewHealthService(db)
+	proxy := server.NewMCPServer("proxy", "0.0.1", server.WithToolCapabilities(true))
+	proxy.AddTool(mcp.NewTool("github__search"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	m := &MCPService{db: db, mcpProxyServer: proxy}
+
+	report := s.CheckReadiness(context.Background(), m)
+	if !report.OK() {
+		t.Fatalf("expected the registry to be ready, got %+v", report)
+	}
+	if proxyStatus := report.Components[ComponentMCPProxy]; proxyStatus.Detail != "serving 1 tools" {
+		t.Fatalf("expected the proxy to serve 1 tool, got %+v", proxyStatus)
+	}
+
+	// a database behind the schema version of this build isn't ready
+	if err := db.Where("version = ?", migrations.SchemaVersion).Delete(&model.SchemaMigration{}).Error; err != nil {
+		t.Fatalf("failed to delete schema version: %v", err)
+	}
+	report = s.CheckReadiness(context.Background(), m)
+	if report.OK() || report.Components[ComponentMigrations].OK || !report.Components[ComponentDatabase].OK {
+		t.Fatalf("expected only the migrations to fail, got %+v", report)
+	}
+	if err := migrations.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// a registry shutting down isn't ready, but is still alive
+	s.MarkShuttingDown()
+	if report = s.CheckReadiness(context.Background(), m); report.OK() || report.Components[ComponentShutdown].OK {
+		t.Fatalf("expected the registry not to be ready while shutting down, got %+v", report)
+	}
+	if !s.CheckLiveness().OK() {
+		t.Fatalf("expected the registry to be alive while shutting down")
+	}
+
+	// an unreachable database isn't ready
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+	if report = s.CheckReadiness(context.Background(), m); report.Components[ComponentDatabase].OK {
+		t.Fatalf("expected the database check to fail once it is closed, got %+v", report)
+	}
+}
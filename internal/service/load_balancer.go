@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/client"
+)
+
+// EndpointStatus is the runtime state of one of the endpoints of an MCP server.
+type EndpointStatus struct {
+	URL       string     `json:"url"`
+	Weight    int        `json:"weight"`
+	Healthy   bool       `json:"healthy"`
+	InFlight  int        `json:"in_flight"`
+	LastError string     `json:"last_error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+type endpointState struct {
+	healthy   bool
+	inFlight  int
+	lastError string
+	checkedAt time.Time
+
+	// currentWeight drives the smooth weighted round robin
+	currentWeight int
+}
+
+// endpointBalancer chooses the endpoint that each tool call to an MCP server is sent to.
+// It keeps the health and the in-flight calls of every endpoint, keyed by server name and endpoint URL.
+type endpointBalancer struct {
+	mu      sync.Mutex
+	servers map[string]map[string]*endpointState
+}
+
+func newEndpointBalancer() *endpointBalancer {
+	return &endpointBalancer{servers: make(map[string]map[string]*endpointState)}
+}
+
+// serverEndpoints returns the endpoints serving the server, ie, just its URL if it doesn't list any.
+func serverEndpoints(s *model.McpServer) []model.ServerEndpoint {
+	if len(s.Endpoints) == 0 {
+		return []model.ServerEndpoint{{URL: s.URL, Weight: 1}}
+	}
+	return s.Endpoints
+}
+
+// validateServerEndpoints validates the endpoints and the load balancing strategy of a server being registered.
+// Endpoint weights default to 1 and the server's URL is set to its first endpoint.
+func validateServerEndpoints(s *model.McpServer) error {
+	switch s.LoadBalancing {
+	case "", model.LoadBalancingRoundRobin, model.LoadBalancingLeastInFlight:
+	default:
+		return fmt.Errorf(
+			"invalid load balancing strategy '%s': must be one of %s, %s",
+			s.LoadBalancing, model.LoadBalancingRoundRobin, model.LoadBalancingLeastInFlight,
+		)
+	}
+	if len(s.Endpoints) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for i := range s.Endpoints {
+		e := &s.Endpoints[i]
+		if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid endpoint '%s': must be a valid http/https URL", e.URL)
+		}
+		if seen[e.URL] {
+			return fmt.Errorf("endpoint %s is listed more than once", e.URL)
+		}
+		seen[e.URL] = true
+		if e.Weight < 0 {
+			return fmt.Errorf("invalid weight %d of endpoint %s: must not be negative", e.Weight, e.URL)
+		}
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+	}
+	if s.URL != "" && !seen[s.URL] {
+		return fmt.Errorf("url %s must be one of the server's endpoints", s.URL)
+	}
+	s.URL = s.Endpoints[0].URL
+	return nil
+}
+
+// states returns the state of every endpoint of the server, creating the state of new endpoints
+// and dropping that of endpoints the server no longer has. The caller must hold the lock.
+func (b *endpointBalancer) states(s *model.McpServer) map[string]*endpointState {
+	endpoints := serverEndpoints(s)
+	states, ok := b.servers[s.Name]
+	if !ok {
+		states = make(map[string]*endpointState, len(endpoints))
+		b.servers[s.Name] = states
+	}
+	for _, e := range endpoints {
+		if _, ok := states[e.URL]; !ok {
+			states[e.URL] = &endpointState{healthy: true}
+		}
+	}
+	if len(states) > len(endpoints) {
+		current := make(map[string]bool, len(endpoints))
+		for _, e := range endpoints {
+			current[e.URL] = true
+		}
+		for u := range states {
+			if !current[u] {
+				delete(states, u)
+			}
+		}
+	}
+	return states
+}
+
+// pick chooses the endpoint of the server to send a call to, skipping the excluded ones.
+// Healthy endpoints are preferred, but if none of them is healthy, the unhealthy ones are tried anyway
+// since they may have recovered since they were last checked.
+// The chosen endpoint counts the call as in flight until the returned release func is called.
+func (b *endpointBalancer) pick(s *model.McpServer, exclude map[string]bool) (string, func(), bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := b.states(s)
+	var candidates, healthy []model.ServerEndpoint
+	for _, e := range serverEndpoints(s) {
+		if exclude[e.URL] {
+			continue
+		}
+		candidates = append(candidates, e)
+		if states[e.URL].healthy {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) > 0 {
+		candidates = healthy
+	}
+	if len(candidates) == 0 {
+		return "", nil, false
+	}
+
+	var chosen model.ServerEndpoint
+	if s.LoadBalancing == model.LoadBalancingLeastInFlight {
+		chosen = leastInFlight(candidates, states)
+	} else {
+		chosen = weightedRoundRobin(candidates, states)
+	}
+
+	state := states[chosen.URL]
+	state.inFlight++
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			state.inFlight--
+		})
+	}
+	return chosen.URL, release, true
+}
+
+// weightedRoundRobin implements the smooth weighted round robin, which interleaves the endpoints
+// instead of sending a burst of calls to the heaviest one.
+func weightedRoundRobin(candidates []model.ServerEndpoint, states map[string]*endpointState) model.ServerEndpoint {
+	var (
+		chosen      model.ServerEndpoint
+		chosenState *endpointState
+		total       int
+	)
+	for _, e := range candidates {
+		state := states[e.URL]
+		state.currentWeight += e.Weight
+		total += e.Weight
+		if chosenState == nil || state.currentWeight > chosenState.currentWeight {
+			chosen, chosenState = e, state
+		}
+	}
+	chosenState.currentWeight -= total
+	return chosen
+}
+
+// leastInFlight returns the endpoint with the fewest in-flight calls relative to its weight.
+func leastInFlight(candidates []model.ServerEndpoint, states map[string]*endpointState) model.ServerEndpoint {
+	chosen := candidates[0]
+	for _, e := range candidates[1:] {
+		// compare inFlight/weight without dividing
+		if states[e.URL].inFlight*chosen.Weight < states[chosen.URL].inFlight*e.Weight {
+			chosen = e
+		}
+	}
+	return chosen
+}
+
+// setHealth records the outcome of connecting to an endpoint and reports whether its health changed.
+func (b *endpointBalancer) setHealth(s *model.McpServer, endpoint string, err error, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.states(s)[endpoint]
+	if !ok {
+		return false
+	}
+	healthy := err == nil
+	changed := state.healthy != healthy
+	state.healthy = healthy
+	state.checkedAt = now
+	state.lastError = ""
+	if err != nil {
+		state.lastError = err.Error()
+	}
+	return changed
+}
+
+// status returns the state of every endpoint of the server.
+func (b *endpointBalancer) status(s *model.McpServer) []EndpointStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := b.states(s)
+	endpoints := serverEndpoints(s)
+	statuses := make([]EndpointStatus, len(endpoints))
+	for i, e := range endpoints {
+		state := states[e.URL]
+		statuses[i] = EndpointStatus{
+			URL:       e.URL,
+			Weight:    e.Weight,
+			Healthy:   state.healthy,
+			InFlight:  state.inFlight,
+			LastError: state.lastError,
+		}
+		if !state.checkedAt.IsZero() {
+			checkedAt := state.checkedAt
+			statuses[i].CheckedAt = &checkedAt
+		}
+	}
+	return statuses
+}
+
+// inFlight returns the number of calls in flight to every endpoint, keyed by server name and endpoint URL.
+func (b *endpointBalancer) inFlight() map[string]map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inFlight := make(map[string]map[string]int, len(b.servers))
+	for serverName, states := range b.servers {
+		inFlight[serverName] = make(map[string]int, len(states))
+		for endpoint, state := range states {
+			inFlight[serverName][endpoint] = state.inFlight
+		}
+	}
+	return inFlight
+}
+
+// forget drops the state of a deregistered server's endpoints.
+func (b *endpointBalancer) forget(serverName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.servers, serverName)
+}
+
+// setEndpointHealth records the health of an endpoint of the server, logging when it changes.
+func (m *MCPService) setEndpointHealth(s *model.McpServer, endpoint string, err error) {
+	if !m.balancer.setHealth(s, endpoint, err, time.Now()) || len(s.Endpoints) < 2 {
+		return
+	}
+	if err != nil {
+		slog.Warn("endpoint of MCP server is unhealthy",
+			slog.String(logging.KeyServer, s.Name), slog.String("endpoint", endpoint), logging.Err(err))
+	} else {
+		slog.Info("endpoint of MCP server is healthy again",
+			slog.String(logging.KeyServer, s.Name), slog.String("endpoint", endpoint))
+	}
+}
+
+// connectToolCall connects to an endpoint of the server chosen by its load balancing strategy.
+// If the endpoint can't be connected to, the call fails over to the server's other endpoints,
+// which is safe since the call has not been sent yet.
+// The connect timeout applies to every endpoint tried.
+// The returned release func must be called once the call is over.
+func (m *MCPService) connectToolCall(
+	ctx context.Context,
+	s *model.McpServer,
+	policy *resolvedCallPolicy,
+	toolName string,
+) (*client.Client, func(), error) {
+	tried := make(map[string]bool)
+	var lastErr error
+	for {
+		endpoint, release, ok := m.balancer.pick(s, tried)
+		if !ok {
+			return nil, nil, lastErr
+		}
+		tried[endpoint] = true
+
+		connectCtx, cancel := withCallTimeout(ctx, policy.connectTimeout, s.Name, toolName, timeoutPhaseConnect)
+		c, err := createMcpEndpointConn(connectCtx, s, endpoint)
+		cancel()
+		if err == nil {
+			m.setEndpointHealth(s, endpoint, nil)
+			return c, release, nil
+		}
+		release()
+
+		if ctx.Err() != nil {
+			// the caller gave up on the call, which says nothing about the endpoint's health
+			return nil, nil, connectionError{fmt.Errorf("failed to create connection to MCP server %s: %w", s.Name, err)}
+		}
+		m.setEndpointHealth(s, endpoint, err)
+		err = fmt.Errorf("failed to create connection to MCP server %s: %w", s.Name, err)
+		if timeoutErr := callTimeoutError(ctx, connectCtx); timeoutErr != nil {
+			lastErr = timeoutErr
+		} else {
+			lastErr = connectionError{err}
+		}
+	}
+}
+
+// StartEndpointHealthChecks runs CheckEndpointHealth every interval in the background until ctx is cancelled.
+func (m *MCPService) StartEndpointHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.CheckEndpointHealth(ctx); err != nil {
+					slog.ErrorContext(ctx, "endpoint health check failed", logging.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// CheckEndpointHealth probes every endpoint of the servers that have more than one,
+// so that unhealthy endpoints stop receiving calls and recovered ones start receiving them again.
+// Servers with a single URL are not probed since there is nothing to fail over to.
+func (m *MCPService) CheckEndpointHealth(ctx context.Context) error {
+	servers, err := m.ListMcpServers()
+	if err != nil {
+		return fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range servers {
+		s := &servers[i]
+		if len(s.Endpoints) < 2 {
+			continue
+		}
+		for _, e := range s.Endpoints {
+			wg.Add(1)
+			go func(endpoint string) {
+				defer wg.Done()
+				probeCtx, cancel := context.WithTimeout(ctx, serverProbeTimeout)
+				defer cancel()
+				c, err := createMcpEndpointConn(probeCtx, s, endpoint)
+				if err == nil {
+					_ = c.Close()
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				m.setEndpointHealth(s, endpoint, err)
+			}(e.URL)
+		}
+	}
+	wg.Wait()
+	return nil
+}
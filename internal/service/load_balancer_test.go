@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestValidateServerEndpoints(t *testing.T) {
+	s := &model.McpServer{
+		Name:      "replicated",
+		Endpoints: []model.ServerEndpoint{{URL: "http://a/mcp"}, {URL: "http://b/mcp", Weight: 3}},
+	}
+	if err := validateServerEndpoints(s); err != nil {
+		t.Fatalf("expected the endpoints to be valid, got %v", err)
+	}
+	if s.URL != "http://a/mcp" || s.Endpoints[0].Weight != 1 {
+		t.Fatalf("expected the URL to default to the first endpoint and weights to 1, got %s, %d", s.URL, s.Endpoints[0].Weight)
+	}
+
+	invalid := []*model.McpServer{
+		{Name: "x", LoadBalancing: "random"},
+		{Name: "x", Endpoints: []model.ServerEndpoint{{URL: "a/mcp"}}},
+		{Name: "x", Endpoints: []model.ServerEndpoint{{URL: "http://a/mcp"}, {URL: "http://a/mcp"}}},
+		{Name: "x", Endpoints: []model.ServerEndpoint{{URL: "http://a/mcp", Weight: -1}}},
+		{Name: "x", URL: "http://c/mcp", Endpoints: []model.ServerEndpoint{{URL: "http://a/mcp"}}},
+	}
+	for _, s := range invalid {
+		if err := validateServerEndpoints(s); err == nil {
+			t.Errorf("expected server %+v to be invalid", s)
+		}
+	}
+}
+
+func TestEndpointBalancerPick(t *testing.T) {
+	b := newEndpointBalancer()
+	s := &model.McpServer{
+		Name:      "replicated",
+		Endpoints: []model.ServerEndpoint{{URL: "a", Weight: 1}, {URL: "b", Weight: 3}},
+	}
+
+	// round robin follows the weights
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		endpoint, release, ok := b.pick(s, nil)
+		if !ok {
+			t.Fatalf("expected an endpoint to be picked")
+		}
+		counts[endpoint]++
+		release()
+	}
+	if counts["a"] != 2 || counts["b"] != 6 {
+		t.Fatalf("expected calls to be spread 2:6, got %v", counts)
+	}
+
+	// unhealthy endpoints are skipped while there is a healthy one
+	b.setHealth(s, "b", errors.New("connection refused"), time.Now())
+	for i := 0; i < 3; i++ {
+		endpoint, release, _ := b.pick(s, nil)
+		release()
+		if endpoint != "a" {
+			t.Fatalf("expected the unhealthy endpoint to be skipped, got %s", endpoint)
+		}
+	}
+	if endpoint, _, _ := b.pick(s, map[string]bool{"a": true}); endpoint != "b" {
+		t.Fatalf("expected the unhealthy endpoint to be tried when it is the only one left, got %s", endpoint)
+	}
+	if _, _, ok := b.pick(s, map[string]bool{"a": true, "b": true}); ok {
+		t.Fatalf("expected no endpoint to be picked when all are excluded")
+	}
+
+	// least in flight accounts for the weights
+	b = newEndpointBalancer()
+	s.LoadBalancing = model.LoadBalancingLeastInFlight
+	counts = map[string]int{}
+	for i := 0; i < 4; i++ {
+		endpoint, _, _ := b.pick(s, nil)
+		counts[endpoint]++
+	}
+	if counts["a"] != 1 || counts["b"] != 3 {
+		t.Fatalf("expected in-flight calls to be spread 1:3, got %v", counts)
+	}
+	for _, e := range b.status(s) {
+		if e.InFlight != counts[e.URL] {
+			t.Fatalf("expected endpoint %s to have %d calls in flight, got %d", e.URL, counts[e.URL], e.InFlight)
+		}
+	}
+}
+
+func TestForwardToolCallFailover(t *testing.T) {
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	upstream.AddTool(mcp.NewTool("echo"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	live := httptest.NewServer(server.NewStreamableHTTPServer(upstream))
+	defer live.Close()
+	dead := httptest.NewServer(nil)
+	dead.Close()
+
+	m := newTestMCPService(t)
+	db := m.db
+	s := &model.McpServer{
+		Name:      "replicated",
+		Endpoints: []model.ServerEndpoint{{URL: dead.URL}, {URL: live.URL}},
+	}
+	if err := validateServerEndpoints(s); err != nil {
+		t.Fatalf("invalid endpoints: %v", err)
+	}
+	if err := db.Create(s).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	policy, err := m.resolveCallPolicy("replicated", "echo")
+	if err != nil {
+		t.Fatalf("failed to resolve policy: %v", err)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "echo"
+	for i := 0; i < 2; i++ {
+		result, err := m.forwardToolCall(context.Background(), "replicated", policy, req)
+		if err != nil || result.IsError {
+			t.Fatalf("expected call %d to fail over to the live endpoint, got %v", i, err)
+		}
+	}
+
+	stored, err := m.GetMcpServer("replicated")
+	if err != nil {
+		t.Fatalf("failed to get server: %v", err)
+	}
+	statuses := m.balancer.status(stored)
+	if len(statuses) != 2 || statuses[0].Healthy || !statuses[1].Healthy || statuses[0].InFlight != 0 {
+		t.Fatalf("expected only the dead endpoint to be unhealthy and no calls in flight, got %+v", statuses)
+	}
+
+	if err := m.CheckEndpointHealth(context.Background()); err != nil {
+		t.Fatalf("failed to check endpoint health: %v", err)
+	}
+	if statuses := m.balancer.status(stored); statuses[0].Healthy || !statuses[1].Healthy {
+		t.Fatalf("expected the health check to agree, got %+v", statuses)
+	}
+}
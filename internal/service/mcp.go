@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+	"sync"
+
 	"github.com/mark3labs/mcp-go/server"
 	"gorm.io/gorm"
 )
@@ -11,17 +13,63 @@ import (
 type MCPService struct {
 	db             *gorm.DB
 	mcpProxyServer *server.MCPServer
+
+	// analyticsService records metrics about every tool call made through the registry
+	analyticsService *AnalyticsService
+
+	// limiter enforces the rate and concurrency limits of upstream servers and tools
+	limiter *callLimiter
+
+	// inflight tracks the tool calls of MCP clients, so that clients can cancel them
+	inflight inflightCalls
+
+	// breakers make calls to unhealthy upstream servers fail fast
+	breakers *circuitBreakers
+
+	// balancer spreads calls over the endpoints of upstream servers and tracks their health
+	balancer *endpointBalancer
+
+	// cassettes record the tool calls of servers in record mode and serve the calls of servers in replay mode
+	cassettes *cassetteRegistry
+
+	// runningCanaries holds the names of the canary checks being run, so that a canary isn't run twice at once
+	runningCanaries sync.Map
+
+	// drainer tracks the tool calls in progress, so that the registry can wait for them when shutting down
+	drainer callDrainer
 }
 
 // NewMCPService creates a new instance of MCPService.
 // It initializes the MCP proxy server by loading all registered tools from the database.
-func NewMCPService(db *gorm.DB, mcpProxyServer *server.MCPServer) (*MCPService, error) {
+// The cassettes of servers in record or replay mode are kept in cassetteDir.
+func NewMCPService(
+	db *gorm.DB,
+	mcpProxyServer *server.MCPServer,
+	analyticsService *AnalyticsService,
+	cassetteDir string,
+) (*MCPService, error) {
 	s := &MCPService{
-		db:             db,
-		mcpProxyServer: mcpProxyServer,
+		db:               db,
+		mcpProxyServer:   mcpProxyServer,
+		analyticsService: analyticsService,
+		limiter:          newCallLimiter(),
+		breakers:         newCircuitBreakers(),
+		balancer:         newEndpointBalancer(),
+		cassettes:        newCassetteRegistry(cassetteDir),
 	}
+	mcpProxyServer.AddNotificationHandler(methodNotificationCancelled, s.handleClientCancellation)
 	if err := s.initMCPProxyServer(); err != nil {
 		return nil, fmt.Errorf("failed to initialize MCP proxy server: %w", err)
 	}
+	if err := s.loadCallLimits(); err != nil {
+		return nil, err
+	}
+	if err := s.loadCassettes(); err != nil {
+		return nil, err
+	}
+	if err := s.loadMockServers(); err != nil {
+		return nil, err
+	}
+	s.registerMetrics()
 	return s, nil
 }
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestMCPService creates an MCPService on a new test database the way the registry does,
+// with analytics enabled and an empty cassette directory.
+func newTestMCPService(t *testing.T) *MCPService {
+	t.Helper()
+	db := newTestDB(t)
+	m, err := NewMCPService(db, server.NewMCPServer("proxy", "0.0.1"), NewAnalyticsService(db, nil), t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create MCP service: %v", err)
+	}
+	return m
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/metrics"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Reasons for which a tool call is rejected before it is forwarded upstream, as reported by metrics
+const (
+	rejectReasonQuota        = "quota"
+	rejectReasonCircuitOpen  = "circuit_open"
+	rejectReasonShuttingDown = "shutting_down"
+	rejectReasonInvalidArgs  = "invalid_arguments"
+)
+
+var (
+	toolCallsTotal = metrics.DefaultRegistry.NewCounter(
+		"mcpjungle_tool_calls_total",
+		"Tool calls forwarded to upstream MCP servers, by outcome (success or the type of error).",
+		"server", "tool", "outcome",
+	)
+	toolCallDuration = metrics.DefaultRegistry.NewHistogram(
+		"mcpjungle_tool_call_duration_seconds",
+		"Duration of the tool calls forwarded to upstream MCP servers, retries included.",
+		metrics.DefBuckets,
+		"server", "tool",
+	)
+	toolCallRetries = metrics.DefaultRegistry.NewCounter(
+		"mcpjungle_tool_call_retries_total",
+		"Retries of failed tool calls.",
+		"server", "tool",
+	)
+	toolCallsRejected = metrics.DefaultRegistry.NewCounter(
+		"mcpjungle_tool_calls_rejected_total",
+		"Tool calls rejected before being forwarded upstream, by reason "+
+			"(invalid_arguments, quota, rate_limit, concurrency_limit, circuit_open or shutting_down).",
+		"server", "reason",
+	)
+	upstreamConnectDuration = metrics.DefaultRegistry.NewHistogram(
+		"mcpjungle_upstream_connect_duration_seconds",
+		"Time taken to connect to an upstream MCP server and initialize a session with it, by outcome (success or error).",
+		metrics.DefBuckets,
+		"server", "outcome",
+	)
+	mcpSessionsActive = metrics.DefaultRegistry.NewGauge(
+		"mcpjungle_mcp_sessions_active",
+		"MCP client sessions connected to the proxy to receive messages from it.",
+	)
+	mcpSessionsInitialized = metrics.DefaultRegistry.NewCounter(
+		"mcpjungle_mcp_sessions_initialized_total",
+		"MCP client sessions initialized with the proxy.",
+	)
+)
+
+// observeToolCall updates the metrics of a tool call forwarded upstream.
+func observeToolCall(serverName, toolName string, elapsed time.Duration, attempts int, errorType string) {
+	outcome := errorType
+	if outcome == "" {
+		outcome = "success"
+	}
+	toolCallsTotal.Inc(serverName, toolName, outcome)
+	toolCallDuration.Observe(elapsed.Seconds(), serverName, toolName)
+	if attempts > 1 {
+		toolCallRetries.Add(float64(attempts-1), serverName, toolName)
+	}
+}
+
+// observeRejectedCall counts a tool call rejected before being forwarded upstream.
+func observeRejectedCall(serverName string, err error) {
+	var (
+		quotaErr        *QuotaExceededError
+		throttledErr    *CallThrottledError
+		unavailableErr  *ServerUnavailableError
+		shuttingDownErr *RegistryShuttingDownError
+		invalidArgsErr  *InvalidArgumentsError
+	)
+	switch {
+	case errors.As(err, &quotaErr):
+		toolCallsRejected.Inc(serverName, rejectReasonQuota)
+	case errors.As(err, &throttledErr):
+		toolCallsRejected.Inc(serverName, throttledErr.Reason)
+	case errors.As(err, &unavailableErr):
+		toolCallsRejected.Inc(serverName, rejectReasonCircuitOpen)
+	case errors.As(err, &shuttingDownErr):
+		toolCallsRejected.Inc(serverName, rejectReasonShuttingDown)
+	case errors.As(err, &invalidArgsErr):
+		toolCallsRejected.Inc(serverName, rejectReasonInvalidArgs)
+	}
+}
+
+// observeUpstreamConnect updates the metrics of a connection to an upstream MCP server.
+func observeUpstreamConnect(serverName string, elapsed time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	upstreamConnectDuration.Observe(elapsed.Seconds(), serverName, outcome)
+}
+
+// registerSessionMetricsHooks installs the hooks that count the sessions of MCP clients with the proxy.
+func registerSessionMetricsHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		mcpSessionsActive.Inc()
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		mcpSessionsActive.Dec()
+	})
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		mcpSessionsInitialized.Inc()
+	})
+}
+
+// registerMetrics registers the metrics whose values are read from the state of the MCP service when scraped.
+// They replace the metrics of any MCP service created before.
+func (m *MCPService) registerMetrics() {
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_registered_servers",
+		"MCP servers registered in the registry.",
+		nil,
+		func(emit func(float64, ...string)) {
+			var count int64
+			if err := m.db.Model(&model.McpServer{}).Count(&count).Error; err != nil {
+				slog.Error("failed to count MCP servers for metrics", logging.Err(err))
+				return
+			}
+			emit(float64(count))
+		},
+	)
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_registered_tools",
+		"Tools registered in the registry, by MCP server.",
+		[]string{"server"},
+		func(emit func(float64, ...string)) {
+			rows, err := m.db.Model(&model.Tool{}).
+				Select("mcp_servers.name, COUNT(*)").
+				Joins("JOIN mcp_servers ON mcp_servers.id = tools.server_id").
+				Group("mcp_servers.name").
+				Rows()
+			if err != nil {
+				slog.Error("failed to count tools for metrics", logging.Err(err))
+				return
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var name string
+				var count int64
+				if err := rows.Scan(&name, &count); err != nil {
+					slog.Error("failed to count tools for metrics", logging.Err(err))
+					return
+				}
+				emit(float64(count), name)
+			}
+		},
+	)
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_circuit_breaker_state",
+		"State of the circuit breaker of every MCP server that received calls, 1 for its current state and 0 for the others.",
+		[]string{"server", "state"},
+		func(emit func(float64, ...string)) {
+			for serverName, state := range m.breakers.states() {
+				for _, s := range []string{BreakerStateClosed, BreakerStateOpen, BreakerStateHalfOpen} {
+					value := 0.0
+					if s == state {
+						value = 1
+					}
+					emit(value, serverName, s)
+				}
+			}
+		},
+	)
+	m.registerCallLimitMetrics()
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_upstream_calls_in_flight",
+		"Tool calls in flight to every endpoint of the MCP servers that received calls.",
+		[]string{"server", "endpoint"},
+		func(emit func(float64, ...string)) {
+			for serverName, endpoints := range m.balancer.inFlight() {
+				for endpoint, n := range endpoints {
+					emit(float64(n), serverName, endpoint)
+				}
+			}
+		},
+	)
+}
+
+// registerCallLimitMetrics registers the metrics of the call limits.
+func (m *MCPService) registerCallLimitMetrics() {
+	labels := []string{"scope_type", "scope_value"}
+	stat := func(value func(CallLimitStats) int64) metrics.CollectFunc {
+		return func(emit func(float64, ...string)) {
+			for key, stats := range m.limiter.allStats() {
+				emit(float64(value(stats)), string(key.scopeType), key.scopeValue)
+			}
+		}
+	}
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_call_limit_allowed_total",
+		"Tool calls let through by a call limit.",
+		labels,
+		stat(func(s CallLimitStats) int64 { return s.Allowed }),
+	)
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_call_limit_queued_total",
+		"Tool calls that waited in the queue of a call limit.",
+		labels,
+		stat(func(s CallLimitStats) int64 { return s.Queued }),
+	)
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_call_limit_rejected_rate_total",
+		"Tool calls rejected by the rate limit of a call limit.",
+		labels,
+		stat(func(s CallLimitStats) int64 { return s.RejectedRate }),
+	)
+	metrics.DefaultRegistry.NewCounterFunc(
+		"mcpjungle_call_limit_rejected_concurrency_total",
+		"Tool calls rejected by the concurrency limit of a call limit.",
+		labels,
+		stat(func(s CallLimitStats) int64 { return s.RejectedConcurrency }),
+	)
+	metrics.DefaultRegistry.NewGaugeFunc(
+		"mcpjungle_call_limit_in_flight",
+		"Tool calls in flight within the scope of a call limit.",
+		labels,
+		stat(func(s CallLimitStats) int64 { return s.InFlight }),
+	)
+}
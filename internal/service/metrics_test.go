@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/metrics"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMetrics(t *testing.T) {
+	m := newTestMCPService(t)
+	db := m.db
+	if err := db.Create(&model.McpServer{Name: "metrics_test", URL: "http://metrics_test"}).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	m.limiter.set(model.CallLimit{ScopeType: model.CallScopeServer, ScopeValue: "metrics_test", MaxConcurrent: 1})
+
+	// calls rejected because the registry is shutting down are counted by reason
+	if err := m.Drain(context.Background()); err != nil {
+		t.Fatalf("failed to drain: %v", err)
+	}
+	if _, err := m.callUpstreamTool(context.Background(), "metrics_test", mcp.CallToolRequest{}); err == nil {
+		t.Fatalf("expected the call to be rejected")
+	}
+
+	var out strings.Builder
+	if err := metrics.DefaultRegistry.Write(&out); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	for _, want := range []string{
+		"mcpjungle_registered_servers 1\n",
+		`mcpjungle_tool_calls_rejected_total{server="metrics_test",reason="shutting_down"} 1` + "\n",
+		`mcpjungle_call_limit_in_flight{scope_type="server",scope_value="metrics_test"} 0` + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected the metrics to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/mock"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mockURLScheme is the scheme of the URLs of mock servers, which are served in-process.
+const mockURLScheme = "mock://"
+
+// mockServers holds the mock servers served in-process, by their URL.
+// It is global, like the connections made to upstream servers, so that every connection can reach them.
+var mockServers = &mockServerRegistry{servers: make(map[string]*server.MCPServer)}
+
+type mockServerRegistry struct {
+	mu      sync.RWMutex
+	servers map[string]*server.MCPServer
+}
+
+func (r *mockServerRegistry) set(url string, s *server.MCPServer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers[url] = s
+}
+
+func (r *mockServerRegistry) get(url string) (*server.MCPServer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.servers[url]
+	return s, ok
+}
+
+func (r *mockServerRegistry) remove(url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.servers, url)
+}
+
+// mockServerURL returns the URL of the mock server with the given name.
+func mockServerURL(name string) string {
+	return mockURLScheme + name
+}
+
+// isMockServerURL reports whether the URL is that of a mock server.
+func isMockServerURL(url string) bool {
+	return strings.HasPrefix(url, mockURLScheme)
+}
+
+// startMockServer starts serving the mock server declared by s in-process and sets its URL.
+func startMockServer(s *model.McpServer) error {
+	if len(s.Endpoints) > 0 || (s.URL != "" && s.URL != mockServerURL(s.Name)) {
+		return errors.New("a mock server must not have a URL or endpoints")
+	}
+	cfg, err := mock.Parse([]byte(s.MockConfig))
+	if err != nil {
+		return err
+	}
+	s.URL = mockServerURL(s.Name)
+	mockServers.set(s.URL, mock.NewServer(cfg))
+	return nil
+}
+
+// loadMockServers starts serving the registered mock servers when the registry starts.
+// A mock server that fails to start is logged and its calls fail, like those of an unreachable server.
+func (m *MCPService) loadMockServers() error {
+	var servers []model.McpServer
+	if err := m.db.Where("mock_config <> ''").Find(&servers).Error; err != nil {
+		return fmt.Errorf("failed to load mock servers: %w", err)
+	}
+	for i := range servers {
+		if err := startMockServer(&servers[i]); err != nil {
+			slog.Error("failed to start mock server", slog.String(logging.KeyServer, servers[i].Name), logging.Err(err))
+		}
+	}
+	return nil
+}
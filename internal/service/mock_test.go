@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRegisterMockServer(t *testing.T) {
+	m := newTestMCPService(t)
+	ctx := context.Background()
+	config := "tools: [{name: greet, responses: [{text: 'hello {{.name}}'}]}]"
+
+	invalid := []*model.McpServer{
+		{Name: "mocked", MockConfig: "tools: []"},
+		{Name: "mocked", URL: "http://localhost/mcp", MockConfig: config},
+		// the registry doesn't read files of its host on behalf of API callers
+		{Name: "mocked", MockConfig: "tools: [{name: leak, responses: [{image: {path: /etc/passwd, mime_type: image/png}}]}]"},
+	}
+	for _, s := range invalid {
+		if err := m.RegisterMcpServer(ctx, s); err == nil {
+			t.Errorf("expected mock server %+v to be rejected", s)
+		}
+	}
+
+	s := &model.McpServer{Name: "mocked", MockConfig: config}
+	if err := m.RegisterMcpServer(ctx, s); err != nil {
+		t.Fatalf("failed to register mock server: %v", err)
+	}
+	if s.URL != "mock://mocked" {
+		t.Errorf("expected the mock server to get a mock URL, got %s", s.URL)
+	}
+
+	if err := m.RegisterMcpServer(ctx, &model.McpServer{Name: "mocked", MockConfig: config}); err == nil {
+		t.Errorf("expected a mock server to be registered once")
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "greet"
+	req.Params.Arguments = map[string]any{"name": "jungle"}
+	result, err := m.callUpstreamTool(ctx, "mocked", req)
+	if err != nil || result.Content[0].(mcp.TextContent).Text != "hello jungle" {
+		t.Fatalf("expected the call to be served in-process, got %+v (%v)", result, err)
+	}
+
+	// mock servers are served again when the registry restarts
+	mockServers.remove(s.URL)
+	if err := m.loadMockServers(); err != nil {
+		t.Fatalf("failed to load mock servers: %v", err)
+	}
+	if _, err := m.callUpstreamTool(ctx, "mocked", req); err != nil {
+		t.Errorf("expected the mock server to be served after a restart: %v", err)
+	}
+
+	if err := m.DeregisterMcpServer("mocked"); err != nil {
+		t.Fatalf("failed to deregister mock server: %v", err)
+	}
+	if _, ok := mockServers.get(s.URL); ok {
+		t.Errorf("expected a deregistered mock server to stop being served")
+	}
+}
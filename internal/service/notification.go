@@ -0,0 +1,455 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// AlertEventRaised is sent when a new alert is created
+	AlertEventRaised = "alert.raised"
+	// AlertEventResolved is sent when an alert is resolved, either manually or because its condition cleared
+	AlertEventResolved = "alert.resolved"
+	// AlertEventTest is sent when a user tests a notification channel
+	AlertEventTest = "alert.test"
+
+	// SignatureHeader carries the HMAC-SHA256 signature of webhook payloads
+	SignatureHeader = "X-MCPJungle-Signature"
+)
+
+const (
+	defaultNotificationAttempts = 3
+	defaultNotificationBackoff  = 2 * time.Second
+	notificationTimeout         = 10 * time.Second
+)
+
+// severityRank orders alert severities so that they can be compared for routing.
+var severityRank = map[string]int{
+	"low":      0,
+	"medium":   1,
+	"high":     2,
+	"critical": 3,
+}
+
+// AlertNotification is the payload delivered to webhook channels.
+type AlertNotification struct {
+	Event  string      `json:"event"`
+	Alert  model.Alert `json:"alert"`
+	SentAt time.Time   `json:"sent_at"`
+}
+
+// NotificationService manages notification channels and delivers alerts to them.
+type NotificationService struct {
+	db         *gorm.DB
+	httpClient *http.Client
+
+	// maxAttempts is the number of times delivery to a channel is attempted before giving up
+	maxAttempts int
+	// backoff is the delay before the first retry, it doubles with every subsequent attempt
+	backoff time.Duration
+
+	// pending counts the deliveries in progress, so that they can be waited for on shutdown
+	pending atomic.Int64
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		db:          db,
+		httpClient:  &http.Client{Timeout: notificationTimeout},
+		maxAttempts: defaultNotificationAttempts,
+		backoff:     defaultNotificationBackoff,
+	}
+}
+
+// validateNotificationChannel checks that the channel is well-formed and fills in defaults.
+func validateNotificationChannel(c *model.NotificationChannel) error {
+	if c.Name == "" {
+		return errors.New("notification channel name is required")
+	}
+	if c.MinSeverity == "" {
+		c.MinSeverity = "low"
+	}
+	if _, ok := severityRank[c.MinSeverity]; !ok {
+		return fmt.Errorf("invalid min_severity '%s', must be one of low, medium, high, critical", c.MinSeverity)
+	}
+	switch c.Type {
+	case model.NotificationChannelWebhook, model.NotificationChannelSlack:
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("%s channels require a valid http/https url", c.Type)
+		}
+	case model.NotificationChannelEmail:
+		if c.SMTPHost == "" || c.EmailFrom == "" || c.EmailTo == "" {
+			return errors.New("email channels require smtp_host, email_from and email_to")
+		}
+		if strings.ContainsAny(c.EmailFrom+c.EmailTo, "\r\n") {
+			return errors.New("email_from and email_to cannot contain line breaks")
+		}
+		if c.SMTPPort == 0 {
+			c.SMTPPort = 25
+		}
+	default:
+		return fmt.Errorf("invalid notification channel type '%s', must be one of webhook, slack, email", c.Type)
+	}
+	return nil
+}
+
+// CreateNotificationChannel validates and stores a new notification channel.
+func (n *NotificationService) CreateNotificationChannel(c *model.NotificationChannel) error {
+	if err := validateNotificationChannel(c); err != nil {
+		return err
+	}
+	if err := n.db.Create(c).Error; err != nil {
+		return fmt.Errorf("failed to create notification channel: %w", err)
+	}
+	return nil
+}
+
+// ListNotificationChannels returns all notification channels.
+func (n *NotificationService) ListNotificationChannels() ([]model.NotificationChannel, error) {
+	var channels []model.NotificationChannel
+	if err := n.db.Order("name").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// GetNotificationChannel fetches a notification channel by name.
+func (n *NotificationService) GetNotificationChannel(name string) (*model.NotificationChannel, error) {
+	var c model.NotificationChannel
+	if err := n.db.Where("name = ?", name).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DeleteNotificationChannel deletes a notification channel by name.
+func (n *NotificationService) DeleteNotificationChannel(name string) error {
+	c, err := n.GetNotificationChannel(name)
+	if err != nil {
+		return fmt.Errorf("failed to get notification channel %s: %w", name, err)
+	}
+	if err := n.db.Delete(c).Error; err != nil {
+		return fmt.Errorf("failed to delete notification channel %s: %w", name, err)
+	}
+	return nil
+}
+
+// TestNotificationChannel synchronously delivers a test alert to the channel (without retries)
+// so that users can verify its configuration.
+func (n *NotificationService) TestNotificationChannel(ctx context.Context, name string) error {
+	c, err := n.GetNotificationChannel(name)
+	if err != nil {
+		return fmt.Errorf("failed to get notification channel %s: %w", name, err)
+	}
+	alert := model.Alert{
+		Type:         "test",
+		Title:        "Test notification from MCPJungle",
+		Message:      fmt.Sprintf("This is a test notification for channel %s", c.Name),
+		Severity:     "low",
+		ResourceType: "global",
+		CreatedAt:    time.Now(),
+	}
+	return n.deliver(ctx, c, AlertEventTest, &alert)
+}
+
+// Notify delivers an alert event to every channel whose severity route matches the alert.
+// Delivery happens in the background and is retried with exponential backoff.
+// Failures are logged and never propagated to the caller.
+func (n *NotificationService) Notify(event string, alert *model.Alert) {
+	channels, err := n.ListNotificationChannels()
+	if err != nil {
+		slog.Error("failed to list notification channels", slog.String("alert_id", alert.ID.String()), logging.Err(err))
+		return
+	}
+	// copy the alert so that the caller can't modify it while it's being delivered
+	a := *alert
+	for i := range channels {
+		c := channels[i]
+		if severityRank[a.Severity] < severityRank[c.MinSeverity] {
+			continue
+		}
+		n.pending.Add(1)
+		go func() {
+			defer n.pending.Add(-1)
+			if err := n.deliverWithRetry(context.Background(), &c, event, &a); err != nil {
+				slog.Error("failed to deliver alert",
+					slog.String("alert_id", a.ID.String()), slog.String("channel", c.Name), logging.Err(err))
+			}
+		}()
+	}
+}
+
+// Wait waits for the alert deliveries in progress to complete, until ctx is done.
+func (n *NotificationService) Wait(ctx context.Context) error {
+	for {
+		pending := n.pending.Load()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up waiting for %d alert deliveries in progress: %w", pending, ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// deliverWithRetry attempts to deliver the alert up to maxAttempts times, backing off exponentially.
+func (n *NotificationService) deliverWithRetry(ctx context.Context, c *model.NotificationChannel, event string, alert *model.Alert) error {
+	var err error
+	backoff := n.backoff
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		if err = n.deliver(ctx, c, event, alert); err == nil {
+			return nil
+		}
+		if attempt == n.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", n.maxAttempts, err)
+}
+
+// deliver makes a single attempt at delivering the alert to the channel.
+func (n *NotificationService) deliver(ctx context.Context, c *model.NotificationChannel, event string, alert *model.Alert) error {
+	switch c.Type {
+	case model.NotificationChannelWebhook:
+		body, err := json.Marshal(AlertNotification{Event: event, Alert: *alert, SentAt: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+		headers := map[string]string{}
+		if c.Secret != "" {
+			headers[SignatureHeader] = SignPayload(c.Secret, body)
+		}
+		return n.post(ctx, c.URL, body, headers)
+
+	case model.NotificationChannelSlack:
+		body, err := json.Marshal(slackPayload(event, alert))
+		if err != nil {
+			return err
+		}
+		return n.post(ctx, c.URL, body, nil)
+
+	case model.NotificationChannelEmail:
+		return sendAlertEmail(ctx, c, event, alert)
+	}
+	return fmt.Errorf("unsupported notification channel type '%s'", c.Type)
+}
+
+// post sends a JSON payload and treats any non-2xx response as a failed delivery.
+func (n *NotificationService) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("request failed with status: %d, message: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// SignPayload computes the value of the signature header for a webhook payload.
+// Receivers must compute the HMAC-SHA256 of the raw request body with the channel's secret and compare.
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// alertSummary returns a one-line human-readable summary of an alert event.
+func alertSummary(event string, alert *model.Alert) string {
+	switch event {
+	case AlertEventResolved:
+		return fmt.Sprintf("[RESOLVED] %s", alert.Title)
+	case AlertEventTest:
+		return fmt.Sprintf("[TEST] %s", alert.Title)
+	}
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Severity), alert.Title)
+}
+
+var slackSeverityColors = map[string]string{
+	"low":      "#439FE0",
+	"medium":   "#DAA038",
+	"high":     "#E8752E",
+	"critical": "#D00000",
+}
+
+// slackPayload builds a Slack incoming-webhook message for an alert event.
+func slackPayload(event string, alert *model.Alert) map[string]any {
+	color := slackSeverityColors[alert.Severity]
+	if event == AlertEventResolved {
+		color = "good"
+	}
+	fields := []map[string]any{
+		{"title": "Severity", "value": alert.Severity, "short": true},
+		{"title": "Type", "value": alert.Type, "short": true},
+	}
+	if alert.ResourceID != nil {
+		fields = append(fields, map[string]any{
+			"title": "Resource",
+			"value": alert.ResourceType + ": " + *alert.ResourceID,
+			"short": true,
+		})
+	}
+	return map[string]any{
+		"text": alertSummary(event, alert),
+		"attachments": []map[string]any{
+			{
+				"color":  color,
+				"title":  alert.Title,
+				"text":   alert.Message,
+				"fields": fields,
+				"ts":     alert.CreatedAt.Unix(),
+			},
+		},
+	}
+}
+
+// sendAlertEmail sends the alert event as a plain-text email through the channel's SMTP server.
+// The whole SMTP conversation is bounded by notificationTimeout and abandoned if ctx is done.
+func sendAlertEmail(ctx context.Context, c *model.NotificationChannel, event string, alert *model.Alert) error {
+	// channels are validated on creation, but a line break in a header would let it inject more headers
+	if strings.ContainsAny(c.EmailFrom+c.EmailTo, "\r\n") {
+		return errors.New("email_from and email_to cannot contain line breaks")
+	}
+	var to []string
+	for _, addr := range strings.Split(c.EmailTo, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\r\n\r\n", alert.Message)
+	fmt.Fprintf(&body, "Severity: %s\r\n", alert.Severity)
+	fmt.Fprintf(&body, "Type: %s\r\n", alert.Type)
+	if alert.ResourceID != nil {
+		fmt.Fprintf(&body, "Resource: %s %s\r\n", alert.ResourceType, *alert.ResourceID)
+	}
+	fmt.Fprintf(&body, "Raised at: %s\r\n", alert.CreatedAt.UTC().Format(time.RFC1123))
+	fmt.Fprintf(&body, "Alert ID: %s\r\n", alert.ID)
+
+	msg := "From: " + c.EmailFrom + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("UTF-8", stripLineBreaks(alertSummary(event, alert))) + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		body.String()
+
+	var auth smtp.Auth
+	if c.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", c.SMTPUsername, c.SMTPPassword, c.SMTPHost)
+	}
+	addr := net.JoinHostPort(c.SMTPHost, strconv.Itoa(c.SMTPPort))
+	if err := sendMail(ctx, addr, c.SMTPHost, auth, c.EmailFrom, to, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email through %s: %w", addr, err)
+	}
+	return nil
+}
+
+// stripLineBreaks replaces the line breaks of a header value with spaces.
+// Alert titles contain user-supplied names, which must not be able to start new headers.
+func stripLineBreaks(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+// sendMail does what smtp.SendMail does, over a connection that is dialed with ctx
+// and whose deadline is the earlier of ctx's deadline and notificationTimeout from now,
+// so that an SMTP server that never answers can't block delivery forever.
+func sendMail(ctx context.Context, addr, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	deadline := time.Now().Add(notificationTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	// closing the connection unblocks any read or write in progress when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+)
+
+// newTestNotificationService returns a NotificationService that retries quickly.
+func newTestNotificationService(t *testing.T) *NotificationService {
+	t.Helper()
+	n := NewNotificationService(newTestDB(t))
+	n.backoff = time.Millisecond
+	return n
+}
+
+func TestWebhookDeliverySignedAndRetried(t *testing.T) {
+	const secret = "s3cr3t"
+	var attempts atomic.Int32
+	received := make(chan AlertNotification, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first attempt to exercise retries
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), SignPayload(secret, body); got != want {
+			t.Errorf("signature header = %q, want %q", got, want)
+		}
+		var n AlertNotification
+		if err := json.Unmarshal(body, &n); err != nil {
+			t.Errorf("failed to decode webhook payload: %v", err)
+		}
+		received <- n
+	}))
+	defer srv.Close()
+
+	n := newTestNotificationService(t)
+	c := &model.NotificationChannel{Name: "hook", Type: model.NotificationChannelWebhook, URL: srv.URL, Secret: secret}
+	if err := n.CreateNotificationChannel(c); err != nil {
+		t.Fatalf("CreateNotificationChannel() error = %v", err)
+	}
+
+	n.Notify(AlertEventRaised, &model.Alert{Title: "too expensive", Severity: "high"})
+
+	select {
+	case got := <-received:
+		if got.Event != AlertEventRaised || got.Alert.Title != "too expensive" {
+			t.Errorf("unexpected webhook payload: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("got %d delivery attempts, want 2", got)
+	}
+}
+
+func TestNotifyRoutesBySeverity(t *testing.T) {
+	received := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer srv.Close()
+
+	n := newTestNotificationService(t)
+	c := &model.NotificationChannel{
+		Name:        "oncall",
+		Type:        model.NotificationChannelSlack,
+		URL:         srv.URL,
+		MinSeverity: "high",
+	}
+	if err := n.CreateNotificationChannel(c); err != nil {
+		t.Fatalf("CreateNotificationChannel() error = %v", err)
+	}
+
+	n.Notify(AlertEventRaised, &model.Alert{Title: "minor", Severity: "medium"})
+	n.Notify(AlertEventRaised, &model.Alert{Title: "major", Severity: "critical"})
+
+	select {
+	case got := <-received:
+		if text, _ := got["text"].(string); text != "[CRITICAL] major" {
+			t.Errorf("slack text = %q, want %q", text, "[CRITICAL] major")
+		}
+		if attachments, _ := got["attachments"].([]any); len(attachments) != 1 {
+			t.Errorf("got %d slack attachments, want 1", len(attachments))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("slack message was not delivered")
+	}
+	select {
+	case got := <-received:
+		t.Errorf("alert below the channel's minimum severity was delivered: %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// fakeSMTPServer accepts a single SMTP session on a local port and sends the message data it receives.
+func fakeSMTPServer(t *testing.T) (host string, port int, messages <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				ch <- data.String()
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func TestEmailDelivery(t *testing.T) {
+	host, port, messages := fakeSMTPServer(t)
+
+	n := newTestNotificationService(t)
+	c := &model.NotificationChannel{
+		Name:      "ops-mail",
+		Type:      model.NotificationChannelEmail,
+		SMTPHost:  host,
+		SMTPPort:  port,
+		EmailFrom: "mcpjungle@example.com",
+		EmailTo:   "ops@example.com, dev@example.com",
+	}
+	if err := n.CreateNotificationChannel(c); err != nil {
+		t.Fatalf("CreateNotificationChannel() error = %v", err)
+	}
+	if err := n.TestNotificationChannel(context.Background(), "ops-mail"); err != nil {
+		t.Fatalf("TestNotificationChannel() error = %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		for _, want := range []string{
+			"To: ops@example.com, dev@example.com",
+			"Subject: [TEST] Test notification from MCPJungle",
+			"channel ops-mail",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("email does not contain %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("email was not delivered to port " + strconv.Itoa(port))
+	}
+}
+
+func TestEmailDeliveryGivesUpOnSilentServer(t *testing.T) {
+	// the server accepts connections but never sends its greeting
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	c := &model.NotificationChannel{
+		Name:      "silent-mail",
+		Type:      model.NotificationChannelEmail,
+		SMTPHost:  addr.IP.String(),
+		SMTPPort:  addr.Port,
+		EmailFrom: "mcpjungle@example.com",
+		EmailTo:   "ops@example.com",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := sendAlertEmail(ctx, c, AlertEventTest, &model.Alert{Title: "t"}); err == nil {
+		t.Fatal("expected delivery to a silent server to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("delivery took %s, expected it to give up once ctx was done", elapsed)
+	}
+}
+
+func TestEmailHeadersCannotBeInjected(t *testing.T) {
+	host, port, messages := fakeSMTPServer(t)
+
+	n := newTestNotificationService(t)
+	c := &model.NotificationChannel{
+		Name:      "ops-mail",
+		Type:      model.NotificationChannelEmail,
+		SMTPHost:  host,
+		SMTPPort:  port,
+		EmailFrom: "mcpjungle@example.com\r\nBcc: evil@example.com",
+		EmailTo:   "ops@example.com",
+	}
+	if err := n.CreateNotificationChannel(c); err == nil {
+		t.Fatal("expected a channel with a line break in email_from to be rejected")
+	}
+
+	c.EmailFrom = "mcpjungle@example.com"
+	alert := &model.Alert{Title: "Rule fired\r\nBcc: evil@example.com", Severity: "high"}
+	if err := sendAlertEmail(context.Background(), c, AlertEventRaised, alert); err != nil {
+		t.Fatalf("sendAlertEmail() error = %v", err)
+	}
+	select {
+	case msg := <-messages:
+		if strings.Contains(msg, "\r\nBcc:") {
+			t.Errorf("the alert title injected a header:\n%s", msg)
+		}
+		if !strings.Contains(msg, "Subject: [HIGH] Rule fired Bcc: evil@example.com") {
+			t.Errorf("expected the line break of the subject to be replaced:\n%s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("email was not delivered")
+	}
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// PricingFile is the format of the file that the pricing catalog can be loaded from.
+// JSON files are accepted too since YAML is a superset of JSON.
+//
+//	models:
+//	  - model: claude-sonnet-4-20250514
+//	    input_per_mtok: 3
+//	    output_per_mtok: 15
+//	    cache_read_per_mtok: 0.3
+//	    cache_write_per_mtok: 3.75
+//	    effective_from: 2025-05-22
+type PricingFile struct {
+	Models []PricingFileEntry `yaml:"models" json:"models"`
+}
+
+// PricingFileEntry is a single price in a PricingFile.
+type PricingFileEntry struct {
+	Model             string  `yaml:"model" json:"model"`
+	InputPerMTok      float64 `yaml:"input_per_mtok" json:"input_per_mtok"`
+	OutputPerMTok     float64 `yaml:"output_per_mtok" json:"output_per_mtok"`
+	CacheReadPerMTok  float64 `yaml:"cache_read_per_mtok" json:"cache_read_per_mtok"`
+	CacheWritePerMTok float64 `yaml:"cache_write_per_mtok" json:"cache_write_per_mtok"`
+	// EffectiveFrom is a YYYY-MM-DD date or an RFC3339 timestamp.
+	// If empty, the price applies to all usage until the model's next price.
+	EffectiveFrom string `yaml:"effective_from" json:"effective_from"`
+}
+
+// CostRecomputation is the outcome of recomputing historical costs.
+type CostRecomputation struct {
+	// Updated is the number of usage records whose cost was recomputed from the catalog
+	Updated int `json:"updated"`
+	// Unpriced is the number of usage records left untouched because the catalog has no price for them
+	Unpriced int `json:"unpriced"`
+}
+
+// ParseEffectiveFrom parses the effective date of a price, which is either a YYYY-MM-DD date (UTC)
+// or an RFC3339 timestamp. An empty value means the price has always been effective.
+func ParseEffectiveFrom(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither a YYYY-MM-DD date nor an RFC3339 timestamp", value)
+	}
+	return t.UTC(), nil
+}
+
+func validateModelPrice(p *model.ModelPrice) error {
+	if p.Model == "" {
+		return errors.New("model is required")
+	}
+	if p.InputPerMTok < 0 || p.OutputPerMTok < 0 || p.CacheReadPerMTok < 0 || p.CacheWritePerMTok < 0 {
+		return errors.New("prices cannot be negative")
+	}
+	return nil
+}
+
+// SetModelPrice adds a price to the catalog.
+// If the model already has a price with the same effective date, that price is replaced.
+func (s *AnalyticsService) SetModelPrice(p *model.ModelPrice) error {
+	return setModelPrice(s.db, p)
+}
+
+func setModelPrice(db *gorm.DB, p *model.ModelPrice) error {
+	if err := validateModelPrice(p); err != nil {
+		return err
+	}
+
+	var existing model.ModelPrice
+	err := db.Where("model = ? AND effective_from = ?", p.Model, p.EffectiveFrom).First(&existing).Error
+	switch {
+	case err == nil:
+		p.ID = existing.ID
+		p.CreatedAt = existing.CreatedAt
+		if err := db.Save(p).Error; err != nil {
+			return fmt.Errorf("failed to update price of model %s: %w", p.Model, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := db.Create(p).Error; err != nil {
+			return fmt.Errorf("failed to create price of model %s: %w", p.Model, err)
+		}
+	default:
+		return fmt.Errorf("failed to look up price of model %s: %w", p.Model, err)
+	}
+	return nil
+}
+
+// ListModelPrices returns the pricing catalog, optionally only the prices of a single model.
+func (s *AnalyticsService) ListModelPrices(modelName string) ([]model.ModelPrice, error) {
+	q := s.db.Order("model").Order("effective_from")
+	if modelName != "" {
+		q = q.Where("model = ?", modelName)
+	}
+	var prices []model.ModelPrice
+	if err := q.Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// DeleteModelPrice removes the price of a model that became effective at the given time.
+// If effectiveFrom is nil, all prices of the model are removed.
+func (s *AnalyticsService) DeleteModelPrice(modelName string, effectiveFrom *time.Time) error {
+	q := s.db.Where("model = ?", modelName)
+	if effectiveFrom != nil {
+		q = q.Where("effective_from = ?", *effectiveFrom)
+	}
+	res := q.Delete(&model.ModelPrice{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete price of model %s: %w", modelName, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("no price found for model %s: %w", modelName, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// LoadPricingFile adds all the prices in a PricingFile to the catalog.
+// Prices that are already in the catalog with the same model and effective date are replaced.
+func (s *AnalyticsService) LoadPricingFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read pricing file: %w", err)
+	}
+	var f PricingFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse pricing file %s: %w", path, err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i, e := range f.Models {
+			effectiveFrom, err := ParseEffectiveFrom(e.EffectiveFrom)
+			if err != nil {
+				return fmt.Errorf("invalid effective_from of entry %d in pricing file: %w", i, err)
+			}
+			p := &model.ModelPrice{
+				Model:             e.Model,
+				InputPerMTok:      e.InputPerMTok,
+				OutputPerMTok:     e.OutputPerMTok,
+				CacheReadPerMTok:  e.CacheReadPerMTok,
+				CacheWritePerMTok: e.CacheWritePerMTok,
+				EffectiveFrom:     effectiveFrom,
+			}
+			if err := setModelPrice(tx, p); err != nil {
+				return fmt.Errorf("invalid entry %d in pricing file: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// priceAt returns the price of the model that was effective at the given time, or nil if there is none.
+func (s *AnalyticsService) priceAt(modelName string, at time.Time) (*model.ModelPrice, error) {
+	var p model.ModelPrice
+	err := s.db.Where("model = ? AND effective_from <= ?", modelName, at).
+		Order("effective_from DESC").
+		First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up price of model %s: %w", modelName, err)
+	}
+	return &p, nil
+}
+
+// applyCatalogCost fills in the cost of a usage record that was reported without one.
+func (s *AnalyticsService) applyCatalogCost(u *model.UsageMetric) error {
+	// a reported cost of 0 is a free call, not a missing cost
+	if u.Cost != nil {
+		u.CostSource = model.CostSourceReported
+		return nil
+	}
+	p, err := s.priceAt(u.Model, u.Timestamp)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		var unpriced float64
+		u.Cost = &unpriced
+		u.CostSource = model.CostSourceUnpriced
+		return nil
+	}
+	cost := p.Cost(u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheWriteTokens)
+	u.Cost = &cost
+	u.CostSource = model.CostSourceCatalog
+	return nil
+}
+
+// RecomputeCosts recomputes the cost of all usage in [from, to) from the pricing catalog,
+// optionally only the usage of a single model.
+// This overwrites reported costs too, so that costs are consistent regardless of which client reported them.
+// Usage for which the catalog has no price is left untouched.
+func (s *AnalyticsService) RecomputeCosts(from, to time.Time, modelName string) (*CostRecomputation, error) {
+	// load the relevant part of the catalog in memory instead of querying it for every usage record
+	prices, err := s.ListModelPrices(modelName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the pricing catalog: %w", err)
+	}
+	// prices of each model, sorted by effective date
+	byModel := make(map[string][]model.ModelPrice)
+	for _, p := range prices {
+		byModel[p.Model] = append(byModel[p.Model], p)
+	}
+
+	result := &CostRecomputation{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		q := tx.Where("timestamp >= ? AND timestamp < ?", from, to)
+		if modelName != "" {
+			q = q.Where("model = ?", modelName)
+		}
+		var batch []model.UsageMetric
+		return q.FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+			for _, u := range batch {
+				var price *model.ModelPrice
+				for i := range byModel[u.Model] {
+					if byModel[u.Model][i].EffectiveFrom.After(u.Timestamp) {
+						break
+					}
+					price = &byModel[u.Model][i]
+				}
+				if price == nil {
+					result.Unpriced++
+					continue
+				}
+
+				cost := price.Cost(u.InputTokens, u.OutputTokens, u.CacheReadTokens, u.CacheWriteTokens)
+				err := tx.Model(&model.UsageMetric{}).
+					Where("id = ?", u.ID).
+					Updates(map[string]any{"cost": cost, "cost_source": model.CostSourceCatalog}).Error
+				if err != nil {
+					return fmt.Errorf("failed to update cost of usage %s: %w", u.ID, err)
+				}
+				result.Updated++
+			}
+			return nil
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+const testPricingFile = `
+models:
+  - model: claude-sonnet-4
+    input_per_mtok: 3
+    output_per_mtok: 15
+    cache_read_per_mtok: 0.3
+    effective_from: 2025-01-01
+  - model: claude-sonnet-4
+    input_per_mtok: 2
+    output_per_mtok: 10
+    effective_from: 2025-06-01
+`
+
+func TestCatalogCostUsesEffectivePrice(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(path, []byte(testPricingFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadPricingFile(path); err != nil {
+		t.Fatalf("LoadPricingFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		usage      model.UsageMetric
+		wantCost   float64
+		wantSource string
+	}{
+		{
+			name: "old price",
+			usage: model.UsageMetric{
+				InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 1_000_000,
+				Timestamp: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantCost:   3 + 1.5 + 0.3,
+			wantSource: model.CostSourceCatalog,
+		},
+		{
+			name: "new price",
+			usage: model.UsageMetric{
+				InputTokens: 1_000_000, OutputTokens: 100_000,
+				Timestamp: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantCost:   2 + 1,
+			wantSource: model.CostSourceCatalog,
+		},
+		{
+			name: "reported cost is kept",
+			usage: model.UsageMetric{
+				InputTokens: 1_000_000, Cost: floatPtr(42),
+				Timestamp: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantCost:   42,
+			wantSource: model.CostSourceReported,
+		},
+		{
+			name: "reported cost of a free call is kept",
+			usage: model.UsageMetric{
+				InputTokens: 1_000_000, Cost: floatPtr(0),
+				Timestamp: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantCost:   0,
+			wantSource: model.CostSourceReported,
+		},
+		{
+			name: "before the first price",
+			usage: model.UsageMetric{
+				InputTokens: 1_000_000,
+				Timestamp:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantCost:   0,
+			wantSource: model.CostSourceUnpriced,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.usage
+			u.Model, u.ClientType = "claude-sonnet-4", "claude"
+			if err := s.RecordUsage(&u); err != nil {
+				t.Fatalf("RecordUsage() error = %v", err)
+			}
+			if u.Cost == nil || math.Abs(*u.Cost-tt.wantCost) > 1e-9 || u.CostSource != tt.wantSource {
+				t.Errorf("cost = %v (%s), want %g (%s)", u.Cost, u.CostSource, tt.wantCost, tt.wantSource)
+			}
+		})
+	}
+
+	// recomputing overwrites the reported cost so that all clients are costed consistently
+	result, err := s.RecomputeCosts(
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		"",
+	)
+	if err != nil {
+		t.Fatalf("RecomputeCosts() error = %v", err)
+	}
+	if result.Updated != 4 || result.Unpriced != 0 {
+		t.Errorf("RecomputeCosts() = %+v, want 4 updated and 0 unpriced", result)
+	}
+	var total float64
+	if err := s.db.Model(&model.UsageMetric{}).Select("SUM(cost)").Scan(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := 4.8 + 3 + 2 + 2; math.Abs(total-want) > 1e-9 {
+		t.Errorf("total cost after recomputation = %g, want %g", total, want)
+	}
+}
+
+func TestDeleteModelPriceNotFound(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	if err := s.DeleteModelPrice("missing", nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteModelPrice() error = %v, want a not found error", err)
+	}
+}
@@ -3,8 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"log/slog"
+	"time"
 )
 
 // initMCPProxyServer initializes the MCP proxy server.
@@ -27,7 +33,13 @@ func (m *MCPService) initMCPProxyServer() error {
 		}
 		tool.InputSchema = inputSchema
 
-		// TODO: Add other attributes to the tool, such as annotations
+		if len(tm.Annotations) > 0 {
+			if err := json.Unmarshal(tm.Annotations, &tool.Annotations); err != nil {
+				return fmt.Errorf(
+					"failed to unmarshal annotations %s for tool %s: %w", tm.Annotations, tm.Name, err,
+				)
+			}
+		}
 
 		m.mcpProxyServer.AddTool(tool, m.mcpProxyToolCallHandler)
 	}
@@ -44,8 +56,152 @@ func (m *MCPService) mcpProxyToolCallHandler(ctx context.Context, request mcp.Ca
 		return nil, fmt.Errorf("invalid input: tool name does not contain a %s separator", serverToolNameSep)
 	}
 
+	// Ensure the tool name is set correctly, ie, without the server name prefix
+	request.Params.Name = toolName
+
+	ctx = extractMetaTraceContext(ctx, &request)
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		ctx = logging.With(ctx, slog.String(logging.KeySession, session.SessionID()))
+	}
+	ctx, done := m.trackClientCancellation(ctx, &request)
+	defer done()
+
+	// forward the request to the upstream MCP server and relay the response back
+	result, err := m.callUpstreamTool(ctx, serverName, request)
+
+	// a rejected or timed out call is a tool error, so that the LLM driving the client learns why the tool is unavailable
+	var rejection toolResultError
+	if errors.As(err, &rejection) {
+		return rejection.ToolResult(), nil
+	}
+	return result, err
+}
+
+// toolResultError is implemented by errors that reject a tool call before it is forwarded upstream
+// or that the registry raises in place of the upstream's response, eg- timeouts.
+// The MCP proxy relays them to clients as tool error results instead of protocol errors.
+type toolResultError interface {
+	error
+	ToolResult() *mcp.CallToolResult
+}
+
+// callUpstreamTool calls a tool on the upstream MCP server that provides it.
+// The request must contain the tool's name as known to the upstream server, ie, without the server name prefix.
+// Calls whose arguments don't conform to the tool's input schema are rejected with an InvalidArgumentsError,
+// unless the call policy of the server or tool disables argument validation.
+// Calls that would exceed a quota are rejected with a QuotaExceededError before being forwarded.
+// Calls to a server whose circuit breaker is open fail fast with a ServerUnavailableError.
+// Calls are then subject to the rate and concurrency limits of the server and tool,
+// which either queue them or reject them with a CallThrottledError.
+// Forwarded calls are bounded by the connect and call timeouts of their call policy,
+// and failed calls are retried according to its retry policy.
+// Every forwarded call is recorded for analytics, regardless of its outcome,
+// and its full payloads are captured if the server has capture enabled.
+// Servers in record or replay mode have their calls recorded to or served from their cassette.
+// Once the registry starts shutting down, calls are rejected with a RegistryShuttingDownError.
+// Every call is traced, from its admission to the response of the upstream server.
+func (m *MCPService) callUpstreamTool(ctx context.Context, serverName string, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+	toolName := request.Params.Name
+	ctx = logging.With(ctx, slog.String(logging.KeyServer, serverName), slog.String(logging.KeyTool, toolName))
+	ctx, span := tracing.Start(ctx, "tools/call "+mergeServerToolNames(serverName, toolName), tracing.SpanKindInternal,
+		tracing.String("mcp.server", serverName),
+		tracing.String("mcp.tool", toolName),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	ctx, done, err := m.drainer.begin(ctx)
+	if err != nil {
+		observeRejectedCall(serverName, err)
+		return nil, err
+	}
+	defer done()
+
+	policy, err := m.resolveCallPolicy(serverName, toolName)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.validateArguments {
+		if err := m.validateToolArguments(ctx, serverName, request); err != nil {
+			observeRejectedCall(serverName, err)
+			return nil, err
+		}
+	}
+
+	if m.analyticsService != nil {
+		releaseQuotas, err := m.analyticsService.ReserveQuotas(ctx, serverName, toolName)
+		if err != nil {
+			observeRejectedCall(serverName, err)
+			return nil, err
+		}
+		// the call holds a unit of its call quotas until it has been recorded
+		defer releaseQuotas()
+	}
+
+	probe, transition, err := m.breakers.allow(serverName, policy.breaker, time.Now())
+	if transition != "" {
+		m.onBreakerTransition(serverName, transition)
+	}
+	if err != nil {
+		observeRejectedCall(serverName, err)
+		return nil, err
+	}
+
+	release, err := m.limiter.acquire(ctx, serverName, toolName)
+	if err != nil {
+		m.breakers.abandon(serverName, probe)
+		observeRejectedCall(serverName, err)
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	attempts := 1
+	player := m.cassettes.get(serverName)
+	if player.replaying() {
+		// the calls to a server in replay mode are served from its cassette, without contacting the server
+		result, err = player.replay(request)
+	} else {
+		result, attempts, err = m.forwardToolCallWithRetries(ctx, serverName, policy, request)
+	}
+	elapsed := time.Since(start)
+	m.recordToolCall(ctx, serverName, toolName, elapsed, attempts, result, err)
+
+	errorType := classifyToolCallError(ctx, result, err)
+	observeToolCall(serverName, toolName, elapsed, attempts, errorType)
+	m.captureToolCall(ctx, serverName, request, elapsed, attempts, result, err, errorType)
+	player.record(ctx, request, result, err, errorType)
+	slog.DebugContext(ctx, "tool call completed",
+		slog.Duration("duration", elapsed), slog.Int("attempts", attempts), slog.String("error_type", errorType))
+	span.SetAttributes(tracing.Int("mcp.tool_call.attempts", attempts))
+	if errorType != "" {
+		span.SetAttributes(tracing.String("error.type", errorType))
+		if err == nil {
+			// the upstream server reported a tool error
+			span.SetStatus(tracing.StatusError, errorType)
+		}
+	}
+
+	if transition := m.breakers.recordOutcome(serverName, policy.breaker, probe, errorType, time.Now()); transition != "" {
+		m.onBreakerTransition(serverName, transition)
+	}
+	return result, err
+}
+
+// forwardToolCall connects to the upstream MCP server and calls the tool on it.
+func (m *MCPService) forwardToolCall(
+	ctx context.Context,
+	serverName string,
+	policy *resolvedCallPolicy,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	toolName := request.Params.Name
+
 	// get the MCP server details from the database
-	server, err := m.GetMcpServer(serverName)
+	server, err := m.getMcpServer(ctx, serverName)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to get details about MCP server %s from DB: %w", serverName, err,
@@ -53,17 +209,64 @@ func (m *MCPService) mcpProxyToolCallHandler(ctx context.Context, request mcp.Ca
 	}
 
 	// connect to the upstream MCP server that actually provides the tool
-	mcpClient, err := createMcpServerConn(ctx, server)
+	mcpClient, release, err := m.connectToolCall(ctx, server, policy, toolName)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to create connection to MCP server %s: %w", serverName, err,
-		)
+		return nil, err
 	}
+	defer release()
 	defer mcpClient.Close()
 
-	// Ensure the tool name is set correctly, ie, without the server name prefix
-	request.Params.Name = toolName
+	callCtx, cancel := withCallTimeout(ctx, policy.callTimeout, serverName, toolName, timeoutPhaseCall)
+	defer cancel()
+	callCtx, span := tracing.Start(callCtx, "tools/call "+toolName, tracing.SpanKindClient,
+		tracing.String("mcp.server", serverName),
+		tracing.String("mcp.tool", toolName),
+	)
+	defer span.End()
+	injectMetaTraceContext(callCtx, &request)
 
-	// forward the request to the upstream MCP server and relay the response back
-	return mcpClient.CallTool(ctx, request)
+	result, err := mcpClient.CallTool(callCtx, request)
+	if err != nil {
+		if timeoutErr := callTimeoutError(ctx, callCtx); timeoutErr != nil {
+			span.RecordError(timeoutErr)
+			return nil, timeoutErr
+		}
+	}
+	span.RecordError(err)
+	if result != nil && result.IsError {
+		span.SetStatus(tracing.StatusError, "tool error")
+	}
+	return result, err
+}
+
+// connectionError wraps failures to connect to an upstream MCP server, so that they can be told apart from failed calls.
+type connectionError struct {
+	error
+}
+
+func (e connectionError) Unwrap() error {
+	return e.error
+}
+
+// withCallTimeout bounds a phase of a tool call, a timeout of 0 means no timeout.
+// When the timeout fires, the cause of the returned context is a ToolCallTimeoutError.
+func withCallTimeout(
+	ctx context.Context,
+	timeout time.Duration,
+	serverName, toolName, phase string,
+) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, newToolCallTimeoutError(serverName, toolName, phase, timeout))
+}
+
+// callTimeoutError returns the ToolCallTimeoutError if the phase of a call failed because its timeout fired,
+// as opposed to the caller cancelling the call.
+func callTimeoutError(ctx, phaseCtx context.Context) *ToolCallTimeoutError {
+	var timeoutErr *ToolCallTimeoutError
+	if ctx.Err() == nil && errors.As(context.Cause(phaseCtx), &timeoutErr) {
+		return timeoutErr
+	}
+	return nil
 }
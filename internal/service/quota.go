@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// QuotaExceededMetaKey is the key of the _meta entry that describes the exhausted quota
+// in the result of a tool call rejected by the MCP proxy.
+const QuotaExceededMetaKey = "mcpjungle/quota_exceeded"
+
+// QuotaStatus is a quota along with its consumption in the current period.
+type QuotaStatus struct {
+	model.Quota
+	Current  float64   `json:"current"`
+	ResetsAt time.Time `json:"resets_at"`
+	Exceeded bool      `json:"exceeded"`
+}
+
+// QuotaExceededError is returned when a tool call is rejected because a quota is exhausted.
+type QuotaExceededError struct {
+	Quota      string            `json:"quota"`
+	Metric     model.QuotaMetric `json:"metric"`
+	Period     model.QuotaPeriod `json:"period"`
+	Limit      float64           `json:"limit"`
+	Current    float64           `json:"current"`
+	ScopeType  model.QuotaScope  `json:"scope_type"`
+	ScopeValue string            `json:"scope_value,omitempty"`
+	ResetsAt   time.Time         `json:"resets_at"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	var consumed string
+	if e.Metric == model.QuotaMetricCost {
+		consumed = fmt.Sprintf("$%.2f of $%.2f per %s", e.Current, e.Limit, e.Period)
+	} else {
+		consumed = fmt.Sprintf("%g of %g calls per %s", e.Current, e.Limit, e.Period)
+	}
+	scope := string(e.ScopeType)
+	if e.ScopeValue != "" {
+		scope += " " + e.ScopeValue
+	}
+	return fmt.Sprintf(
+		"quota %s exceeded: %s used (scope: %s), resets at %s",
+		e.Quota, consumed, scope, e.ResetsAt.Format(time.RFC3339),
+	)
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+// The details of the quota are available to clients in the result's _meta.
+func (e *QuotaExceededError) ToolResult() *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Result:  mcp.Result{Meta: map[string]any{QuotaExceededMetaKey: e}},
+		Content: []mcp.Content{mcp.NewTextContent(e.Error())},
+		IsError: true,
+	}
+}
+
+func validateQuota(q *model.Quota) error {
+	if q.Name == "" {
+		return errors.New("quota name is required")
+	}
+	switch q.Metric {
+	case model.QuotaMetricCalls, model.QuotaMetricCost:
+	default:
+		return fmt.Errorf("invalid quota metric '%s', must be one of calls, cost", q.Metric)
+	}
+	switch q.Period {
+	case model.QuotaPeriodMinute, model.QuotaPeriodDay, model.QuotaPeriodMonth:
+	default:
+		return fmt.Errorf("invalid quota period '%s', must be one of minute, day, month", q.Period)
+	}
+	if q.Limit <= 0 {
+		return errors.New("quota limit must be positive")
+	}
+
+	if q.ScopeType == "" {
+		q.ScopeType = model.QuotaScopeGlobal
+	}
+	switch q.ScopeType {
+	case model.QuotaScopeGlobal:
+		if q.ScopeValue != "" {
+			return errors.New("global quotas cannot have a scope value")
+		}
+	case model.QuotaScopeAPIKey, model.QuotaScopeClient, model.QuotaScopeServer:
+		if q.ScopeValue == "" {
+			return fmt.Errorf("%s quotas require a scope value", q.ScopeType)
+		}
+	case model.QuotaScopeTool:
+		if _, _, ok := splitServerToolName(q.ScopeValue); !ok {
+			return fmt.Errorf("tool quotas require a canonical tool name (<server>%s<tool>) as scope value", serverToolNameSep)
+		}
+	default:
+		return fmt.Errorf("invalid quota scope '%s', must be one of global, api_key, client, server, tool", q.ScopeType)
+	}
+	return nil
+}
+
+// CreateQuota validates and stores a new quota.
+// The scope value of API key quotas is the API key itself, only its hash is stored.
+func (s *AnalyticsService) CreateQuota(q *model.Quota) error {
+	if err := validateQuota(q); err != nil {
+		return err
+	}
+	if q.ScopeType == model.QuotaScopeAPIKey {
+		q.ScopeValue = HashAPIKey(q.ScopeValue)
+	}
+	if err := s.db.Create(q).Error; err != nil {
+		return fmt.Errorf("failed to create quota: %w", err)
+	}
+	return nil
+}
+
+// DeleteQuota deletes a quota by name.
+func (s *AnalyticsService) DeleteQuota(name string) error {
+	res := s.db.Where("name = ?", name).Delete(&model.Quota{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete quota %s: %w", name, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("quota %s: %w", name, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// ListQuotas returns all quotas along with their consumption in the current period.
+func (s *AnalyticsService) ListQuotas() ([]QuotaStatus, error) {
+	var quotas []model.Quota
+	if err := s.db.Order("name").Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	statuses := make([]QuotaStatus, 0, len(quotas))
+	for _, q := range quotas {
+		start, resetsAt := quotaPeriodWindow(q.Period, now)
+		current, err := s.quotaConsumption(&q, start)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, QuotaStatus{
+			Quota:    q,
+			Current:  current,
+			ResetsAt: resetsAt,
+			Exceeded: q.Enabled && current >= q.Limit,
+		})
+	}
+	return statuses, nil
+}
+
+// CheckQuotas returns a QuotaExceededError if any enabled quota that applies to a call
+// of the given tool by the caller in ctx is exhausted.
+func (s *AnalyticsService) CheckQuotas(ctx context.Context, serverName, toolName string) error {
+	release, err := s.ReserveQuotas(ctx, serverName, toolName)
+	if err != nil {
+		return err
+	}
+	release()
+	return nil
+}
+
+// ReserveQuotas admits a call of the given tool by the caller in ctx under the enabled quotas that apply to it,
+// or returns a QuotaExceededError if any of them is exhausted.
+// An admitted call holds a unit of its call quotas until the returned release function is called,
+// which must be done once the call has been recorded, so that concurrent calls can't exceed the quotas.
+// The cost of a call is only known once its usage is reported, so cost quotas don't reserve anything.
+func (s *AnalyticsService) ReserveQuotas(ctx context.Context, serverName, toolName string) (func(), error) {
+	quotas, err := s.applicableQuotas(ctx, serverName, toolName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the calls reserved before this one are counted before the recorded calls are, so that a call
+	// that is recorded and released in between is counted twice rather than not at all
+	reservation, ahead := s.reservations.reserve(quotas)
+	now := time.Now().UTC()
+	for _, q := range quotas {
+		start, resetsAt := quotaPeriodWindow(q.Period, now)
+		current, err := s.quotaConsumption(&q, start)
+		if err != nil {
+			s.reservations.release(reservation)
+			return nil, err
+		}
+		current += float64(ahead[q.ID])
+		if current >= q.Limit {
+			s.reservations.release(reservation)
+			return nil, &QuotaExceededError{
+				Quota:      q.Name,
+				Metric:     q.Metric,
+				Period:     q.Period,
+				Limit:      q.Limit,
+				Current:    current,
+				ScopeType:  q.ScopeType,
+				ScopeValue: q.ScopeValue,
+				ResetsAt:   resetsAt,
+			}
+		}
+	}
+	var once sync.Once
+	return func() { once.Do(func() { s.reservations.release(reservation) }) }, nil
+}
+
+// applicableQuotas returns the enabled quotas that apply to a call of the given tool by the caller in ctx.
+func (s *AnalyticsService) applicableQuotas(ctx context.Context, serverName, toolName string) ([]model.Quota, error) {
+	caller := CallerFromContext(ctx)
+
+	scopes := s.db.Where("scope_type = ?", model.QuotaScopeGlobal).
+		Or("scope_type = ? AND scope_value = ?", model.QuotaScopeClient, toolCallClientType(ctx)).
+		Or("scope_type = ? AND scope_value = ?", model.QuotaScopeServer, serverName).
+		Or("scope_type = ? AND scope_value = ?", model.QuotaScopeTool, mergeServerToolNames(serverName, toolName))
+	if caller.APIKeyHash != "" {
+		scopes = scopes.Or("scope_type = ? AND scope_value = ?", model.QuotaScopeAPIKey, caller.APIKeyHash)
+	}
+	var quotas []model.Quota
+	if err := s.db.Where("enabled = ?", true).Where(scopes).Find(&quotas).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up quotas: %w", err)
+	}
+	return quotas, nil
+}
+
+// quotaReservation is the set of call quotas that an admitted call holds a unit of.
+type quotaReservation struct {
+	seq    uint64
+	quotas []uuid.UUID
+}
+
+// quotaReservations tracks the calls admitted under call quotas that haven't been recorded yet.
+// Reservations are ordered, a call is admitted if the recorded calls and the calls reserved before it
+// leave a unit of each of its quotas, so that of N concurrent calls competing for the last unit only the first is admitted.
+type quotaReservations struct {
+	mu   sync.Mutex
+	next uint64
+	// inFlight maps the ID of a call quota to the sequence numbers of the reservations holding a unit of it
+	inFlight map[uuid.UUID]map[uint64]struct{}
+}
+
+func newQuotaReservations() *quotaReservations {
+	return &quotaReservations{inFlight: make(map[uuid.UUID]map[uint64]struct{})}
+}
+
+// reserve takes a unit of each of the call quotas and returns, for every one of them,
+// the number of units held by the reservations made before this one.
+func (r *quotaReservations) reserve(quotas []model.Quota) (*quotaReservation, map[uuid.UUID]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next++
+	reservation := &quotaReservation{seq: r.next}
+	ahead := make(map[uuid.UUID]int)
+	for _, q := range quotas {
+		if q.Metric != model.QuotaMetricCalls {
+			continue
+		}
+		held := r.inFlight[q.ID]
+		if held == nil {
+			held = make(map[uint64]struct{})
+			r.inFlight[q.ID] = held
+		}
+		ahead[q.ID] = len(held)
+		held[reservation.seq] = struct{}{}
+		reservation.quotas = append(reservation.quotas, q.ID)
+	}
+	return reservation, ahead
+}
+
+// release returns the units held by the reservation.
+func (r *quotaReservations) release(reservation *quotaReservation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range reservation.quotas {
+		delete(r.inFlight[id], reservation.seq)
+		if len(r.inFlight[id]) == 0 {
+			delete(r.inFlight, id)
+		}
+	}
+}
+
+// quotaPeriodWindow returns the start of the current period and the time at which it resets.
+func quotaPeriodWindow(p model.QuotaPeriod, now time.Time) (time.Time, time.Time) {
+	switch p {
+	case model.QuotaPeriodMinute:
+		start := now.Truncate(time.Minute)
+		return start, start.Add(time.Minute)
+	case model.QuotaPeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// quotaConsumption returns how much of the quota has been consumed since start.
+func (s *AnalyticsService) quotaConsumption(q *model.Quota, start time.Time) (float64, error) {
+	if q.Metric == model.QuotaMetricCalls {
+		var count int64
+		err := toolCallScope(s.db.Model(&model.ToolCall{}), q).
+			Where("timestamp >= ?", start).
+			Count(&count).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to count calls of quota %s: %w", q.Name, err)
+		}
+		return float64(count), nil
+	}
+
+	usage := s.db.Model(&model.UsageMetric{}).Where("timestamp >= ?", start)
+	switch q.ScopeType {
+	case model.QuotaScopeAPIKey:
+		usage = usage.Where("api_key_hash = ?", q.ScopeValue)
+	case model.QuotaScopeClient:
+		usage = usage.Where("client_type = ?", q.ScopeValue)
+	case model.QuotaScopeServer, model.QuotaScopeTool:
+		// LLM usage is attributed to servers and tools through the tool calls linked to it
+		linked := toolCallScope(s.db.Model(&model.ToolCall{}), q).
+			Select("usage_metric_id").
+			Where("usage_metric_id IS NOT NULL")
+		usage = usage.Where("id IN (?)", linked)
+	}
+	var cost float64
+	if err := usage.Select("COALESCE(SUM(cost), 0)").Scan(&cost).Error; err != nil {
+		return 0, fmt.Errorf("failed to compute cost of quota %s: %w", q.Name, err)
+	}
+	return cost, nil
+}
+
+// toolCallScope restricts a query on tool calls to the quota's scope.
+func toolCallScope(q *gorm.DB, quota *model.Quota) *gorm.DB {
+	switch quota.ScopeType {
+	case model.QuotaScopeAPIKey:
+		return q.Where("api_key_hash = ?", quota.ScopeValue)
+	case model.QuotaScopeClient:
+		return q.Where("client_type = ?", quota.ScopeValue)
+	case model.QuotaScopeServer:
+		return q.Where("server_name = ?", quota.ScopeValue)
+	case model.QuotaScopeTool:
+		serverName, toolName, _ := strings.Cut(quota.ScopeValue, serverToolNameSep)
+		return q.Where("server_name = ? AND tool_name = ?", serverName, toolName)
+	}
+	return q
+}
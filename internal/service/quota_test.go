@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestCheckQuotas(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+
+	quotas := []*model.Quota{
+		{Name: "github-calls", Metric: model.QuotaMetricCalls, Period: model.QuotaPeriodDay, Limit: 2,
+			ScopeType: model.QuotaScopeServer, ScopeValue: "github", Enabled: true},
+		{Name: "ci-key-budget", Metric: model.QuotaMetricCost, Period: model.QuotaPeriodMonth, Limit: 5,
+			ScopeType: model.QuotaScopeAPIKey, ScopeValue: "ci-key", Enabled: true},
+		{Name: "disabled", Metric: model.QuotaMetricCalls, Period: model.QuotaPeriodMinute, Limit: 1, Enabled: false},
+	}
+	for _, q := range quotas {
+		if err := s.CreateQuota(q); err != nil {
+			t.Fatalf("CreateQuota(%s) error = %v", q.Name, err)
+		}
+	}
+
+	recordCall := func(serverName string) {
+		t.Helper()
+		tc := model.ToolCall{ToolName: "t", ServerName: serverName, Model: "m", ClientType: "api", Success: true, Timestamp: time.Now()}
+		if err := s.RecordToolCall(tc); err != nil {
+			t.Fatalf("RecordToolCall() error = %v", err)
+		}
+	}
+	ctx := context.Background()
+	ciCtx := ContextWithCaller(ctx, Caller{APIKeyHash: HashAPIKey("ci-key")})
+
+	recordCall("github")
+	if err := s.CheckQuotas(ctx, "github", "create_issue"); err != nil {
+		t.Fatalf("CheckQuotas() error = %v before the quota is exhausted", err)
+	}
+	recordCall("github")
+
+	err := s.CheckQuotas(ctx, "github", "create_issue")
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("CheckQuotas() error = %v, want a QuotaExceededError", err)
+	}
+	if quotaErr.Quota != "github-calls" || quotaErr.Current != 2 || !quotaErr.ResetsAt.After(time.Now()) {
+		t.Errorf("unexpected quota error: %+v", quotaErr)
+	}
+	if err := s.CheckQuotas(ctx, "slack", "post"); err != nil {
+		t.Errorf("CheckQuotas() error = %v for a server out of the quota's scope", err)
+	}
+
+	// cost quotas count the usage reported with the API key
+	err = s.RecordUsage(&model.UsageMetric{Model: "m", ClientType: "c", Cost: floatPtr(6), APIKeyHash: HashAPIKey("ci-key")})
+	if err != nil {
+		t.Fatalf("RecordUsage() error = %v", err)
+	}
+	if err := s.CheckQuotas(ctx, "slack", "post"); err != nil {
+		t.Errorf("CheckQuotas() error = %v for a caller without the API key", err)
+	}
+	if err := s.CheckQuotas(ciCtx, "slack", "post"); !errors.As(err, &quotaErr) || quotaErr.Quota != "ci-key-budget" {
+		t.Errorf("CheckQuotas() error = %v, want the API key's budget to be exceeded", err)
+	}
+
+	statuses, err := s.ListQuotas()
+	if err != nil {
+		t.Fatalf("ListQuotas() error = %v", err)
+	}
+	for _, st := range statuses {
+		if want := st.Name != "disabled"; st.Exceeded != want {
+			t.Errorf("quota %s: exceeded = %v, want %v (current %g)", st.Name, st.Exceeded, want, st.Current)
+		}
+	}
+}
+
+func TestDeleteQuotaNotFound(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	if err := s.DeleteQuota("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteQuota() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func TestReserveQuotasConcurrently(t *testing.T) {
+	s := NewAnalyticsService(newTestDB(t), nil)
+	q := &model.Quota{Name: "github-calls", Metric: model.QuotaMetricCalls, Period: model.QuotaPeriodDay, Limit: 3,
+		ScopeType: model.QuotaScopeServer, ScopeValue: "github", Enabled: true}
+	if err := s.CreateQuota(q); err != nil {
+		t.Fatalf("CreateQuota() error = %v", err)
+	}
+	tc := model.ToolCall{ToolName: "t", ServerName: "github", Model: "m", ClientType: "api", Success: true, Timestamp: time.Now()}
+	if err := s.RecordToolCall(tc); err != nil {
+		t.Fatalf("RecordToolCall() error = %v", err)
+	}
+
+	// 2 units are left, only 2 of the concurrent calls are admitted while they are in flight
+	ctx := context.Background()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		releases []func()
+		rejected int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := s.ReserveQuotas(ctx, "github", "create_issue")
+			mu.Lock()
+			defer mu.Unlock()
+			var quotaErr *QuotaExceededError
+			switch {
+			case err == nil:
+				releases = append(releases, release)
+			case errors.As(err, &quotaErr):
+				rejected++
+			default:
+				t.Errorf("ReserveQuotas() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(releases) != 2 || rejected != 8 {
+		t.Fatalf("admitted %d and rejected %d calls, want 2 and 8", len(releases), rejected)
+	}
+
+	// the admitted calls are recorded before they release their units, the quota stays exhausted
+	for _, release := range releases {
+		if err := s.RecordToolCall(tc); err != nil {
+			t.Fatalf("RecordToolCall() error = %v", err)
+		}
+		release()
+	}
+	if err := s.CheckQuotas(ctx, "github", "create_issue"); err == nil {
+		t.Errorf("CheckQuotas() admitted a call beyond the quota")
+	}
+	if len(s.reservations.inFlight) != 0 {
+		t.Errorf("expected every reservation to be released, got %v", s.reservations.inFlight)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/migrations"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// readinessCheckTimeout bounds every check made by a readiness probe,
+// so that a hung database doesn't hang the probe past the prober's own timeout.
+const readinessCheckTimeout = 2 * time.Second
+
+// Components of the registry checked by the readiness probe
+const (
+	ComponentDatabase   = "database"
+	ComponentMigrations = "migrations"
+	ComponentMCPProxy   = "mcp_proxy"
+	ComponentShutdown   = "shutdown"
+)
+
+// ComponentStatus is the outcome of checking one component of the registry.
+type ComponentStatus struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ProbeReport is the outcome of a liveness or readiness probe.
+type ProbeReport struct {
+	// Status is ok if all components are, unavailable otherwise
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// OK tells whether all the components checked by the probe are ok.
+func (r *ProbeReport) OK() bool {
+	return r.Status == "ok"
+}
+
+func newProbeReport(components map[string]ComponentStatus) *ProbeReport {
+	r := &ProbeReport{Status: "ok", Components: components}
+	for _, c := range components {
+		if !c.OK {
+			r.Status = "unavailable"
+		}
+	}
+	return r
+}
+
+func componentStatus(detail string, err error) ComponentStatus {
+	if err != nil {
+		return ComponentStatus{Error: err.Error()}
+	}
+	return ComponentStatus{OK: true, Detail: detail}
+}
+
+// MarkShuttingDown makes the registry report that it isn't ready, so that load balancers
+// stop routing new traffic to it while it shuts down.
+func (s *HealthService) MarkShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+// ShuttingDown tells whether the registry has started shutting down.
+func (s *HealthService) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+// CheckLiveness reports whether the registry process is alive.
+// It deliberately doesn't check dependencies like the database, since restarting
+// the registry wouldn't fix them.
+func (s *HealthService) CheckLiveness() *ProbeReport {
+	uptime := time.Since(s.startedAt).Round(time.Second)
+	return newProbeReport(map[string]ComponentStatus{
+		"process": {OK: true, Detail: "up for " + uptime.String()},
+	})
+}
+
+// CheckReadiness reports whether the registry can serve traffic: its database is reachable and migrated
+// to the schema version this build expects, its MCP proxy server serves tools, and it isn't shutting down.
+func (s *HealthService) CheckReadiness(ctx context.Context, mcpService *MCPService) *ProbeReport {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	components := map[string]ComponentStatus{
+		ComponentDatabase:   componentStatus("reachable", s.pingDatabase(ctx)),
+		ComponentMigrations: s.checkSchemaVersion(ctx),
+		ComponentMCPProxy:   mcpService.checkProxy(ctx),
+		ComponentShutdown:   {OK: true},
+	}
+	if s.ShuttingDown() {
+		components[ComponentShutdown] = ComponentStatus{Error: "the registry is shutting down"}
+	}
+	return newProbeReport(components)
+}
+
+func (s *HealthService) pingDatabase(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get the database connection: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping the database: %w", err)
+	}
+	return nil
+}
+
+func (s *HealthService) checkSchemaVersion(ctx context.Context) ComponentStatus {
+	version, err := migrations.AppliedSchemaVersion(s.db.WithContext(ctx))
+	if err != nil {
+		return componentStatus("", fmt.Errorf("failed to get the applied schema version: %w", err))
+	}
+	if version < migrations.SchemaVersion {
+		return componentStatus("", fmt.Errorf(
+			"the database schema is at version %d but this build expects version %d", version, migrations.SchemaVersion,
+		))
+	}
+	return componentStatus(fmt.Sprintf("schema version %d", version), nil)
+}
+
+// checkProxy lists the tools served by the MCP proxy server, as an MCP client would.
+func (m *MCPService) checkProxy(ctx context.Context) ComponentStatus {
+	msg := m.mcpProxyServer.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":0,"method":"tools/list"}`))
+	switch resp := msg.(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := resp.Result.(mcp.ListToolsResult)
+		if !ok {
+			return componentStatus("", fmt.Errorf("unexpected response to tools/list: %T", resp.Result))
+		}
+		return componentStatus(fmt.Sprintf("serving %d tools", len(result.Tools)), nil)
+	case mcp.JSONRPCError:
+		return componentStatus("", fmt.Errorf("failed to list tools: %s", resp.Error.Message))
+	default:
+		return componentStatus("", fmt.Errorf("unexpected response to tools/list: %T", msg))
+	}
+}
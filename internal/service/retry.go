@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/datatypes"
+)
+
+// retryableErrorTypes are the classes of errors that retry policies may retry.
+// Tool error results are never retried since the tool did run, and neither are calls cancelled by the caller.
+var retryableErrorTypes = map[string]bool{
+	model.ToolCallErrorConnection: true,
+	model.ToolCallErrorUpstream:   true,
+	model.ToolCallErrorTimeout:    true,
+}
+
+type retryConfig struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	retryOn     map[string]bool
+	// nonIdempotent allows retrying tools that don't declare themselves safe to retry
+	nonIdempotent bool
+}
+
+func parseRetryOn(value string) map[string]bool {
+	retryOn := make(map[string]bool)
+	for _, class := range strings.Split(value, ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			retryOn[class] = true
+		}
+	}
+	return retryOn
+}
+
+// backoffBefore returns how long to wait before the given retry (1 for the first retry).
+// The backoff doubles with every retry up to the maximum, and is jittered so that
+// the retries of concurrent calls don't hit a recovering server at the same time.
+func (c retryConfig) backoffBefore(retry int) time.Duration {
+	d := c.backoff
+	for i := 1; i < retry && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	if c.maxBackoff > 0 && d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// equal jitter: wait at least half of the backoff
+	return d/2 + rand.N(d/2+1)
+}
+
+// isRetrySafe tells whether a tool declares that calling it more than once has no additional effect.
+func isRetrySafe(annotations datatypes.JSON) bool {
+	if len(annotations) == 0 {
+		return false
+	}
+	var a mcp.ToolAnnotation
+	if err := json.Unmarshal(annotations, &a); err != nil {
+		return false
+	}
+	return (a.ReadOnlyHint != nil && *a.ReadOnlyHint) || (a.IdempotentHint != nil && *a.IdempotentHint)
+}
+
+// maxAttempts returns how many times a call to the tool may be forwarded upstream.
+func (m *MCPService) maxAttempts(serverName, toolName string, cfg retryConfig) int {
+	if cfg.maxAttempts <= 1 || cfg.nonIdempotent {
+		return max(cfg.maxAttempts, 1)
+	}
+	tool, err := m.GetTool(mergeServerToolNames(serverName, toolName))
+	if err != nil || !isRetrySafe(tool.Annotations) {
+		return 1
+	}
+	return cfg.maxAttempts
+}
+
+// forwardToolCallWithRetries forwards a tool call upstream, retrying it according to the retry policy.
+// It returns the outcome of the last attempt along with the number of attempts made.
+func (m *MCPService) forwardToolCallWithRetries(
+	ctx context.Context,
+	serverName string,
+	policy *resolvedCallPolicy,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, int, error) {
+	maxAttempts := m.maxAttempts(serverName, request.Params.Name, policy.retry)
+	for attempt := 1; ; attempt++ {
+		result, err := m.forwardToolCall(ctx, serverName, policy, request)
+		errorType := classifyToolCallError(ctx, result, err)
+		if attempt >= maxAttempts || !policy.retry.retryOn[errorType] {
+			return result, attempt, err
+		}
+
+		backoff := policy.retry.backoffBefore(attempt)
+		slog.WarnContext(ctx, "tool call attempt failed, retrying",
+			slog.String(logging.KeyServer, serverName),
+			slog.String(logging.KeyTool, request.Params.Name),
+			slog.Int("attempt", attempt),
+			slog.String("error_type", errorType),
+			slog.Duration("backoff", backoff),
+			logging.Err(err),
+		)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, attempt, err
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	cfg := retryConfig{backoff: 100 * time.Millisecond, maxBackoff: 300 * time.Millisecond}
+	bounds := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 150 * time.Millisecond, 300 * time.Millisecond},
+		{10, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+	for _, b := range bounds {
+		for i := 0; i < 20; i++ {
+			if d := cfg.backoffBefore(b.retry); d < b.min || d > b.max {
+				t.Fatalf("backoff before retry %d is %s, expected within [%s, %s]", b.retry, d, b.min, b.max)
+			}
+		}
+	}
+}
+
+func TestIsRetrySafe(t *testing.T) {
+	cases := map[string]bool{
+		``: false,
+		`{"readOnlyHint":true,"destructiveHint":false}`: true,
+		`{"idempotentHint":true}`:                       true,
+		`{"readOnlyHint":false,"idempotentHint":false}`: false,
+		`{"destructiveHint":true,"openWorldHint":true}`: false,
+		`not json`: false,
+	}
+	for annotations, want := range cases {
+		if got := isRetrySafe([]byte(annotations)); got != want {
+			t.Errorf("isRetrySafe(%s) = %v, want %v", annotations, got, want)
+		}
+	}
+}
+
+func TestForwardToolCallWithRetries(t *testing.T) {
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	handler := func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	upstream.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), handler)
+	upstream.AddTool(mcp.NewTool("write"), handler)
+
+	// the upstream server fails every other connection attempt,
+	// initialize being the only request made without a session ID
+	var connections atomic.Int64
+	streamable := server.NewStreamableHTTPServer(upstream)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Mcp-Session-Id") == "" && connections.Add(1)%2 == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		streamable.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	m := newTestMCPService(t)
+	db := m.db
+	s := &model.McpServer{Name: "upstream", URL: ts.URL}
+	if err := db.Create(s).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	for _, tool := range []mcp.Tool{mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), mcp.NewTool("write")} {
+		annotations, _ := json.Marshal(tool.Annotations)
+		if err := db.Create(&model.Tool{ServerID: s.ID, Name: tool.Name, Annotations: annotations}).Error; err != nil {
+			t.Fatalf("failed to create tool: %v", err)
+		}
+	}
+	policy, err := m.resolveCallPolicy("upstream", "read")
+	if err != nil {
+		t.Fatalf("failed to resolve policy: %v", err)
+	}
+	policy.retry.backoff = time.Millisecond
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "read"
+	result, attempts, err := m.forwardToolCallWithRetries(context.Background(), "upstream", policy, req)
+	if err != nil || result.IsError || attempts != 2 {
+		t.Fatalf("expected the read-only tool to succeed on its second attempt, got attempts %d, err %v", attempts, err)
+	}
+
+	req.Params.Name = "write"
+	_, attempts, err = m.forwardToolCallWithRetries(context.Background(), "upstream", policy, req)
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected the non-idempotent tool not to be retried, got attempts %d, err %v", attempts, err)
+	}
+
+	policy.retry.nonIdempotent = true
+	connections.Store(0)
+	_, attempts, err = m.forwardToolCallWithRetries(context.Background(), "upstream", policy, req)
+	if err != nil || attempts != 2 {
+		t.Fatalf("expected the non-idempotent tool to be retried when allowed, got attempts %d, err %v", attempts, err)
+	}
+}
@@ -3,18 +3,36 @@ package service
 import (
 	"context"
 	"fmt"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
 	"github.com/duaraghav8/mcpjungle/internal/model"
 	"gorm.io/gorm"
+	"log/slog"
 )
 
 // RegisterMcpServer registers a new MCP server in the database.
 // It also registers all the Tools provided by the server.
 // Tool registration is on best-effort basis and does not fail the server registration.
 // Registered tools are also added to the MCP proxy server.
-func (m *MCPService) RegisterMcpServer(ctx context.Context, s *model.McpServer) error {
+func (m *MCPService) RegisterMcpServer(ctx context.Context, s *model.McpServer) (err error) {
 	if err := validateServerName(s.Name); err != nil {
 		return err
 	}
+	if err := validateServerEndpoints(s); err != nil {
+		return err
+	}
+	if s.MockConfig != "" {
+		if _, ok := mockServers.get(mockServerURL(s.Name)); ok {
+			return fmt.Errorf("failed to register mcp server: mock server %s is already registered", s.Name)
+		}
+		if err := startMockServer(s); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				mockServers.remove(s.URL)
+			}
+		}()
+	}
 
 	// TODO: validate the URL to ensure it is a valid HTTP/HTTPS URL (streamable http compliant)
 
@@ -40,6 +58,9 @@ func (m *MCPService) RegisterMcpServer(ctx context.Context, s *model.McpServer)
 // It also deregisters all the tools registered by the server.
 // If even a singe tool fails to deregister, the server deregistration fails.
 // A deregistered tool is also removed from the MCP proxy server.
+// The canary checks and the capture config of the server are deleted along with it,
+// and the server leaves record or replay mode. Its captured calls are kept.
+// Its circuit breaker is dropped and any open circuit breaker alert is resolved.
 func (m *MCPService) DeregisterMcpServer(name string) error {
 	s, err := m.GetMcpServer(name)
 	if err != nil {
@@ -52,16 +73,35 @@ func (m *MCPService) DeregisterMcpServer(name string) error {
 			err,
 		)
 	}
+	if err := m.db.Where("server_id = ?", s.ID).Delete(&model.ServerEndpoint{}).Error; err != nil {
+		return fmt.Errorf("failed to delete endpoints of server %s: %w", name, err)
+	}
+	if err := m.deleteServerCanaries(name); err != nil {
+		return err
+	}
+	if err := m.deleteServerCassette(name); err != nil {
+		return err
+	}
+	if err := m.db.Where("server_name = ?", name).Delete(&model.CallCaptureConfig{}).Error; err != nil {
+		return fmt.Errorf("failed to delete capture config of server %s: %w", name, err)
+	}
+	if err := m.forgetServerBreaker(name); err != nil {
+		return err
+	}
 	if err := m.db.Delete(s).Error; err != nil {
 		return fmt.Errorf("failed to deregister server %s: %w", name, err)
 	}
+	m.balancer.forget(name)
+	if s.MockConfig != "" {
+		mockServers.remove(s.URL)
+	}
 	return nil
 }
 
 // ListMcpServers returns all registered MCP servers.
 func (m *MCPService) ListMcpServers() ([]model.McpServer, error) {
 	var servers []model.McpServer
-	if err := m.db.Find(&servers).Error; err != nil {
+	if err := m.db.Preload("Endpoints").Find(&servers).Error; err != nil {
 		return nil, err
 	}
 	return servers, nil
@@ -69,13 +109,46 @@ func (m *MCPService) ListMcpServers() ([]model.McpServer, error) {
 
 // GetMcpServer fetches a server from the database by name.
 func (m *MCPService) GetMcpServer(name string) (*model.McpServer, error) {
+	return m.getMcpServer(context.Background(), name)
+}
+
+// getMcpServer fetches a server from the database by name, as part of the operation of ctx.
+func (m *MCPService) getMcpServer(ctx context.Context, name string) (*model.McpServer, error) {
 	var serverModel model.McpServer
-	if err := m.db.Where("name = ?", name).First(&serverModel).Error; err != nil {
+	if err := m.db.WithContext(ctx).Preload("Endpoints").Where("name = ?", name).First(&serverModel).Error; err != nil {
 		return nil, err
 	}
 	return &serverModel, nil
 }
 
+// McpServerStatus is a registered MCP server along with the runtime state of the registry's calls to it.
+type McpServerStatus struct {
+	model.McpServer
+	CircuitBreaker CircuitBreakerStatus `json:"circuit_breaker"`
+
+	// EndpointStatuses is only reported for servers that list their endpoints.
+	EndpointStatuses []EndpointStatus `json:"endpoint_statuses,omitempty"`
+
+	// Health summarizes the periodic health checks of the server.
+	Health *ServerHealth `json:"health,omitempty"`
+}
+
+// ListMcpServerStatuses returns all registered MCP servers along with their runtime state.
+func (m *MCPService) ListMcpServerStatuses() ([]McpServerStatus, error) {
+	servers, err := m.ListMcpServers()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]McpServerStatus, len(servers))
+	for i, s := range servers {
+		statuses[i] = McpServerStatus{McpServer: s, CircuitBreaker: m.breakers.status(s.Name)}
+		if len(s.Endpoints) > 0 {
+			statuses[i].EndpointStatuses = m.balancer.status(&s)
+		}
+	}
+	return statuses, nil
+}
+
 // InitializeExampleServers creates example MCP servers for demo purposes
 func (m *MCPService) InitializeExampleServers() error {
 	exampleServers := []model.McpServer{
@@ -122,7 +195,8 @@ func (m *MCPService) InitializeExampleServers() error {
 				err = m.db.Create(&tool).Error
 				if err != nil {
 					// Log error but continue
-					fmt.Printf("Failed to create tool %s for server %s: %v\n", tool.Name, server.Name, err)
+					slog.Error("failed to create example tool",
+						slog.String(logging.KeyServer, server.Name), slog.String(logging.KeyTool, tool.Name), logging.Err(err))
 				}
 			}
 		}
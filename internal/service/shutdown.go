@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// abortedCallsGracePeriod bounds how long draining waits for the tool calls it cancelled
+// at its deadline to return, so that they still get recorded.
+const abortedCallsGracePeriod = 5 * time.Second
+
+// errShutdownDeadline is the cause of the cancellation of the tool calls still in progress at the drain deadline.
+var errShutdownDeadline = errors.New("the registry shut down before the call completed")
+
+// RegistryShuttingDownError is returned when a tool call is rejected because the registry is shutting down.
+type RegistryShuttingDownError struct{}
+
+func (e *RegistryShuttingDownError) Error() string {
+	return "the MCPJungle registry is shutting down, retry the call"
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+func (e *RegistryShuttingDownError) ToolResult() *mcp.CallToolResult {
+	return mcp.NewToolResultError(e.Error())
+}
+
+// callDrainer tracks the tool calls in progress so that the registry can wait for them before shutting down.
+// Its zero value is ready to use.
+type callDrainer struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	// idle is closed once draining has started and no call is in progress
+	idle chan struct{}
+
+	// abortCtx is cancelled when the drain deadline passes, which cancels the calls still in progress
+	abortCtx context.Context
+	abort    context.CancelCauseFunc
+}
+
+// init lazily sets up the abort context, the lock must be held.
+func (d *callDrainer) init() {
+	if d.abortCtx == nil {
+		d.abortCtx, d.abort = context.WithCancelCause(context.Background())
+	}
+}
+
+// begin tracks a new tool call, or rejects it with a RegistryShuttingDownError if draining has started.
+// It returns the context to make the call with and a function that must be called once the call completes.
+func (d *callDrainer) begin(ctx context.Context) (context.Context, func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return ctx, nil, &RegistryShuttingDownError{}
+	}
+	d.init()
+	d.active++
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(d.abortCtx, func() { cancel(errShutdownDeadline) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.active--
+		if d.draining && d.active == 0 {
+			close(d.idle)
+		}
+	}, nil
+}
+
+// drain rejects new tool calls and waits for the ones in progress to complete.
+// If ctx is done first, the calls still in progress are cancelled and an error reports how many there were.
+func (d *callDrainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.draining {
+		d.draining = true
+		d.init()
+		d.idle = make(chan struct{})
+		if d.active == 0 {
+			close(d.idle)
+		}
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	aborted := d.active
+	d.mu.Unlock()
+	d.abort(errShutdownDeadline)
+
+	// give the cancelled calls a chance to return and be recorded
+	select {
+	case <-idle:
+	case <-time.After(abortedCallsGracePeriod):
+	}
+	return fmt.Errorf("cancelled %d tool calls still in progress at the shutdown deadline", aborted)
+}
+
+// Drain makes the MCP service reject new tool calls and waits for the ones in progress to complete,
+// until ctx is done. Calls still in progress then are cancelled, which closes their upstream connections.
+func (m *MCPService) Drain(ctx context.Context) error {
+	return m.drainer.drain(ctx)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDrainWaitsForCallsInProgress(t *testing.T) {
+	m := &MCPService{}
+	_, done, err := m.drainer.begin(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin call: %v", err)
+	}
+
+	drained := make(chan error, 1)
+	go func() { drained <- m.Drain(context.Background()) }()
+
+	// new calls are rejected as soon as draining starts
+	for draining := false; !draining; {
+		time.Sleep(10 * time.Millisecond)
+		m.drainer.mu.Lock()
+		draining = m.drainer.draining
+		m.drainer.mu.Unlock()
+	}
+	_, err = m.callUpstreamTool(context.Background(), "github", mcp.CallToolRequest{})
+	var shuttingDown *RegistryShuttingDownError
+	if !errors.As(err, &shuttingDown) {
+		t.Fatalf("expected new calls to be rejected while draining, got %v", err)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("expected draining to wait for the call in progress, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	done()
+	if err := <-drained; err != nil {
+		t.Fatalf("expected draining to complete once the call completed, got %v", err)
+	}
+}
+
+func TestDrainCancelsCallsAtDeadline(t *testing.T) {
+	m := &MCPService{}
+	ctx, done, err := m.drainer.begin(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin call: %v", err)
+	}
+	// the call returns as soon as it's cancelled
+	go func() {
+		<-ctx.Done()
+		done()
+	}()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Drain(drainCtx); err == nil {
+		t.Fatalf("expected draining to report the call cancelled at the deadline")
+	}
+	if !errors.Is(context.Cause(ctx), errShutdownDeadline) {
+		t.Fatalf("expected the call to be cancelled by the shutdown, got %v", context.Cause(ctx))
+	}
+}
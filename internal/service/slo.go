@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+// ServerSLOReport is the availability of an upstream MCP server over a window, measured against its SLO.
+// Availability counts both health checks and tool calls: a healthy check or a call that didn't fail
+// because of the server (tool error results and calls cancelled by the client don't count against it)
+// is a good event.
+type ServerSLOReport struct {
+	ServerName string `json:"server_name"`
+	// Target is the server's SLO target, if it has one
+	Target *float64 `json:"target,omitempty"`
+
+	HealthChecks    int64 `json:"health_checks"`
+	HealthyChecks   int64 `json:"healthy_checks"`
+	ToolCalls       int64 `json:"tool_calls"`
+	FailedToolCalls int64 `json:"failed_tool_calls"`
+
+	// Availability is the fraction of good events, it is only reported if there were any events
+	Availability *float64 `json:"availability,omitempty"`
+
+	// ErrorBudgetConsumed is the fraction of the error budget (1 - target) used up by bad events
+	ErrorBudgetConsumed *float64 `json:"error_budget_consumed,omitempty"`
+	// BurnRate is the budget consumed relative to the elapsed fraction of the window.
+	// Above 1, the budget will be exhausted before the end of the window.
+	BurnRate *float64 `json:"burn_rate,omitempty"`
+	// Met tells whether the availability meets the target so far
+	Met *bool `json:"met,omitempty"`
+}
+
+// SLOReport is the availability of every registered server over a window.
+type SLOReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Elapsed is the fraction of the window that has elapsed
+	Elapsed float64           `json:"elapsed"`
+	Servers []ServerSLOReport `json:"servers"`
+}
+
+func validateServerSLO(slo *model.ServerSLO) error {
+	if slo.ServerName == "" {
+		return fmt.Errorf("server name is required")
+	}
+	if slo.Target <= 0 || slo.Target >= 1 {
+		return fmt.Errorf("invalid target %v: must be between 0 and 1 (exclusive), eg- 0.999", slo.Target)
+	}
+	return nil
+}
+
+// SetServerSLO sets the SLO of a registered server, replacing its existing SLO if any.
+func (s *HealthService) SetServerSLO(slo *model.ServerSLO) error {
+	if err := validateServerSLO(slo); err != nil {
+		return err
+	}
+	if err := s.db.Where("name = ?", slo.ServerName).First(&model.McpServer{}).Error; err != nil {
+		return fmt.Errorf("failed to get MCP server %s: %w", slo.ServerName, err)
+	}
+
+	var existing model.ServerSLO
+	err := s.db.Where("server_name = ?", slo.ServerName).First(&existing).Error
+	switch {
+	case err == nil:
+		slo.ID = existing.ID
+		slo.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(slo).Error; err != nil {
+			return fmt.Errorf("failed to update SLO of server %s: %w", slo.ServerName, err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.db.Create(slo).Error; err != nil {
+			return fmt.Errorf("failed to create SLO of server %s: %w", slo.ServerName, err)
+		}
+	default:
+		return fmt.Errorf("failed to look up SLO of server %s: %w", slo.ServerName, err)
+	}
+	return nil
+}
+
+// ListServerSLOs returns the SLOs of all servers.
+func (s *HealthService) ListServerSLOs() ([]model.ServerSLO, error) {
+	var slos []model.ServerSLO
+	if err := s.db.Order("server_name").Find(&slos).Error; err != nil {
+		return nil, err
+	}
+	return slos, nil
+}
+
+// DeleteServerSLO deletes the SLO of a server.
+func (s *HealthService) DeleteServerSLO(serverName string) error {
+	res := s.db.Where("server_name = ?", serverName).Delete(&model.ServerSLO{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete SLO of server %s: %w", serverName, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("server %s has no SLO: %w", serverName, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// GetSLOReport reports the availability of every registered server in [from, to) against its SLO.
+// Health checks are counted per day (UTC), so a window that starts or ends during a day
+// includes all the health checks of that day.
+func (s *HealthService) GetSLOReport(from, to, now time.Time) (*SLOReport, error) {
+	var names []string
+	if err := s.db.Model(&model.McpServer{}).Order("name").Pluck("name", &names).Error; err != nil {
+		return nil, fmt.Errorf("failed to list MCP servers: %w", err)
+	}
+	slos, err := s.ListServerSLOs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLOs: %w", err)
+	}
+	targets := make(map[string]float64, len(slos))
+	for _, slo := range slos {
+		targets[slo.ServerName] = slo.Target
+	}
+
+	reports := make(map[string]*ServerSLOReport, len(names))
+	for _, name := range names {
+		reports[name] = &ServerSLOReport{ServerName: name}
+	}
+
+	// the window covers the days it overlaps, the day of its exclusive end included unless it starts at midnight
+	lastDay := to.UTC()
+	if lastDay.Truncate(24 * time.Hour).Equal(lastDay) {
+		lastDay = lastDay.Add(-time.Nanosecond)
+	}
+	rows, err := s.db.Model(&model.ServerUptimeDay{}).
+		Select("server_name, SUM(checks), SUM(healthy_checks)").
+		Where("day >= ? AND day <= ?", from.UTC().Format(time.DateOnly), lastDay.Format(time.DateOnly)).
+		Group("server_name").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count health checks: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var checks, healthy int64
+		if err := rows.Scan(&name, &checks, &healthy); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to count health checks: %w", err)
+		}
+		if r, ok := reports[name]; ok {
+			r.HealthChecks, r.HealthyChecks = checks, healthy
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count health checks: %w", err)
+	}
+
+	// tool error results and calls cancelled by the client are not the server's fault
+	rows, err = s.db.Model(&model.ToolCall{}).
+		Select(
+			"server_name, COUNT(*), COALESCE(SUM(CASE WHEN success OR error_type IN (?, ?) THEN 0 ELSE 1 END), 0)",
+			model.ToolCallErrorTool, model.ToolCallErrorCancelled,
+		).
+		Where("timestamp >= ? AND timestamp < ?", from, to).
+		Group("server_name").
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tool calls: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var calls, failed int64
+		if err := rows.Scan(&name, &calls, &failed); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to count tool calls: %w", err)
+		}
+		if r, ok := reports[name]; ok {
+			r.ToolCalls, r.FailedToolCalls = calls, failed
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tool calls: %w", err)
+	}
+
+	report := &SLOReport{From: from, To: to, Servers: make([]ServerSLOReport, 0, len(names))}
+	report.Elapsed = min(max(float64(now.Sub(from))/float64(to.Sub(from)), 0), 1)
+	for _, name := range names {
+		r := reports[name]
+		if target, ok := targets[name]; ok {
+			r.Target = &target
+		}
+		r.evaluate(report.Elapsed)
+		report.Servers = append(report.Servers, *r)
+	}
+	return report, nil
+}
+
+// evaluate computes the availability of the server and how it fares against its target.
+func (r *ServerSLOReport) evaluate(elapsed float64) {
+	events := r.HealthChecks + r.ToolCalls
+	if events == 0 {
+		return
+	}
+	good := r.HealthyChecks + r.ToolCalls - r.FailedToolCalls
+	availability := float64(good) / float64(events)
+	r.Availability = &availability
+	if r.Target == nil {
+		return
+	}
+
+	consumed := (1 - availability) / (1 - *r.Target)
+	met := availability >= *r.Target
+	r.ErrorBudgetConsumed, r.Met = &consumed, &met
+	if elapsed > 0 {
+		burnRate := consumed / elapsed
+		r.BurnRate = &burnRate
+	}
+}
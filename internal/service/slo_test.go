@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"gorm.io/gorm"
+)
+
+func TestSLOReport(t *testing.T) {
+	db := newTestDB(t)
+	s := NewHealthService(db)
+	for _, name := range []string{"github", "weather", "idle"} {
+		if err := db.Create(&model.McpServer{Name: name, URL: "http://" + name}).Error; err != nil {
+			t.Fatalf("failed to create server: %v", err)
+		}
+	}
+	if err := s.SetServerSLO(&model.ServerSLO{ServerName: "github", Target: 0.9}); err != nil {
+		t.Fatalf("failed to set SLO: %v", err)
+	}
+	if err := s.SetServerSLO(&model.ServerSLO{ServerName: "unknown", Target: 0.9}); err == nil {
+		t.Fatalf("expected setting the SLO of an unregistered server to fail")
+	}
+	if err := s.SetServerSLO(&model.ServerSLO{ServerName: "github", Target: 1}); err == nil {
+		t.Fatalf("expected a target of 100%% to be invalid")
+	}
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	// health checks of the same day are counted together, those outside the window are ignored
+	checks := []model.ServerHealthCheck{
+		{ServerName: "github", CheckedAt: from.Add(time.Hour), Healthy: true},
+		{ServerName: "github", CheckedAt: from.Add(2 * time.Hour), Healthy: false},
+		{ServerName: "github", CheckedAt: from.AddDate(0, 0, 10), Healthy: true},
+		{ServerName: "github", CheckedAt: to.Add(time.Hour), Healthy: false},
+		{ServerName: "weather", CheckedAt: from.AddDate(0, 0, 1), Healthy: true},
+	}
+	for i := range checks {
+		if err := s.countUptimeCheck(&checks[i]); err != nil {
+			t.Fatalf("failed to count health check: %v", err)
+		}
+	}
+	var days int64
+	db.Model(&model.ServerUptimeDay{}).Where("server_name = ?", "github").Count(&days)
+	if days != 3 {
+		t.Fatalf("expected the health checks of github to be counted over 3 days, got %d", days)
+	}
+
+	// tool error results and cancelled calls don't count against the server
+	calls := []model.ToolCall{
+		{Success: true},
+		{Success: false, ErrorType: model.ToolCallErrorTool},
+		{Success: false, ErrorType: model.ToolCallErrorCancelled},
+		{Success: false, ErrorType: model.ToolCallErrorConnection},
+		{Success: true},
+		{Success: true},
+		{Success: true},
+	}
+	for i := range calls {
+		calls[i].ServerName, calls[i].ToolName, calls[i].Model, calls[i].ClientType = "github", "search", "unknown", "api"
+		calls[i].Timestamp = from.Add(time.Duration(i) * time.Hour)
+		if err := db.Create(&calls[i]).Error; err != nil {
+			t.Fatalf("failed to create tool call: %v", err)
+		}
+	}
+
+	// halfway through the month
+	report, err := s.GetSLOReport(from, to, from.Add(to.Sub(from)/2))
+	if err != nil {
+		t.Fatalf("failed to get SLO report: %v", err)
+	}
+	if report.Elapsed != 0.5 || len(report.Servers) != 3 {
+		t.Fatalf("expected a report on 3 servers halfway through the window, got %+v", report)
+	}
+	byName := map[string]ServerSLOReport{}
+	for _, r := range report.Servers {
+		byName[r.ServerName] = r
+	}
+
+	// 3 health checks (1 failed) and 7 calls (1 failed): 8 good events out of 10
+	github := byName["github"]
+	if github.HealthChecks != 3 || github.HealthyChecks != 2 || github.ToolCalls != 7 || github.FailedToolCalls != 1 {
+		t.Fatalf("unexpected event counts for github: %+v", github)
+	}
+	approx := func(got *float64, want float64) bool {
+		return got != nil && math.Abs(*got-want) < 1e-9
+	}
+	if !approx(github.Availability, 0.8) || !approx(github.ErrorBudgetConsumed, 2) || !approx(github.BurnRate, 4) || *github.Met {
+		t.Fatalf("expected github to miss its SLO having consumed twice its error budget, got %+v", github)
+	}
+
+	if weather := byName["weather"]; !approx(weather.Availability, 1) || weather.Target != nil || weather.Met != nil {
+		t.Fatalf("expected weather to be fully available without an SLO, got %+v", weather)
+	}
+	if idle := byName["idle"]; idle.Availability != nil {
+		t.Fatalf("expected no availability for a server without events, got %+v", idle)
+	}
+}
+
+func TestDeleteServerSLONotFound(t *testing.T) {
+	s := NewHealthService(newTestDB(t))
+	if err := s.DeleteServerSLO("missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteServerSLO() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
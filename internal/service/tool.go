@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/duaraghav8/mcpjungle/internal/logging"
 	"github.com/duaraghav8/mcpjungle/internal/model"
 	"github.com/duaraghav8/mcpjungle/internal/types"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
+	"log/slog"
 )
 
 // ListTools returns all tools registered in the registry.
@@ -77,28 +79,12 @@ func (m *MCPService) InvokeTool(ctx context.Context, name string, args map[strin
 	if !ok {
 		return nil, fmt.Errorf("invalid input: tool name does not contain a %s separator", serverToolNameSep)
 	}
-	serverModel, err := m.GetMcpServer(serverName)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to get details about MCP server %s from DB: %w",
-			serverName,
-			err,
-		)
-	}
-
-	mcpClient, err := createMcpServerConn(ctx, serverModel)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to create connection to MCP server %s: %w", serverName, err,
-		)
-	}
-	defer mcpClient.Close()
 
 	callToolReq := mcp.CallToolRequest{}
 	callToolReq.Params.Name = toolName
 	callToolReq.Params.Arguments = args
 
-	callToolResp, err := mcpClient.CallTool(ctx, callToolReq)
+	callToolResp, err := m.callUpstreamTool(ctx, serverName, callToolReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call tool %s on MCP server %s: %w", toolName, serverName, err)
 	}
@@ -143,23 +129,30 @@ func (m *MCPService) registerServerTools(ctx context.Context, s *model.McpServer
 	if err != nil {
 		return fmt.Errorf("failed to fetch tools from MCP server %s: %w", s.Name, err)
 	}
-	for _, tool := range resp.Tools {
+	m.addServerTools(ctx, s, resp.Tools)
+	return nil
+}
+
+// addServerTools registers the tools of an MCP server in the DB and adds them to the MCP proxy server.
+func (m *MCPService) addServerTools(ctx context.Context, s *model.McpServer, tools []mcp.Tool) {
+	for _, tool := range tools {
 		// extracting json schema is currently on best-effort basis
 		// if it fails, we log the error and continue with the next tool
 		jsonSchema, _ := json.Marshal(tool.InputSchema)
+		annotations, _ := json.Marshal(tool.Annotations)
 
 		t := &model.Tool{
 			ServerID:    s.ID,
 			Name:        tool.GetName(),
 			Description: tool.Description,
 			InputSchema: jsonSchema,
+			Annotations: annotations,
 		}
 		if err := m.db.Create(t).Error; err != nil {
-			// TODO: Add error log about this failure
 			// If registration of a tool fails, we should not fail the entire server registration.
 			// Instead, continue with the next tool.
-
-			//fmt.Printf("failed to register tool %s in DB: %w", mergeServerToolNames(s.Name, t.Name), err)
+			slog.ErrorContext(ctx, "failed to register tool",
+				slog.String(logging.KeyServer, s.Name), slog.String(logging.KeyTool, t.Name), logging.Err(err))
 		} else {
 			// Set tool name to include the server name prefix to make it recognizable by MCPJungle
 			tool.Name = mergeServerToolNames(s.Name, tool.Name)
@@ -167,7 +160,6 @@ func (m *MCPService) registerServerTools(ctx context.Context, s *model.McpServer
 			m.mcpProxyServer.AddTool(tool, m.mcpProxyToolCallHandler)
 		}
 	}
-	return nil
 }
 
 // deregisterServerTools deletes all tools that belong to an MCP server from the DB.
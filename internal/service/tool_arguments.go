@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/duaraghav8/mcpjungle/internal/jsonschema"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gorm.io/gorm"
+)
+
+// InvalidArgumentsMetaKey is the key of the _meta entry that lists the problems with the arguments
+// in the result of a tool call rejected by the MCP proxy.
+const InvalidArgumentsMetaKey = "mcpjungle/invalid_arguments"
+
+// InvalidArgumentsError is returned when the arguments of a tool call don't conform to the tool's input schema.
+type InvalidArgumentsError struct {
+	// Tool is the canonical name of the tool
+	Tool string `json:"tool"`
+	// Problems lists every way in which the arguments don't conform to the schema
+	Problems []jsonschema.Violation `json:"problems"`
+}
+
+func (e *InvalidArgumentsError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return fmt.Sprintf("invalid arguments for tool %s: %s", e.Tool, strings.Join(problems, "; "))
+}
+
+// ToolResult converts the error into the error result of an MCP tool call.
+// The text lists the problems one per line so that the LLM driving the client can fix all of them at once,
+// and the problems are also available to clients in the result's _meta.
+func (e *InvalidArgumentsError) ToolResult() *mcp.CallToolResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The arguments of the call to tool %s don't match its input schema, the call was not made.\n", e.Tool)
+	b.WriteString("Problems found (paths are in JSONPath notation, $ being the arguments object):\n")
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+	b.WriteString("Fix all of the problems above and call the tool again.")
+	return &mcp.CallToolResult{
+		Result:  mcp.Result{Meta: map[string]any{InvalidArgumentsMetaKey: e}},
+		Content: []mcp.Content{mcp.NewTextContent(b.String())},
+		IsError: true,
+	}
+}
+
+// validateToolArguments checks the arguments of a tool call against the input schema of the tool
+// and returns an InvalidArgumentsError listing all the problems if they don't conform.
+// Calls to tools that the registry doesn't know the schema of are not validated.
+func (m *MCPService) validateToolArguments(ctx context.Context, serverName string, request mcp.CallToolRequest) error {
+	toolName := request.Params.Name
+	var tool model.Tool
+	err := m.db.WithContext(ctx).
+		Joins("JOIN mcp_servers ON mcp_servers.id = tools.server_id").
+		Where("mcp_servers.name = ? AND tools.name = ?", serverName, toolName).
+		First(&tool).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up the input schema of tool %s: %w", mergeServerToolNames(serverName, toolName), err)
+	}
+
+	var schema any
+	if len(tool.InputSchema) == 0 || json.Unmarshal(tool.InputSchema, &schema) != nil || schema == nil {
+		return nil
+	}
+
+	// a call without arguments is validated as an empty arguments object, so that missing required arguments are reported
+	var args any = map[string]any{}
+	switch raw := request.Params.Arguments.(type) {
+	case nil:
+	case map[string]any:
+		args = raw
+	default:
+		// arguments that weren't decoded into a map, eg- raw JSON, are decoded the way the upstream server would
+		encoded, err := json.Marshal(raw)
+		if err != nil || json.Unmarshal(encoded, &args) != nil {
+			return nil
+		}
+	}
+	problems, err := jsonschema.ValidateContext(ctx, schema, args)
+	if errors.Is(err, jsonschema.ErrSchemaTooComplex) {
+		// like the schemas that can't be decoded, the schemas that can't be checked don't block calls
+		slog.WarnContext(ctx, "the input schema of the tool is too complex to validate its arguments against")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to validate the arguments of tool %s: %w", mergeServerToolNames(serverName, toolName), err)
+	}
+	if len(problems) > 0 {
+		return &InvalidArgumentsError{Tool: mergeServerToolNames(serverName, toolName), Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolArgumentValidation(t *testing.T) {
+	m := newTestMCPService(t)
+	ctx := context.Background()
+	config := `
+tools:
+  - name: forecast
+    input_schema:
+      type: object
+      properties:
+        city: {type: string, minLength: 2}
+        days: {type: integer, minimum: 1, maximum: 14}
+        units: {enum: [metric, imperial]}
+      required: [city]
+    responses:
+      - text: "Sunny in {{.city}}"
+`
+	if err := m.RegisterMcpServer(ctx, &model.McpServer{Name: "validated", MockConfig: config}); err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+	t.Cleanup(func() { mockServers.remove(mockServerURL("validated")) })
+
+	if _, err := m.InvokeTool(ctx, "validated/forecast", map[string]any{"city": "London", "days": 3.0}); err != nil {
+		t.Fatalf("expected valid arguments to be forwarded, got %v", err)
+	}
+
+	// every problem is reported, the call isn't forwarded
+	_, err := m.InvokeTool(ctx, "validated/forecast", map[string]any{"days": "3", "units": "kelvin"})
+	var invalidArgsErr *InvalidArgumentsError
+	if !errors.As(err, &invalidArgsErr) {
+		t.Fatalf("expected an InvalidArgumentsError, got %v", err)
+	}
+	if len(invalidArgsErr.Problems) != 3 || invalidArgsErr.Tool != "validated/forecast" {
+		t.Errorf("expected 3 problems with the arguments of validated/forecast, got %+v", invalidArgsErr)
+	}
+
+	// the proxy relays the problems to the LLM as a tool error
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "validated/forecast"
+	req.Params.Arguments = map[string]any{"city": "L"}
+	result, err := m.mcpProxyToolCallHandler(ctx, req)
+	if err != nil {
+		t.Fatalf("expected a tool error result, got %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "- $.city: must be at least 2 characters long, got 1") {
+		t.Errorf("expected the result to list the problem, got %q", text)
+	}
+	if _, ok := result.Meta[InvalidArgumentsMetaKey]; !ok {
+		t.Errorf("expected the problems in the result's _meta, got %v", result.Meta)
+	}
+
+	// a call without arguments misses the required ones
+	req.Params.Arguments = nil
+	if result, _ := m.mcpProxyToolCallHandler(ctx, req); !result.IsError {
+		t.Errorf("expected a call without arguments to be rejected")
+	}
+
+	// validation can be disabled for the server
+	disabled := false
+	policy := &model.CallPolicy{ScopeType: model.CallScopeServer, ScopeValue: "validated", ValidateArguments: &disabled}
+	if err := m.SetCallPolicy(policy); err != nil {
+		t.Fatalf("failed to set policy: %v", err)
+	}
+	resp, err := m.InvokeTool(ctx, "validated/forecast", map[string]any{"city": "L", "days": 30.0})
+	if err != nil || resp.IsError {
+		t.Fatalf("expected the arguments to be forwarded without validation, got %+v (%v)", resp, err)
+	}
+}
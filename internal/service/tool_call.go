@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/logging"
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// toolCallClientTypeMCP is recorded for tool calls made by MCP clients through the proxy MCP server
+	toolCallClientTypeMCP = "mcp"
+	// toolCallClientTypeAPI is recorded for tool calls made through the registry's HTTP API (eg- mcpjungle invoke)
+	toolCallClientTypeAPI = "api"
+	// toolCallClientTypeReplay is recorded for replays of captured tool calls, unless the caller declares its client type
+	toolCallClientTypeReplay = "replay"
+	// toolCallClientTypeCanary is recorded for the tool calls of canary checks
+	toolCallClientTypeCanary = "canary"
+
+	// toolCallModelUnknown is recorded as the model of a tool call because the registry
+	// never sees which LLM decided to call the tool.
+	toolCallModelUnknown = "unknown"
+)
+
+// recordToolCall stores analytics about a single tool call.
+// Analytics are best-effort, a failure to record the call is logged and never fails the call itself.
+func (m *MCPService) recordToolCall(
+	ctx context.Context,
+	serverName, toolName string,
+	elapsed time.Duration,
+	attempts int,
+	result *mcp.CallToolResult,
+	callErr error,
+) {
+	if m.analyticsService == nil {
+		return
+	}
+
+	tc := model.ToolCall{
+		ToolName:     toolName,
+		ServerName:   serverName,
+		Model:        toolCallModelUnknown,
+		ClientType:   toolCallClientType(ctx),
+		APIKeyHash:   CallerFromContext(ctx).APIKeyHash,
+		ResponseTime: int(elapsed.Milliseconds()),
+		Attempts:     attempts,
+		Success:      callErr == nil && result != nil && !result.IsError,
+		Timestamp:    time.Now(),
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		tc.SessionID = session.SessionID()
+	}
+	switch {
+	case callErr != nil:
+		errMsg := callErr.Error()
+		tc.Error = &errMsg
+	case result != nil && result.IsError:
+		errMsg := "tool returned an error result"
+		tc.Error = &errMsg
+	}
+	tc.ErrorType = classifyToolCallError(ctx, result, callErr)
+
+	if err := m.analyticsService.RecordToolCall(tc); err != nil {
+		slog.ErrorContext(ctx, "failed to record tool call",
+			slog.String(logging.KeyServer, serverName), slog.String(logging.KeyTool, toolName), logging.Err(err))
+	}
+}
+
+// classifyToolCallError returns the model.ToolCallError* class of a failed tool call, or an empty string if it succeeded.
+func classifyToolCallError(ctx context.Context, result *mcp.CallToolResult, callErr error) string {
+	var timeoutErr *ToolCallTimeoutError
+	switch {
+	case callErr == nil && result != nil && result.IsError:
+		return model.ToolCallErrorTool
+	case callErr == nil:
+		return ""
+	case errors.As(callErr, &timeoutErr):
+		return model.ToolCallErrorTimeout
+	case ctx.Err() != nil:
+		return model.ToolCallErrorCancelled
+	case errors.As(callErr, &connectionError{}):
+		return model.ToolCallErrorConnection
+	default:
+		return model.ToolCallErrorUpstream
+	}
+}
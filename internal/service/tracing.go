@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"maps"
+
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// extractMetaTraceContext continues the trace of an MCP client that propagates it in the _meta of its tool call,
+// which takes precedence over the traceparent header of the HTTP request that carried the call.
+// The trace context is removed from the request, the upstream server receives the registry's own.
+func extractMetaTraceContext(ctx context.Context, request *mcp.CallToolRequest) context.Context {
+	meta := request.Params.Meta
+	if meta == nil {
+		return ctx
+	}
+	v, ok := meta.AdditionalFields[tracing.TraceparentHeader]
+	if !ok {
+		return ctx
+	}
+	delete(meta.AdditionalFields, tracing.TraceparentHeader)
+	if meta.ProgressToken == nil && len(meta.AdditionalFields) == 0 {
+		request.Params.Meta = nil
+	}
+	traceparent, _ := v.(string)
+	if sc, err := tracing.ParseTraceparent(traceparent); err == nil {
+		ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	return ctx
+}
+
+// injectMetaTraceContext propagates the trace of ctx to the upstream server in the _meta of a tool call.
+// The request's _meta is copied, so that the trace context doesn't leak into the caller's request.
+func injectMetaTraceContext(ctx context.Context, request *mcp.CallToolRequest) {
+	sc := tracing.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	meta := &mcp.Meta{AdditionalFields: make(map[string]any)}
+	if request.Params.Meta != nil {
+		meta.ProgressToken = request.Params.Meta.ProgressToken
+		maps.Copy(meta.AdditionalFields, request.Params.Meta.AdditionalFields)
+	}
+	meta.AdditionalFields[tracing.TraceparentHeader] = sc.Traceparent()
+	request.Params.Meta = meta
+}
+
+// traceHeaders propagates the trace of ctx to an upstream server in the HTTP headers of the requests made to it.
+func traceHeaders(ctx context.Context) map[string]string {
+	sc := tracing.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return map[string]string{tracing.TraceparentHeader: sc.Traceparent()}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestToolCallTracing(t *testing.T) {
+	var (
+		mu              sync.Mutex
+		headerParents   []string
+		metaTraceparent string
+	)
+	upstream := server.NewMCPServer("upstream", "0.0.1", server.WithToolCapabilities(true))
+	upstream.AddTool(mcp.NewTool("echo"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if req.Params.Meta != nil {
+			metaTraceparent, _ = req.Params.Meta.AdditionalFields[tracing.TraceparentHeader].(string)
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	streamable := server.NewStreamableHTTPServer(upstream)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headerParents = append(headerParents, r.Header.Get(tracing.TraceparentHeader))
+		mu.Unlock()
+		streamable.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	m := newTestMCPService(t)
+	db := m.db
+	s := &model.McpServer{Name: "upstream", URL: ts.URL}
+	if err := db.Create(s).Error; err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := db.Create(&model.Tool{ServerID: s.ID, Name: "echo"}).Error; err != nil {
+		t.Fatalf("failed to create tool: %v", err)
+	}
+
+	exporter := &tracing.InMemoryExporter{}
+	tracer := tracing.NewTracer(exporter)
+	tracing.SetTracer(tracer)
+	t.Cleanup(func() { tracing.SetTracer(nil) })
+
+	// the MCP client propagates its trace in the _meta of the call
+	const clientTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "upstream/echo"
+	req.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{tracing.TraceparentHeader: clientTraceparent}}
+	if _, err := m.mcpProxyToolCallHandler(context.Background(), req); err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down tracer: %v", err)
+	}
+
+	spans := make(map[string]tracing.SpanData)
+	for _, span := range exporter.Spans() {
+		if span.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("expected span %s to continue the client's trace, got trace %s", span.Name, span.SpanContext.TraceID)
+		}
+		spans[span.Name] = span
+	}
+	call, ok := spans["tools/call upstream/echo"]
+	if !ok || call.Parent.String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected the tool call span to be a child of the client's span, got %+v", call)
+	}
+	if call.Attribute("mcp.server") != "upstream" || call.Attribute("mcp.tool_call.attempts") != int64(1) {
+		t.Errorf("unexpected attributes of the tool call span: %+v", call.Attributes)
+	}
+	connect, ok := spans["mcp.connect"]
+	if !ok || connect.Parent != call.SpanContext.SpanID {
+		t.Fatalf("expected a connect span as a child of the tool call span, got %+v", connect)
+	}
+	forward, ok := spans["tools/call echo"]
+	if !ok || forward.Parent != call.SpanContext.SpanID || forward.Kind != tracing.SpanKindClient {
+		t.Fatalf("expected a client span for the upstream call as a child of the tool call span, got %+v", forward)
+	}
+
+	// the upstream server receives the registry's trace context, both in HTTP headers and in the call's _meta
+	mu.Lock()
+	defer mu.Unlock()
+	if metaTraceparent != forward.SpanContext.Traceparent() {
+		t.Errorf("expected the upstream call's _meta to carry traceparent %s, got '%s'", forward.SpanContext.Traceparent(), metaTraceparent)
+	}
+	if len(headerParents) == 0 || headerParents[0] != connect.SpanContext.Traceparent() {
+		t.Errorf("expected the initialize request to carry the connect span's traceparent, got %v", headerParents)
+	}
+	var found bool
+	for _, h := range headerParents {
+		found = found || h == forward.SpanContext.Traceparent()
+	}
+	if !found {
+		t.Errorf("expected the tools/call request to carry the upstream call span's traceparent, got %v", headerParents)
+	}
+}
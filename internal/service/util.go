@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"github.com/duaraghav8/mcpjungle/internal/model"
+	"github.com/duaraghav8/mcpjungle/internal/tracing"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const serverToolNameSep = "/"
@@ -45,8 +47,55 @@ func splitServerToolName(name string) (string, string, bool) {
 }
 
 // createMcpServerConn creates a new MCP server connection and returns the client.
+// A server with several endpoints is connected to through the first of them that is reachable.
 func createMcpServerConn(ctx context.Context, s *model.McpServer) (*client.Client, error) {
-	var opts []transport.StreamableHTTPCOption
+	var err error
+	for _, e := range serverEndpoints(s) {
+		var c *client.Client
+		if c, err = createMcpEndpointConn(ctx, s, e.URL); err == nil {
+			return c, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// createMcpEndpointConn creates a new connection to the given endpoint of an MCP server and returns the client.
+func createMcpEndpointConn(ctx context.Context, s *model.McpServer, url string) (c *client.Client, err error) {
+	ctx, span := tracing.StartChild(ctx, "mcp.connect", tracing.SpanKindClient,
+		tracing.String("mcp.server", s.Name),
+		tracing.String("url.full", url),
+	)
+	defer func(start time.Time) {
+		observeUpstreamConnect(s.Name, time.Since(start), err)
+		span.RecordError(err)
+		span.End()
+	}(time.Now())
+
+	if isMockServerURL(url) {
+		ms, ok := mockServers.get(url)
+		if !ok {
+			return nil, fmt.Errorf("mock server %s is not running", s.Name)
+		}
+		if c, err = client.NewInProcessClient(ms); err != nil {
+			return nil, fmt.Errorf("failed to create in-process client for mock server: %w", err)
+		}
+	} else if c, err = newStreamableHTTPConn(s, url); err != nil {
+		return nil, err
+	}
+	if err = initializeMcpConn(ctx, c, url); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// newStreamableHTTPConn creates a client for the given endpoint of an MCP server served over streamable HTTP.
+func newStreamableHTTPConn(s *model.McpServer, url string) (*client.Client, error) {
+	// every request made to the server carries the trace of the operation it is made for
+	opts := []transport.StreamableHTTPCOption{transport.WithHTTPHeaderFunc(traceHeaders)}
 	if s.BearerToken != "" {
 		// If bearer token is provided, set the Authorization header
 		o := transport.WithHTTPHeaders(map[string]string{
@@ -55,23 +104,25 @@ func createMcpServerConn(ctx context.Context, s *model.McpServer) (*client.Clien
 		opts = append(opts, o)
 	}
 
-	c, err := client.NewStreamableHttpClient(s.URL, opts...)
+	t, err := transport.NewStreamableHTTP(url, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create streamable HTTP client for MCP server: %w", err)
 	}
+	return client.NewClient(&cancellableTransport{Interface: t}), nil
+}
 
+// initializeMcpConn initializes a new connection to the given endpoint of an MCP server.
+func initializeMcpConn(ctx context.Context, c *client.Client, url string) error {
 	initRequest := mcp.InitializeRequest{}
 	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
 	initRequest.Params.ClientInfo = mcp.Implementation{
-		Name:    "mcpjungle mcp client for " + s.URL,
+		Name:    "mcpjungle mcp client for " + url,
 		Version: "0.1",
 	}
 	initRequest.Params.Capabilities = mcp.ClientCapabilities{}
 
-	_, err = c.Initialize(ctx, initRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize connection with MCP server: %w", err)
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		return fmt.Errorf("failed to initialize connection with MCP server: %w", err)
 	}
-
-	return c, nil
+	return nil
 }
@@ -0,0 +1,88 @@
+package testsuite
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results of a suite run that started at the given time as a JUnit XML report.
+// Cases whose assertions failed are reported as failures, and cases whose call could not be made as errors.
+// The class name of a case is the tool it calls.
+func WriteJUnit(w io.Writer, s *Suite, started time.Time, results []Result) error {
+	suite := junitTestSuite{
+		Name:      s.Name,
+		Tests:     len(results),
+		Timestamp: started.UTC().Format("2006-01-02T15:04:05"),
+		Time:      seconds(time.Since(started)),
+	}
+	for _, r := range results {
+		tc := junitTestCase{Name: r.Case.Name, ClassName: r.Case.Tool, Time: seconds(r.Duration)}
+		switch {
+		case r.Err != nil:
+			suite.Errors++
+			tc.Error = &junitProblem{Message: r.Err.Error(), Type: "CallFailed", Text: r.Err.Error()}
+		case len(r.Failures) > 0:
+			suite.Failures++
+			tc.Failure = &junitProblem{Message: r.Failures[0], Type: "AssertionFailed", Text: strings.Join(r.Failures, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	report := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,135 @@
+// Package testsuite runs declarative test suites of tool calls against the registry,
+// eg- as regression tests of the MCP servers registered in it.
+package testsuite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/assertion"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultParallel is how many cases run at once unless the suite says otherwise.
+const DefaultParallel = 4
+
+// Suite is a set of test cases, declared in YAML.
+type Suite struct {
+	Name string `yaml:"name,omitempty"`
+	// Parallel is how many cases run at once
+	Parallel int    `yaml:"parallel,omitempty"`
+	Cases    []Case `yaml:"cases"`
+}
+
+// Case calls a tool with an input and checks the result against assertions.
+type Case struct {
+	// Name defaults to the tool name followed by the position of the case in the suite
+	Name   string               `yaml:"name,omitempty"`
+	Tool   string               `yaml:"tool"`
+	Input  map[string]any       `yaml:"input,omitempty"`
+	Expect assertion.Assertions `yaml:"expect,omitempty"`
+}
+
+// Result is the result of running a case.
+type Result struct {
+	Case     *Case
+	Duration time.Duration
+	// Failures describe the assertions that the result of the call failed
+	Failures []string
+	// Err is set when the call could not be made, in which case no assertion was checked
+	Err error
+}
+
+// Passed reports whether the call was made and met every assertion.
+func (r *Result) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Invoker calls a tool with the given input, eg- through the registry's API.
+type Invoker func(tool string, input map[string]any) (assertion.Outcome, error)
+
+// Load reads and validates the suite in the YAML file at path.
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test suite: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a suite declared in YAML.
+func Parse(data []byte) (*Suite, error) {
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse test suite: %w", err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid test suite: %w", err)
+	}
+	return &s, nil
+}
+
+func (s *Suite) validate() error {
+	if len(s.Cases) == 0 {
+		return errors.New("no test cases declared")
+	}
+	if s.Parallel < 0 {
+		return fmt.Errorf("invalid parallel %d: must not be negative", s.Parallel)
+	}
+	if s.Parallel == 0 {
+		s.Parallel = DefaultParallel
+	}
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Tool == "" {
+			return fmt.Errorf("test case #%d has no tool", i+1)
+		}
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s #%d", c.Tool, i+1)
+		}
+		if err := c.Expect.Validate(); err != nil {
+			return fmt.Errorf("test case %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Run runs the cases of the suite, at most parallel of them at once, and returns their results in the order of the suite.
+// The latency of a call is measured around the invoker.
+func (s *Suite) Run(invoke Invoker, parallel int) []Result {
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]Result, len(s.Cases))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range s.Cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = s.Cases[i].run(invoke)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *Case) run(invoke Invoker) Result {
+	start := time.Now()
+	outcome, err := invoke(c.Tool, c.Input)
+	r := Result{Case: c, Duration: time.Since(start)}
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	outcome.Latency = r.Duration
+	r.Failures = c.Expect.Check(outcome)
+	return r
+}
@@ -0,0 +1,105 @@
+package testsuite
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duaraghav8/mcpjungle/internal/assertion"
+)
+
+const testSuite = `
+name: weather
+cases:
+  - name: sunny
+    tool: weather/forecast
+    input: {city: Paris}
+    expect:
+      is_error: false
+      text_contains: [Sunny]
+  - tool: weather/forecast
+    input: {city: Atlantis}
+    expect:
+      is_error: false
+  - name: unreachable
+    tool: down/forecast
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testSuite))
+	if err != nil {
+		t.Fatalf("failed to parse suite: %v", err)
+	}
+	if s.Parallel != DefaultParallel || s.Cases[1].Name != "weather/forecast #2" {
+		t.Errorf("expected defaults to be set, got parallel %d and name %q", s.Parallel, s.Cases[1].Name)
+	}
+
+	invalid := []string{
+		`cases: []`,
+		`cases: [{name: x}]`,
+		`parallel: -1
+cases: [{tool: a/b}]`,
+		`cases: [{tool: a/b, expect: {text_matches: ["("]}}]`,
+	}
+	for _, c := range invalid {
+		if _, err := Parse([]byte(c)); err == nil {
+			t.Errorf("expected suite %s to be invalid", c)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	s, err := Parse([]byte(testSuite))
+	if err != nil {
+		t.Fatalf("failed to parse suite: %v", err)
+	}
+
+	var running, maxRunning atomic.Int32
+	invoke := func(tool string, input map[string]any) (assertion.Outcome, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if strings.HasPrefix(tool, "down/") {
+			return assertion.Outcome{}, errors.New("server down is unreachable")
+		}
+		city := input["city"].(string)
+		if city == "Atlantis" {
+			return assertion.Outcome{IsError: true, Texts: []string{"unknown city"}}, nil
+		}
+		return assertion.Outcome{Texts: []string{"Sunny in " + city}}, nil
+	}
+
+	started := time.Now()
+	results := s.Run(invoke, 3)
+	if maxRunning.Load() != 3 {
+		t.Errorf("expected the cases to run in parallel, at most %d ran at once", maxRunning.Load())
+	}
+	if !results[0].Passed() || results[1].Passed() || len(results[1].Failures) != 1 || results[2].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	var b bytes.Buffer
+	if err := WriteJUnit(&b, s, started, results); err != nil {
+		t.Fatalf("failed to write JUnit report: %v", err)
+	}
+	report := b.String()
+	for _, want := range []string{
+		`<testsuites tests="3" failures="1" errors="1"`,
+		`<testcase name="sunny" classname="weather/forecast"`,
+		`<failure message="expected a successful result, got a tool error: unknown city" type="AssertionFailed">`,
+		`<error message="server down is unreachable" type="CallFailed">`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected the report to contain %s, got:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+// Package tracing records the spans of the requests handled by the registry and exports them to
+// an OpenTelemetry collector. Trace context is propagated with W3C Trace Context (traceparent).
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceparentHeader is the HTTP header, and the MCP _meta entry, that carries W3C trace context.
+const TraceparentHeader = "traceparent"
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is the part of a span that is propagated across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent encodes the span context as a W3C traceparent value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent decodes a W3C traceparent value.
+func ParseTraceparent(v string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s'", v)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	// future versions may append fields, version 00 has exactly 4
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s': unsupported version", v)
+	}
+
+	var sc SpanContext
+	if len(traceID) != 32 || strings.ToLower(traceID) != traceID || !decodeHex(sc.TraceID[:], traceID) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s': bad trace ID", v)
+	}
+	if len(spanID) != 16 || strings.ToLower(spanID) != spanID || !decodeHex(sc.SpanID[:], spanID) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s': bad span ID", v)
+	}
+	var f [1]byte
+	if len(flags) != 2 || !decodeHex(f[:], flags) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s': bad flags", v)
+	}
+	sc.Sampled = f[0]&1 == 1
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent '%s': all-zero ID", v)
+	}
+	return sc, nil
+}
+
+func decodeHex(dst []byte, s string) bool {
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
+
+// SpanKind describes the relationship of a span to its parent and children, with OTLP's values.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode is the status of a span, with OTLP's values.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute is a key-value pair describing a span. Values are strings, int64s or bools.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is a completed span, as handed over to exporters.
+type SpanData struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	// Parent is the ID of the parent span, it is invalid for root spans
+	Parent        SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// Attribute returns the value of the span's attribute with the given key, or nil.
+func (s *SpanData) Attribute(key string) any {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+// Span is an operation in progress.
+// A nil span is valid and records nothing, which is what Start returns when tracing is disabled.
+type Span struct {
+	tracer *Tracer
+	// recording is false for spans of traces that the caller chose not to sample,
+	// such spans only propagate their context
+	recording bool
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the context of the span, to propagate it.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.recording {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed if err is not nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || !s.recording || err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus sets the status of the span.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil || !s.recording {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.StatusCode, s.data.StatusMessage = code, message
+}
+
+// End completes the span and hands it over to the exporter. Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil || !s.recording {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+	s.tracer.enqueue(data)
+}
+
+type spanContextKey struct{}
+type remoteSpanContextKey struct{}
+
+// ContextWithRemoteSpanContext returns a context whose spans are children of a span of another process,
+// eg- as received in a traceparent header. It takes precedence over the span of ctx, if any.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	ctx = context.WithValue(ctx, spanContextKey{}, (*Span)(nil))
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+// SpanFromContext returns the span in progress in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanContextKey{}).(*Span)
+	return s
+}
+
+// SpanContextFromContext returns the context of the span in progress in ctx, or of the remote parent span.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteSpanContextKey{}).(SpanContext)
+	return sc
+}
+
+var global atomic.Pointer[Tracer]
+
+// SetTracer sets the tracer that records spans, nil disables tracing.
+func SetTracer(t *Tracer) {
+	global.Store(t)
+}
+
+// Start starts a span as a child of the span in progress in ctx, if any.
+// It returns a context that carries the new span, which the caller must End.
+// When tracing is disabled it returns ctx as is and a nil span.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	parent := SpanContextFromContext(ctx)
+
+	s := &Span{
+		tracer: t,
+		// honor the sampling decision of the parent, root spans are always sampled
+		recording: !parent.IsValid() || parent.Sampled,
+		data: SpanData{
+			Name:       name,
+			Kind:       kind,
+			Start:      time.Now(),
+			Attributes: attrs,
+		},
+	}
+	s.data.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: s.recording}
+	if parent.IsValid() {
+		s.data.Parent = parent.SpanID
+	} else {
+		s.data.SpanContext.TraceID = newTraceID()
+	}
+	return context.WithValue(ctx, spanContextKey{}, s), s
+}
+
+// StartChild starts a span like Start, but only as part of a trace in progress in ctx.
+// It is meant for operations that are only worth tracing for the request they serve,
+// eg- database queries, which would otherwise flood the backend with root spans when made by background jobs.
+func StartChild(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	if !SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+	return Start(ctx, name, kind, attrs...)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		putUint64(id[:8], rand.Uint64())
+		putUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		putUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := range b {
+		b[i] = byte(v >> (8 * i))
+	}
+}
+
+const (
+	// maxQueuedSpans bounds the spans waiting to be exported, spans beyond it are dropped
+	maxQueuedSpans = 2048
+	// exportBatchSize is the number of queued spans that triggers an export
+	exportBatchSize = 512
+	// exportInterval is the maximum time a span waits before being exported
+	exportInterval = 5 * time.Second
+)
+
+// Exporter sends completed spans to a tracing backend.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer batches completed spans and exports them in the background.
+type Tracer struct {
+	exporter Exporter
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+
+	// exportMu serializes exports so that spans are exported in the order they completed
+	exportMu sync.Mutex
+
+	batchReady chan struct{}
+	stop       chan struct{}
+	stopped    chan struct{}
+}
+
+// NewTracer creates a tracer that exports spans with the exporter.
+// It must be shut down to export the spans still queued.
+func NewTracer(exporter Exporter) *Tracer {
+	t := &Tracer{
+		exporter:   exporter,
+		batchReady: make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+func (t *Tracer) enqueue(s SpanData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.queue) >= maxQueuedSpans {
+		t.dropped++
+		return
+	}
+	t.queue = append(t.queue, s)
+	if len(t.queue) >= exportBatchSize {
+		select {
+		case t.batchReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		case <-t.batchReady:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportInterval)
+		if err := t.Flush(ctx); err != nil {
+			logExportError(err)
+		}
+		cancel()
+	}
+}
+
+// Flush exports the queued spans right away.
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.exportMu.Lock()
+	defer t.exportMu.Unlock()
+
+	t.mu.Lock()
+	spans, dropped := t.queue, t.dropped
+	t.queue, t.dropped = nil, 0
+	t.mu.Unlock()
+
+	var errs []error
+	if dropped > 0 {
+		errs = append(errs, fmt.Errorf("dropped %d spans because the export queue was full", dropped))
+	}
+	if len(spans) > 0 {
+		if err := t.exporter.ExportSpans(ctx, spans); err != nil {
+			errs = append(errs, fmt.Errorf("failed to export %d spans: %w", len(spans), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown stops the background exports and exports the spans still queued.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	close(t.stop)
+	<-t.stopped
+	return t.Flush(ctx)
+}
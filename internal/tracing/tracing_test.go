@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("failed to parse traceparent: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Fatalf("unexpected span context: %+v", sc)
+	}
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected traceparent: %s", got)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	}
+	for _, v := range invalid {
+		if _, err := ParseTraceparent(v); err == nil {
+			t.Errorf("expected traceparent '%s' to be rejected", v)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	if _, span := Start(context.Background(), "disabled", SpanKindInternal); span != nil {
+		t.Fatalf("expected no span while tracing is disabled")
+	}
+
+	exporter := &InMemoryExporter{}
+	tracer := NewTracer(exporter)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, parent := Start(ContextWithRemoteSpanContext(context.Background(), remote), "parent", SpanKindServer)
+	_, child := Start(ctx, "child", SpanKindClient, String("k", "v"))
+	child.End()
+	parent.End()
+
+	unsampled := remote
+	unsampled.Sampled = false
+	_, dropped := Start(ContextWithRemoteSpanContext(context.Background(), unsampled), "unsampled", SpanKindServer)
+	dropped.End()
+	if dropped.SpanContext().TraceID != remote.TraceID || dropped.SpanContext().Sampled {
+		t.Fatalf("expected the span of an unsampled trace to propagate the trace unsampled, got %+v", dropped.SpanContext())
+	}
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down tracer: %v", err)
+	}
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected the 2 sampled spans to be exported, got %d", len(spans))
+	}
+	if spans[0].Name != "child" || spans[1].Name != "parent" {
+		t.Fatalf("expected spans in the order they ended, got %s, %s", spans[0].Name, spans[1].Name)
+	}
+	if spans[1].SpanContext.TraceID != remote.TraceID || spans[1].Parent != remote.SpanID {
+		t.Fatalf("expected the parent span to continue the remote trace, got %+v", spans[1])
+	}
+	if spans[0].SpanContext.TraceID != remote.TraceID || spans[0].Parent != spans[1].SpanContext.SpanID {
+		t.Fatalf("expected the child span to be a child of the parent span, got %+v", spans[0])
+	}
+	if spans[0].Attribute("k") != "v" {
+		t.Fatalf("expected the child span to have its attribute, got %+v", spans[0].Attributes)
+	}
+}
+
+func TestEncodeOTLP(t *testing.T) {
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	start := time.Unix(1700000000, 0)
+	span := SpanData{
+		Name:          "tools/call github/search",
+		Kind:          SpanKindInternal,
+		SpanContext:   sc,
+		Start:         start,
+		End:           start.Add(time.Second),
+		Attributes:    []Attribute{String("mcp.server", "github"), Int("attempts", 2), Bool("retried", true)},
+		StatusCode:    StatusError,
+		StatusMessage: "timeout",
+	}
+	body, err := json.Marshal(encodeOTLP("mcpjungle", []SpanData{span}))
+	if err != nil {
+		t.Fatalf("failed to encode spans: %v", err)
+	}
+	for _, want := range []string{
+		`"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"mcpjungle"}}]}`,
+		`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7","name":"tools/call github/search","kind":1`,
+		`"startTimeUnixNano":"1700000000000000000","endTimeUnixNano":"1700000001000000000"`,
+		`{"key":"attempts","value":{"intValue":"2"}}`,
+		`{"key":"retried","value":{"boolValue":true}}`,
+		`"status":{"code":2,"message":"timeout"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected encoded spans to contain %s, got %s", want, body)
+		}
+	}
+	if strings.Contains(string(body), "parentSpanId") {
+		t.Errorf("expected no parent span ID for a root span, got %s", body)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("api-key=secret, x-tenant = acme%20corp")
+	if err != nil {
+		t.Fatalf("failed to parse headers: %v", err)
+	}
+	if headers["api-key"] != "secret" || headers["x-tenant"] != "acme corp" {
+		t.Fatalf("unexpected headers: %v", headers)
+	}
+	if _, err := ParseHeaders("no-value"); err == nil {
+		t.Fatalf("expected a header without value to be rejected")
+	}
+}
@@ -1,103 +1,60 @@
-// Future implementation: Real-time server health monitoring
+// Server health as determined by the registry's periodic health checks
+
+const API_BASE_URL = process.env.NODE_ENV === 'development'
+  ? 'http://localhost:8080/api/v0'
+  : '/api/v0'
 
 export interface ServerHealth {
-  serverId: string
-  status: 'healthy' | 'unhealthy' | 'unknown' | 'checking'
-  lastPing: Date
-  responseTime: number // in milliseconds
-  errorCount: number
-  uptime: number // percentage
-  lastError?: string
+  server_name: string
+  status: 'healthy' | 'degraded' | 'unhealthy' | 'unknown' // degraded: reachable, but some of its canary checks are failing
+  latency_ms: number
+  checked_at?: string
+  consecutive_failures: number
+  last_error?: string
+  last_error_at?: string
+  uptime_24h?: number // fraction of the checks of the last 24 hours that succeeded
 }
 
 export interface HealthCheckResult {
-  serverId: string
-  isHealthy: boolean
-  responseTime: number
-  timestamp: Date
+  id: string
+  server_name: string
+  checked_at: string
+  healthy: boolean
+  latency_ms: number
   error?: string
 }
 
-export class HealthMonitor {
-  private healthChecks = new Map<string, ServerHealth>()
-  private checkInterval = 30000 // 30 seconds
-  private maxRetries = 3
-  
-  // Future implementation: Start monitoring a server
-  startMonitoring(serverId: string, serverUrl: string): void {
-    // Initialize health status
-    this.healthChecks.set(serverId, {
-      serverId,
-      status: 'unknown',
-      lastPing: new Date(),
-      responseTime: 0,
-      errorCount: 0,
-      uptime: 100
-    })
-
-    // TODO: Implement periodic health checks
-    // - Ping server endpoint every 30 seconds
-    // - Check /health or MCP initialization endpoint
-    // - Track response times and errors
-    // - Update health status in real-time
-    console.log(`Health monitoring started for server: ${serverId}`)
-  }
-
-  // Future implementation: Stop monitoring a server  
-  stopMonitoring(serverId: string): void {
-    this.healthChecks.delete(serverId)
-    console.log(`Health monitoring stopped for server: ${serverId}`)
-  }
-
-  // Future implementation: Get current health status
-  getHealth(serverId: string): ServerHealth | undefined {
-    return this.healthChecks.get(serverId)
-  }
+export interface ServerHealthHistory extends ServerHealth {
+  history: HealthCheckResult[] // newest first
+}
 
-  // Future implementation: Get all health statuses
-  getAllHealth(): ServerHealth[] {
-    return Array.from(this.healthChecks.values())
-  }
+export const healthApi = {
+  // Health of all registered servers
+  async getAllHealth(): Promise<ServerHealth[]> {
+    const response = await fetch(`${API_BASE_URL}/servers/health`)
+    if (!response.ok) {
+      throw new Error(`Failed to fetch server health: ${response.status} ${response.statusText}`)
+    }
+    return response.json()
+  },
 
-  // Future implementation: Manual health check
-  async checkHealth(serverId: string, serverUrl: string): Promise<HealthCheckResult> {
-    const startTime = Date.now()
-    
-    try {
-      // TODO: Implement actual health check
-      // - Make request to server health endpoint
-      // - Check MCP protocol response
-      // - Measure response time
-      // - Return structured result
-      
-      const responseTime = Date.now() - startTime
-      return {
-        serverId,
-        isHealthy: true,
-        responseTime,
-        timestamp: new Date()
-      }
-    } catch (error) {
-      return {
-        serverId,
-        isHealthy: false,
-        responseTime: Date.now() - startTime,
-        timestamp: new Date(),
-        error: error instanceof Error ? error.message : 'Unknown error'
-      }
+  // Health of a server along with its most recent health checks
+  async getHealthHistory(serverName: string, limit = 100): Promise<ServerHealthHistory> {
+    const url = `${API_BASE_URL}/servers/${encodeURIComponent(serverName)}/health?limit=${limit}`
+    const response = await fetch(url)
+    if (!response.ok) {
+      throw new Error(`Failed to fetch health of server ${serverName}: ${response.status} ${response.statusText}`)
     }
+    return response.json()
   }
 }
 
-// Global health monitor instance
-export const healthMonitor = new HealthMonitor()
-
 // Health status indicator component helpers
 export const getHealthColor = (status: ServerHealth['status']): string => {
   switch (status) {
     case 'healthy': return 'bg-green-400'
-    case 'unhealthy': return 'bg-red-400' 
-    case 'checking': return 'bg-yellow-400'
+    case 'degraded': return 'bg-yellow-400'
+    case 'unhealthy': return 'bg-red-400'
     default: return 'bg-gray-400'
   }
 }
@@ -105,15 +62,6 @@ export const getHealthColor = (status: ServerHealth['status']): string => {
 export const getHealthAnimation = (status: ServerHealth['status']): string => {
   switch (status) {
     case 'healthy': return 'animate-pulse'
-    case 'checking': return 'animate-spin'
     default: return ''
   }
 }
-
-// TODO: Implement the following features:
-// 1. WebSocket connection for real-time health updates
-// 2. Health check scheduling with configurable intervals
-// 3. Server downtime alerts and notifications
-// 4. Historical health data and uptime tracking
-// 5. Health dashboard with metrics and charts
-// 6. Custom health check endpoints per server type
\ No newline at end of file